@@ -1,14 +1,18 @@
 package main
 
 import (
+	"context"
+	"crypto/ecdsa"
 	"log"
 
 	"github.com/sidler1/manga-backend/internal/config"
 	"github.com/sidler1/manga-backend/internal/database"
 	"github.com/sidler1/manga-backend/internal/handlers"
 	"github.com/sidler1/manga-backend/internal/middlewares"
+	"github.com/sidler1/manga-backend/internal/models"
 	"github.com/sidler1/manga-backend/internal/repositories"
 	"github.com/sidler1/manga-backend/internal/services"
+	"github.com/sidler1/manga-backend/notifier"
 
 	"github.com/gin-gonic/gin"
 	"github.com/robfig/cron/v3"
@@ -55,32 +59,154 @@ func main() {
 
 	// Initialize repositories
 	mangaRepo := repositories.NewMangaRepository(db)
+	titleSearchRepo := repositories.NewTitleSearchRepository(db)
 	userRepo := repositories.NewUserRepository(db)
 	bookmarkRepo := repositories.NewBookmarkRepository(db)
 	websiteRepo := repositories.NewWebsiteRepository(db)
 	notificationRepo := repositories.NewNotificationRepository(db)
 	tagRepo := repositories.NewTagRepository(db)
 	chapterRepo := repositories.NewChapterRepository(db)
+	releaseStateRepo := repositories.NewReleaseStateRepository(db)
+	creatorRepo := repositories.NewCreatorRepository(db)
+	auditRepo := repositories.NewAuditRepository(db)
+	metadataRepo := repositories.NewMetadataRepository(db)
+	channelRepo := repositories.NewChannelRepository(db)
+	deliveryRepo := repositories.NewDeliveryRepository(db)
+	serverKeyRepo := repositories.NewServerKeyRepository(db)
+	preferenceRepo := repositories.NewPreferenceRepository(db)
+
+	if err := userRepo.SetRole(cfg.AdminUsers, models.RoleAdmin); err != nil {
+		log.Fatalf("Failed to grant admin role: %v", err)
+	}
 
 	// Initialize services
 	authService := services.NewAuthService(userRepo, cfg.JWTSecret)
 	authMiddleware := middlewares.AuthMiddleware(cfg.JWTSecret)
-	notificationService := services.NewNotificationService(userRepo, notificationRepo, mangaRepo)
-	scraperService := services.NewScraperService(websiteRepo, mangaRepo, chapterRepo, tagRepo, notificationService)
-	mangaService := services.NewMangaService(mangaRepo, userRepo, bookmarkRepo, chapterRepo, tagRepo, scraperService, notificationService)
+	unsubscribeSigner := services.NewUnsubscribeSigner(cfg.JWTSecret)
+	deliveryService := services.NewDeliveryService(deliveryRepo, channelRepo, userRepo, mangaRepo, notificationRepo, unsubscribeSigner, cfg.PublicURL)
+	channelService := services.NewChannelService(channelRepo, userRepo, unsubscribeSigner)
+	var vapidKey *ecdsa.PrivateKey
+	if cfg.VAPIDSubject != "" {
+		vapidKey, err = services.LoadVAPIDKey(serverKeyRepo, cfg.VAPIDPrivateKey)
+		if err != nil {
+			log.Fatalf("Failed to load VAPID key: %v", err)
+		}
+		services.RegisterNotifier(notifier.NewWebPushNotifier(vapidKey, cfg.VAPIDSubject))
+	}
+	pushService := services.NewPushService(channelRepo, vapidKey)
+	services.RegisterNotifier(notifier.NewWebhookNotifier())
+	services.RegisterNotifier(notifier.NewNtfyNotifier(cfg.SelfHostedAllowedNetworks))
+	services.RegisterNotifier(notifier.NewGotifyNotifier(cfg.SelfHostedAllowedNetworks))
+	notificationService := services.NewNotificationService(userRepo, notificationRepo, mangaRepo, preferenceRepo, deliveryService)
+	tagService := services.NewTagService(tagRepo)
+	creatorService := services.NewCreatorService(creatorRepo, userRepo)
+	scraperService := services.NewScraperService(websiteRepo, mangaRepo, chapterRepo, tagService, creatorService, notificationService)
+	mangaService := services.NewMangaService(mangaRepo, titleSearchRepo, userRepo, bookmarkRepo, chapterRepo, tagRepo, scraperService, notificationService)
+	releaseStateService := services.NewReleaseStateService(mangaRepo, chapterRepo, releaseStateRepo, notificationService)
+	calendarService := services.NewCalendarService(userRepo, chapterRepo)
+	adminService := services.NewAdminService(mangaRepo, chapterRepo, websiteRepo, auditRepo)
+	websiteService := services.NewWebsiteService(websiteRepo)
+	metadataService := services.NewMetadataService(mangaRepo, metadataRepo, tagRepo, tagService, creatorService)
+	availabilityService := services.NewAvailabilityService(mangaRepo, chapterRepo, titleSearchRepo, notificationService)
 
-	// Set up cron job for hourly updates
-	println("Setting up hourly cron job...")
+	// Set up the Telegram bot. Updates arrive by webhook when a secret for it
+	// is configured, by long polling otherwise.
+	var telegramService services.TelegramService
+	if cfg.TelegramBotToken != "" {
+		telegramClient := notifier.NewTelegramClient(cfg.TelegramAPIURL, cfg.TelegramBotToken)
+		botUsername, err := telegramClient.Username()
+		if err != nil {
+			log.Fatalf("Failed to reach the Telegram bot: %v", err)
+		}
+		services.RegisterNotifier(notifier.NewTelegramNotifier(telegramClient))
+		telegramService = services.NewTelegramService(telegramClient, botUsername, repositories.NewTelegramRepository(db), channelRepo, mangaService)
+		if cfg.TelegramWebhookSecret != "" {
+			err = telegramClient.SetWebhook(cfg.PublicURL+"/api/v1/telegram/webhook", cfg.TelegramWebhookSecret)
+		} else if err = telegramClient.DeleteWebhook(); err == nil {
+			go telegramService.Poll(context.Background())
+		}
+		if err != nil {
+			log.Fatalf("Failed to set up Telegram updates: %v", err)
+		}
+	}
+
+	// Set up the update check. It runs every minute; each website is only
+	// scraped when its own check interval and active hours allow it.
+	println("Setting up update check cron job...")
 	c := cron.New()
-	_, err = c.AddFunc("*/10 * * * *", func() {
-		log.Println("Running hourly manga update check...")
+	_, err = c.AddJob("* * * * *", cron.NewChain(cron.SkipIfStillRunning(cron.DefaultLogger)).Then(cron.FuncJob(func() {
 		if err := scraperService.CheckForUpdates(); err != nil {
 			log.Printf("Error during update check: %v", err)
 		}
+	})))
+	if err != nil {
+		log.Fatalf("Failed to schedule cron job: %v", err)
+	}
+	_, err = c.AddFunc("30 3 * * *", func() {
+		log.Println("Running daily release state analysis...")
+		if err := releaseStateService.AnalyzeAll(); err != nil {
+			log.Printf("Error during release state analysis: %v", err)
+		}
 	})
 	if err != nil {
 		log.Fatalf("Failed to schedule cron job: %v", err)
 	}
+	// Metadata is refreshed in small hourly batches, oldest first, so every
+	// manga is re-scraped about once a week.
+	_, err = c.AddJob("15 * * * *", cron.NewChain(cron.SkipIfStillRunning(cron.DefaultLogger)).Then(cron.FuncJob(func() {
+		if err := metadataService.RefreshDue(); err != nil {
+			log.Printf("Error during metadata refresh: %v", err)
+		}
+	})))
+	if err != nil {
+		log.Fatalf("Failed to schedule cron job: %v", err)
+	}
+	// Source pages are checked in hourly batches so each manga is checked
+	// about daily; repeated 404s or redirects mark it removed.
+	_, err = c.AddJob("45 * * * *", cron.NewChain(cron.SkipIfStillRunning(cron.DefaultLogger)).Then(cron.FuncJob(func() {
+		if err := availabilityService.CheckDue(); err != nil {
+			log.Printf("Error during source availability check: %v", err)
+		}
+	})))
+	if err != nil {
+		log.Fatalf("Failed to schedule cron job: %v", err)
+	}
+	if cfg.NotificationRetention > 0 {
+		_, err = c.AddFunc("0 4 * * *", func() {
+			purged, err := notificationService.PurgeReadNotifications(cfg.NotificationRetention)
+			if err != nil {
+				log.Printf("Error purging read notifications: %v", err)
+				return
+			}
+			log.Printf("Purged %d read notifications", purged)
+		})
+		if err != nil {
+			log.Fatalf("Failed to schedule cron job: %v", err)
+		}
+	}
+	// Deliveries over notification channels are sent, and failed ones retried
+	// with backoff, by a dispatcher running every minute.
+	_, err = c.AddJob("* * * * *", cron.NewChain(cron.SkipIfStillRunning(cron.DefaultLogger)).Then(cron.FuncJob(func() {
+		if err := deliveryService.DispatchDue(); err != nil {
+			log.Printf("Error dispatching notifications: %v", err)
+		}
+	})))
+	if err != nil {
+		log.Fatalf("Failed to schedule cron job: %v", err)
+	}
+	services.RegisterScrapers() // Register scrapers for supported websites
+	if cfg.SMTPHost != "" {
+		services.RegisterNotifier(notifier.NewEmailNotifier(notifier.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		}))
+	}
+	if err := websiteService.SyncScrapers(); err != nil {
+		log.Fatalf("Failed to sync scrapers with moved websites: %v", err)
+	}
 	c.Start()
 	defer c.Stop()
 
@@ -118,22 +244,87 @@ func main() {
 			auth.POST("/login", handlers.Login(authService))
 		}
 
+		//	@Summary		Get iCalendar release feed
+		//	@Description	Serve the release calendar of a user's favorites; authenticated by the secret token in the URL
+		//	@Tags			calendar
+		//	@Produce		text/calendar
+		//	@Param			token	path		string	true	"Feed token, optionally suffixed with .ics"
+		//	@Success		200		{string}	string
+		//	@Failure		404		{object}	handlers.ErrorResponse
+		//	@Router			/calendar/{token} [get]
+		api.GET("/calendar/:token", handlers.GetCalendarFeed(calendarService))
+
+		//	@Summary		Confirm unsubscribing
+		//	@Description	Page behind the signed unsubscribe links of notification emails, asking to confirm
+		//	@Tags			users
+		//	@Produce		html
+		//	@Param			token	path		string	true	"Signed unsubscribe token"
+		//	@Success		200		{string}	string
+		//	@Router			/unsubscribe/{token} [get]
+		api.GET("/unsubscribe/:token", handlers.ConfirmUnsubscribe())
+		//	@Summary		Unsubscribe by signed link
+		//	@Description	Mute one manga on a channel subscription, or disable the subscription, as named by the signed token; also the RFC 8058 one-click target
+		//	@Tags			users
+		//	@Produce		html
+		//	@Param			token	path		string	true	"Signed unsubscribe token"
+		//	@Success		200		{string}	string
+		//	@Failure		404		{object}	handlers.ErrorResponse
+		//	@Router			/unsubscribe/{token} [post]
+		api.POST("/unsubscribe/:token", handlers.UnsubscribeByToken(channelService))
+
+		if telegramService != nil && cfg.TelegramWebhookSecret != "" {
+			//	@Summary		Telegram bot webhook
+			//	@Description	Receive bot updates from Telegram; authenticated by the X-Telegram-Bot-Api-Secret-Token header
+			//	@Tags			telegram
+			//	@Accept			json
+			//	@Param			update	body	notifier.TelegramUpdate	true	"Bot API update"
+			//	@Success		200
+			//	@Failure		401	{object}	handlers.ErrorResponse
+			//	@Router			/telegram/webhook [post]
+			api.POST("/telegram/webhook", handlers.TelegramWebhook(telegramService, cfg.TelegramWebhookSecret))
+		}
+
 		// Protected routes
 		protected := api.Group("/")
 		protected.Use(authMiddleware)
 		{
 			mangas := protected.Group("/mangas")
 			{
-				//	@Summary		Login user
-				//	@Description	Authenticate a user and return a JWT token
-				//	@Tags			auth
+				//	@Summary		List mangas
+				//	@Description	List mangas with pagination, whitelisted filters and sorting
+				//	@Tags			mangas
 				//	@Accept			json
 				//	@Produce		json
-				//	@Param			credentials	body		handlers.LoginRequest	true	"Login Credentials"
-				//	@Success		200			{object}	handlers.AuthResponse
-				//	@Failure		401			{object}	handlers.ErrorResponse
-				//	@Router			/auth/login [post]
+				//	@Param			after			query		string	false	"Opaque cursor from next_cursor of the previous page; pass it empty for the first cursor page"
+				//	@Param			count			query		bool	false	"Include the total number of matches (cursor pages only)"
+				//	@Param			page			query		int		false	"Page number for offset pagination, the default"
+				//	@Param			limit			query		int		false	"Page size (1-100)"
+				//	@Param			tags			query		string	false	"Comma-separated tags that must all be present"
+				//	@Param			exclude_tags	query		string	false	"Comma-separated tags that must not be present"
+				//	@Param			status			query		string	false	"Comma-separated release states"
+				//	@Param			website			query		int		false	"Website ID"
+				//	@Param			author			query		string	false	"Author substring"
+				//	@Param			updated_since	query		string	false	"RFC 3339 timestamp or YYYY-MM-DD"
+				//	@Param			has_unread		query		bool	false	"Only favorites with chapters past the caller's bookmark"
+				//	@Param			sort			query		string	false	"updated, title, popularity or estimated_next, optionally suffixed with :asc or :desc"
+				//	@Param			include			query		string	false	"Comma-separated expansions (tags, chapters, website); returns full mangas instead of summaries"
+				//	@Success		200				{array}		repositories.MangaSummary
+				//	@Failure		400				{object}	handlers.ErrorResponse
+				//	@Failure		500				{object}	handlers.ErrorResponse
+				//	@Router			/mangas [get]
 				mangas.GET("/", handlers.GetMangas(mangaService))
+				//	@Summary		Autocomplete manga titles
+				//	@Description	Typo-tolerant title suggestions with cover and latest chapter
+				//	@Tags			mangas
+				//	@Accept			json
+				//	@Produce		json
+				//	@Param			q		query		string	true	"Partial title, at least 2 characters"
+				//	@Param			limit	query		int		false	"Maximum suggestions (1-20, default 8)"
+				//	@Success		200		{object}	object{suggestions=[]repositories.MangaSuggestion}
+				//	@Failure		400		{object}	handlers.ErrorResponse
+				//	@Failure		500		{object}	handlers.ErrorResponse
+				//	@Router			/mangas/suggest [get]
+				mangas.GET("/suggest", handlers.SuggestMangas(mangaService))
 				//	@Summary		Get a specific manga
 				//	@Description	Retrieve details of a specific manga by its ID
 				//	@Tags			mangas
@@ -145,13 +336,59 @@ func main() {
 				//	@Failure		500	{object}	handlers.ErrorResponse
 				//	@Router			/mangas/{id} [get]
 				mangas.GET("/:id", handlers.GetManga(mangaService))
+				//	@Summary		Get release state history of a manga
+				//	@Description	List the ongoing/hiatus/completed/dropped transitions detected for a manga
+				//	@Tags			mangas
+				//	@Accept			json
+				//	@Produce		json
+				//	@Param			id	path		int	true	"Manga ID"
+				//	@Success		200	{array}		models.MangaStateTransition
+				//	@Failure		400	{object}	handlers.ErrorResponse
+				//	@Failure		500	{object}	handlers.ErrorResponse
+				//	@Router			/mangas/{id}/release-history [get]
+				mangas.GET("/:id/release-history", handlers.GetMangaReleaseHistory(releaseStateService))
+				//	@Summary		List chapters of a manga
+				//	@Description	List chapters, latest first, using cursor pagination
+				//	@Tags			mangas
+				//	@Accept			json
+				//	@Produce		json
+				//	@Param			id		path		int		true	"Manga ID"
+				//	@Param			after	query		string	false	"Opaque cursor from next_cursor of the previous page"
+				//	@Param			limit	query		int		false	"Page size (1-100, default 50)"
+				//	@Param			count	query		bool	false	"Include the total number of chapters"
+				//	@Success		200		{array}		models.Chapter
+				//	@Failure		400		{object}	handlers.ErrorResponse
+				//	@Failure		500		{object}	handlers.ErrorResponse
+				//	@Router			/mangas/{id}/chapters [get]
+				mangas.GET("/:id/chapters", handlers.GetMangaChapters(mangaService))
+				//	@Summary		Get metadata history of a manga
+				//	@Description	List metadata changes found by source refreshes, newest first; changes to locked fields are listed with applied=false
+				//	@Tags			mangas
+				//	@Produce		json
+				//	@Param			id	path		int	true	"Manga ID"
+				//	@Success		200	{array}		models.MangaMetadataChange
+				//	@Failure		400	{object}	handlers.ErrorResponse
+				//	@Failure		500	{object}	handlers.ErrorResponse
+				//	@Router			/mangas/{id}/metadata-history [get]
+				mangas.GET("/:id/metadata-history", handlers.GetMangaMetadataHistory(metadataService))
+				//	@Summary		Get alternative sources of a manga
+				//	@Description	List the same series on other websites, best title match first; useful once a manga is removed from its source
+				//	@Tags			mangas
+				//	@Produce		json
+				//	@Param			id	path		int	true	"Manga ID"
+				//	@Success		200	{array}		repositories.AlternativeSource
+				//	@Failure		400	{object}	handlers.ErrorResponse
+				//	@Failure		404	{object}	handlers.ErrorResponse
+				//	@Router			/mangas/{id}/alternatives [get]
+				mangas.GET("/:id/alternatives", handlers.GetMangaAlternatives(availabilityService))
 				//	@Summary		Search for mangas
-				//	@Description	Search for mangas based on given criteria
+				//	@Description	Full-text search over title, alternative titles, description, author and tags with prefix matching,
+				//	@Description	ranking and highlighted snippets. Optional filters: tag, website_id, status; paginated by page/limit.
 				//	@Tags			mangas
 				//	@Accept			json
 				//	@Produce		json
 				//	@Param			query	body		handlers.SearchRequest	true	"Search criteria"
-				//	@Success		200		{array}		models.Manga
+				//	@Success		200		{array}		repositories.MangaSearchResult
 				//	@Failure		400		{object}	handlers.ErrorResponse
 				//	@Failure		500		{object}	handlers.ErrorResponse
 				//	@Router			/mangas/search [post]
@@ -172,6 +409,51 @@ func main() {
 				mangas.POST("/:id/favorite", handlers.FavoriteManga(mangaService))
 			}
 
+			//	@Summary		List tags
+			//	@Description	List all tags with the number of mangas using each
+			//	@Tags			tags
+			//	@Accept			json
+			//	@Produce		json
+			//	@Param			category	query		string	false	"Category (genre, theme, demographic, format, content_warning)"
+			//	@Success		200	{array}		repositories.TagUsage
+			//	@Failure		401	{object}	handlers.ErrorResponse
+			//	@Failure		500	{object}	handlers.ErrorResponse
+			//	@Security		ApiKeyAuth
+			//	@Router			/tags [get]
+			protected.GET("/tags", handlers.GetTags(tagService))
+
+			authors := protected.Group("/authors")
+			{
+				//	@Summary		Get an author
+				//	@Description	Get an author or artist with the mangas crediting them and their roles
+				//	@Tags			authors
+				//	@Produce		json
+				//	@Param			id	path		int	true	"Author ID"
+				//	@Success		200	{object}	services.CreatorProfile
+				//	@Failure		404	{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/authors/{id} [get]
+				authors.GET("/:id", handlers.GetCreator(creatorService))
+				//	@Summary		Follow an author
+				//	@Description	Get notified when a newly discovered manga credits this author or artist
+				//	@Tags			authors
+				//	@Produce		json
+				//	@Param			id	path		int	true	"Author ID"
+				//	@Success		200	{object}	handlers.SuccessResponse
+				//	@Failure		404	{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/authors/{id}/follow [post]
+				authors.POST("/:id/follow", handlers.FollowCreator(creatorService))
+				//	@Summary		Unfollow an author
+				//	@Tags			authors
+				//	@Produce		json
+				//	@Param			id	path		int	true	"Author ID"
+				//	@Success		200	{object}	handlers.SuccessResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/authors/{id}/follow [delete]
+				authors.DELETE("/:id/follow", handlers.UnfollowCreator(creatorService))
+			}
+
 			bookmarks := protected.Group("/bookmarks")
 			{
 				//	@Summary		Set a bookmark for a manga
@@ -217,11 +499,36 @@ func main() {
 				//	@Security		ApiKeyAuth
 				//	@Router			/users/me [get]
 				users.GET("/me", handlers.GetCurrentUser(userRepo))
+				//	@Summary		Update user settings
+				//	@Description	Choose how mangas rated adult are shown in lists, search and favorites: hide, blur or show
+				//	@Tags			users
+				//	@Accept			json
+				//	@Produce		json
+				//	@Param			settings	body		object{adult_content=string}	true	"Settings"
+				//	@Success		200			{object}	object{adult_content=string}
+				//	@Failure		400			{object}	handlers.ErrorResponse
+				//	@Failure		401			{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/users/settings [put]
+				users.PUT("/settings", handlers.UpdateUserSettings(userRepo))
+				//	@Summary		Get followed authors
+				//	@Description	List the authors and artists the current user follows
+				//	@Tags			users
+				//	@Produce		json
+				//	@Success		200	{array}		models.Creator
+				//	@Failure		401	{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/users/followed-authors [get]
+				users.GET("/followed-authors", handlers.GetFollowedCreators(creatorService))
 				//	@Summary		Get user's favorite mangas
 				//	@Description	Retrieve a list of mangas favorited by the current user
 				//	@Tags			users
 				//	@Accept			json
 				//	@Produce		json
+				//	@Param			state	query		string	false	"Release state filter (ongoing, hiatus, completed, dropped)"
+				//	@Param			after	query		string	false	"Opaque cursor; with after or limit the response is a cursor page"
+				//	@Param			limit	query		int		false	"Page size (1-100)"
+				//	@Param			count	query		bool	false	"Include the total number of favorites"
 				//	@Success		200	{array}		models.Manga
 				//	@Failure		401	{object}	handlers.ErrorResponse
 				//	@Failure		500	{object}	handlers.ErrorResponse
@@ -229,16 +536,272 @@ func main() {
 				//	@Router			/users/favorites [get]
 				users.GET("/favorites", handlers.GetUserFavorites(mangaService))
 				//	@Summary		Get user's notifications
-				//	@Description	Retrieve a list of notifications for the current user
+				//	@Description	Retrieve the current user's notifications, newest first; archived ones only with archived=true
 				//	@Tags			users
 				//	@Accept			json
 				//	@Produce		json
-				//	@Success		200	{array}		models.Notification
-				//	@Failure		401	{object}	handlers.ErrorResponse
-				//	@Failure		500	{object}	handlers.ErrorResponse
+				//	@Param			manga_id	query		int		false	"Only notifications about this manga"
+				//	@Param			type		query		string	false	"Only this type (chapter, new_work, removed, state)"
+				//	@Param			unread		query		bool	false	"Only unread notifications"
+				//	@Param			archived	query		bool	false	"List archived notifications instead of the inbox"
+				//	@Param			after		query		string	false	"Opaque cursor; with after or limit the response is a cursor page"
+				//	@Param			limit		query		int		false	"Page size (1-100)"
+				//	@Param			count		query		bool	false	"Include the total number of notifications"
+				//	@Success		200			{array}		models.Notification
+				//	@Failure		400			{object}	handlers.ErrorResponse
+				//	@Failure		401			{object}	handlers.ErrorResponse
+				//	@Failure		500			{object}	handlers.ErrorResponse
 				//	@Security		ApiKeyAuth
 				//	@Router			/users/notifications [get]
 				users.GET("/notifications", handlers.GetNotifications(notificationService))
+				//	@Summary		Count unread notifications
+				//	@Description	Number of unread notifications in the inbox, leaving out archived ones
+				//	@Tags			users
+				//	@Produce		json
+				//	@Success		200	{object}	map[string]int
+				//	@Security		ApiKeyAuth
+				//	@Router			/users/notifications/unread-count [get]
+				users.GET("/notifications/unread-count", handlers.GetUnreadNotificationCount(notificationService))
+				//	@Summary		Mark notifications read or unread
+				//	@Description	Mark the notifications with the given ids, or all sent at or before a time, read (default) or unread
+				//	@Tags			users
+				//	@Accept			json
+				//	@Produce		json
+				//	@Param			request	body		handlers.MarkNotificationsReadRequest	true	"ids (up to 500) or before (RFC 3339), and read"
+				//	@Success		200		{object}	map[string]int
+				//	@Failure		400		{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/users/notifications/read [post]
+				users.POST("/notifications/read", handlers.MarkNotificationsRead(notificationService))
+				//	@Summary		Mark a notification read
+				//	@Tags			users
+				//	@Produce		json
+				//	@Param			id	path		int	true	"Notification ID"
+				//	@Success		200	{object}	map[string]int
+				//	@Failure		404	{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/users/notifications/{id}/read [put]
+				users.PUT("/notifications/:id/read", handlers.MarkNotificationRead(notificationService, true))
+				//	@Summary		Mark a notification unread
+				//	@Tags			users
+				//	@Produce		json
+				//	@Param			id	path		int	true	"Notification ID"
+				//	@Success		200	{object}	map[string]int
+				//	@Failure		404	{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/users/notifications/{id}/read [delete]
+				users.DELETE("/notifications/:id/read", handlers.MarkNotificationRead(notificationService, false))
+				//	@Summary		Archive a notification
+				//	@Description	Hide a notification from the inbox; it stays listed with archived=true
+				//	@Tags			users
+				//	@Produce		json
+				//	@Param			id	path		int	true	"Notification ID"
+				//	@Success		200	{object}	map[string]string
+				//	@Failure		404	{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/users/notifications/{id}/archive [put]
+				users.PUT("/notifications/:id/archive", handlers.ArchiveNotification(notificationService, true))
+				//	@Summary		Unarchive a notification
+				//	@Description	Bring an archived notification back to the inbox
+				//	@Tags			users
+				//	@Produce		json
+				//	@Param			id	path		int	true	"Notification ID"
+				//	@Success		200	{object}	map[string]string
+				//	@Failure		404	{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/users/notifications/{id}/archive [delete]
+				users.DELETE("/notifications/:id/archive", handlers.ArchiveNotification(notificationService, false))
+				//	@Summary		Delete a notification
+				//	@Tags			users
+				//	@Produce		json
+				//	@Param			id	path		int	true	"Notification ID"
+				//	@Success		200	{object}	map[string]string
+				//	@Failure		404	{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/users/notifications/{id} [delete]
+				users.DELETE("/notifications/:id", handlers.DeleteNotification(notificationService))
+				//	@Summary		Get notification channels
+				//	@Description	List the configured notification channels and the current user's channel subscriptions
+				//	@Tags			users
+				//	@Produce		json
+				//	@Success		200	{object}	handlers.ChannelsResponse
+				//	@Failure		401	{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/users/channels [get]
+				users.GET("/channels", handlers.GetChannels(channelService))
+				//	@Summary		Subscribe to a notification channel
+				//	@Description	Receive notifications over a channel; target and config are channel-specific and validated by it
+				//	@Tags			users
+				//	@Accept			json
+				//	@Produce		json
+				//	@Param			subscription	body		services.ChannelInput	true	"Channel, target and config"
+				//	@Success		201				{object}	models.ChannelSubscription
+				//	@Failure		400				{object}	handlers.ErrorResponse
+				//	@Failure		401				{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/users/channels [post]
+				users.POST("/channels", handlers.SubscribeChannel(channelService))
+				//	@Summary		Update a channel subscription
+				//	@Description	Change the target or config of a subscription, or enable/disable it
+				//	@Tags			users
+				//	@Accept			json
+				//	@Produce		json
+				//	@Param			id				path		int						true	"Subscription ID"
+				//	@Param			subscription	body		services.ChannelInput	true	"Fields to change"
+				//	@Success		200				{object}	models.ChannelSubscription
+				//	@Failure		400				{object}	handlers.ErrorResponse
+				//	@Failure		404				{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/users/channels/{id} [put]
+				users.PUT("/channels/:id", handlers.UpdateChannelSubscription(channelService))
+				//	@Summary		Delete a channel subscription
+				//	@Tags			users
+				//	@Produce		json
+				//	@Param			id	path		int	true	"Subscription ID"
+				//	@Success		200	{object}	handlers.SuccessResponse
+				//	@Failure		404	{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/users/channels/{id} [delete]
+				users.DELETE("/channels/:id", handlers.UnsubscribeChannel(channelService))
+				//	@Summary		Get channel subscription deliveries
+				//	@Description	The latest 100 deliveries over a subscription with status, attempts and last error
+				//	@Tags			users
+				//	@Produce		json
+				//	@Param			id	path		int	true	"Subscription ID"
+				//	@Success		200	{array}		models.NotificationDelivery
+				//	@Failure		404	{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/users/channels/{id}/deliveries [get]
+				users.GET("/channels/:id/deliveries", handlers.GetChannelDeliveries(channelService, deliveryService))
+				//	@Summary		Send a test notification
+				//	@Description	Deliver a test message over a subscription right away and report the outcome
+				//	@Tags			users
+				//	@Produce		json
+				//	@Param			id	path		int	true	"Subscription ID"
+				//	@Success		200	{object}	map[string]string
+				//	@Failure		404	{object}	handlers.ErrorResponse
+				//	@Failure		502	{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/users/channels/{id}/test [post]
+				users.POST("/channels/:id/test", handlers.SendChannelTest(channelService))
+				//	@Summary		Get notification settings
+				//	@Description	Whether notifications are delivered instantly or batched into hourly, daily or weekly digests, quiet hours and whether they are paused
+				//	@Tags			users
+				//	@Produce		json
+				//	@Success		200	{object}	services.NotificationSettings
+				//	@Security		ApiKeyAuth
+				//	@Router			/users/notification-settings [get]
+				users.GET("/notification-settings", handlers.GetNotificationSettings(deliveryService))
+				//	@Summary		Update notification settings
+				//	@Description	Switch between instant delivery and digests sent hourly, daily at a local time or weekly, set quiet hours or pause all deliveries; held notifications move to the new schedule
+				//	@Tags			users
+				//	@Accept			json
+				//	@Produce		json
+				//	@Param			settings	body		services.NotificationSettings	true	"Mode, time (HH:MM), weekday (0 is Sunday), timezone, paused and quiet hours"
+				//	@Success		200			{object}	services.NotificationSettings
+				//	@Failure		400			{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/users/notification-settings [put]
+				users.PUT("/notification-settings", handlers.UpdateNotificationSettings(deliveryService))
+				//	@Summary		Get notification preferences
+				//	@Description	List the user's notification preferences about favorites; favorites without one notify about everything
+				//	@Tags			users
+				//	@Produce		json
+				//	@Success		200	{array}		models.FavoritePreference
+				//	@Security		ApiKeyAuth
+				//	@Router			/users/notification-preferences [get]
+				users.GET("/notification-preferences", handlers.GetNotificationPreferences(notificationService))
+				//	@Summary		Set a notification preference
+				//	@Description	Mute a favorite, or be notified only every N chapters or only on completion
+				//	@Tags			users
+				//	@Accept			json
+				//	@Produce		json
+				//	@Param			manga_id	path		int							true	"Manga ID"
+				//	@Param			preference	body		services.PreferenceInput	true	"Preference"
+				//	@Success		200			{object}	models.FavoritePreference
+				//	@Failure		400			{object}	handlers.ErrorResponse
+				//	@Failure		404			{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/users/notification-preferences/{manga_id} [put]
+				users.PUT("/notification-preferences/:manga_id", handlers.SetNotificationPreference(notificationService))
+				//	@Summary		Reset a notification preference
+				//	@Description	Be notified about everything of a favorite again
+				//	@Tags			users
+				//	@Produce		json
+				//	@Param			manga_id	path		int	true	"Manga ID"
+				//	@Success		200			{object}	map[string]string
+				//	@Failure		404			{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/users/notification-preferences/{manga_id} [delete]
+				users.DELETE("/notification-preferences/:manga_id", handlers.ResetNotificationPreference(notificationService))
+				//	@Summary		Get the Web Push public key
+				//	@Description	VAPID public key to pass as applicationServerKey when subscribing a browser to push
+				//	@Tags			users
+				//	@Produce		json
+				//	@Success		200	{object}	map[string]string
+				//	@Failure		503	{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/users/push/key [get]
+				users.GET("/push/key", handlers.GetPushPublicKey(pushService))
+				//	@Summary		Register a push subscription
+				//	@Description	Receive Web Push notifications on a device; the body is the browser's PushSubscription JSON
+				//	@Tags			users
+				//	@Accept			json
+				//	@Produce		json
+				//	@Param			subscription	body		services.PushSubscriptionInput	true	"Endpoint and keys"
+				//	@Success		201				{object}	models.ChannelSubscription
+				//	@Failure		400				{object}	handlers.ErrorResponse
+				//	@Failure		503				{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/users/push/subscriptions [post]
+				users.POST("/push/subscriptions", handlers.RegisterPushSubscription(pushService))
+				//	@Summary		Unregister a push subscription
+				//	@Description	Stop Web Push notifications on the device with the given endpoint
+				//	@Tags			users
+				//	@Accept			json
+				//	@Produce		json
+				//	@Param			subscription	body		services.PushSubscriptionInput	true	"Endpoint"
+				//	@Success		200				{object}	map[string]string
+				//	@Failure		404				{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/users/push/subscriptions [delete]
+				users.DELETE("/push/subscriptions", handlers.UnregisterPushSubscription(pushService))
+				//	@Summary		Get release calendar
+				//	@Description	List actual and estimated releases of the user's favorites between from and to (defaults to the next 7 days)
+				//	@Tags			users
+				//	@Accept			json
+				//	@Produce		json
+				//	@Param			from	query		string	false	"Start, RFC 3339 or YYYY-MM-DD"
+				//	@Param			to		query		string	false	"End (exclusive), RFC 3339 or YYYY-MM-DD"
+				//	@Success		200		{array}		services.CalendarEntry
+				//	@Failure		400		{object}	handlers.ErrorResponse
+				//	@Failure		401		{object}	handlers.ErrorResponse
+				//	@Failure		500		{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/users/calendar [get]
+				users.GET("/calendar", handlers.GetCalendar(calendarService))
+				//	@Summary		Rotate iCalendar feed URL
+				//	@Description	Issue a new secret iCalendar feed URL for the user's favorites, revoking the previous one
+				//	@Tags			users
+				//	@Accept			json
+				//	@Produce		json
+				//	@Success		200	{object}	map[string]string
+				//	@Failure		401	{object}	handlers.ErrorResponse
+				//	@Failure		500	{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/users/calendar/feed [post]
+				users.POST("/calendar/feed", handlers.RotateCalendarFeed(calendarService, cfg.PublicURL))
+				if telegramService != nil {
+					//	@Summary		Link a Telegram chat
+					//	@Description	Issue a one-time code, valid for 15 minutes, to send the bot as /start <code> from the chat to link
+					//	@Tags			users
+					//	@Produce		json
+					//	@Success		201	{object}	services.TelegramLink
+					//	@Failure		401	{object}	handlers.ErrorResponse
+					//	@Security		ApiKeyAuth
+					//	@Router			/users/telegram/link [post]
+					users.POST("/telegram/link", handlers.CreateTelegramLink(telegramService))
+				}
 			}
 
 			favorites := protected.Group("/favorites")
@@ -248,6 +811,10 @@ func main() {
 				//	@Tags			favorites
 				//	@Accept			json
 				//	@Produce		json
+				//	@Param			state	query		string	false	"Release state filter (ongoing, hiatus, completed, dropped)"
+				//	@Param			after	query		string	false	"Opaque cursor; with after or limit the response is a cursor page"
+				//	@Param			limit	query		int		false	"Page size (1-100)"
+				//	@Param			count	query		bool	false	"Include the total number of favorites"
 				//	@Success		200	{array}		models.Manga
 				//	@Failure		401	{object}	handlers.ErrorResponse
 				//	@Failure		500	{object}	handlers.ErrorResponse
@@ -287,7 +854,9 @@ func main() {
 				//	@Tags			favorites
 				//	@Accept			json
 				//	@Produce		json
-				//	@Success		200	{array}		models.MangaUpdate
+				//	@Param			since	query		string	false	"RFC 3339 timestamp, defaults to 24 hours ago"
+				//	@Param			include	query		string	false	"Comma-separated expansions (tags, chapters, website); returns full mangas instead of summaries"
+				//	@Success		200	{array}		repositories.MangaSummary
 				//	@Failure		401	{object}	handlers.ErrorResponse
 				//	@Failure		500	{object}	handlers.ErrorResponse
 				//	@Security		ApiKeyAuth
@@ -298,26 +867,407 @@ func main() {
 
 		// Admin routes
 		admin := api.Group("/admin")
-		admin.Use(authMiddleware, middlewares.AdminMiddleware(userRepo, cfg.JWTSecret))
+		admin.Use(authMiddleware, middlewares.AdminMiddleware(userRepo))
 		{
 			//	@Summary		Add a new website
-			//	@Description	Add a new website to the system for manga scraping
+			//	@Description	Add a new website to the system for manga scraping; fails with 400 if no scraper supports its URL
 			//	@Tags			admin
 			//	@Accept			json
 			//	@Produce		json
 			//	@Param			website	body		handlers.WebsiteRequest	true	"Website details"
-			//	@Success		201		{object}	handlers.SuccessResponse
+			//	@Success		201		{object}	models.Website
 			//	@Failure		400		{object}	handlers.ErrorResponse
 			//	@Failure		401		{object}	handlers.ErrorResponse
 			//	@Failure		403		{object}	handlers.ErrorResponse
 			//	@Failure		500		{object}	handlers.ErrorResponse
 			//	@Security		ApiKeyAuth
 			//	@Router			/admin/websites [post]
-			admin.POST("/websites", handlers.AddWebsite(mangaService))
+			admin.POST("/websites", handlers.AddWebsite(websiteService))
+			//	@Summary		List websites
+			//	@Description	List all websites with their schedule, including disabled ones
+			//	@Tags			admin
+			//	@Produce		json
+			//	@Success		200	{array}	models.Website
+			//	@Security		ApiKeyAuth
+			//	@Router			/admin/websites [get]
+			admin.GET("/websites", handlers.GetWebsites(websiteService))
+			//	@Summary		Update a website
+			//	@Description	Rename, enable or disable a website, set its check interval (minutes) and active hours (UTC), or move it to a new URL
+			//	@Tags			admin
+			//	@Accept			json
+			//	@Produce		json
+			//	@Param			id		path		int						true	"Website ID"
+			//	@Param			website	body		services.WebsiteInput	true	"Fields to change"
+			//	@Success		200		{object}	models.Website
+			//	@Failure		400		{object}	handlers.ErrorResponse
+			//	@Failure		404		{object}	handlers.ErrorResponse
+			//	@Security		ApiKeyAuth
+			//	@Router			/admin/websites/{id} [put]
+			admin.PUT("/websites/:id", handlers.UpdateWebsite(websiteService))
+			//	@Summary		Delete a website
+			//	@Description	Stop tracking a website; its mangas are kept
+			//	@Tags			admin
+			//	@Produce		json
+			//	@Param			id	path		int	true	"Website ID"
+			//	@Success		200	{object}	handlers.SuccessResponse
+			//	@Failure		404	{object}	handlers.ErrorResponse
+			//	@Security		ApiKeyAuth
+			//	@Router			/admin/websites/{id} [delete]
+			admin.DELETE("/websites/:id", handlers.DeleteWebsite(websiteService))
+			//	@Summary		Move a website
+			//	@Description	Move a website to a new base URL after a domain change. The old URL becomes an alias, and manga external URLs and chapter URLs are rewritten
+			//	@Tags			admin
+			//	@Accept			json
+			//	@Produce		json
+			//	@Param			id		path		int					true	"Website ID"
+			//	@Param			move	body		object{url=string}	true	"New base URL"
+			//	@Success		200		{object}	repositories.WebsiteMove
+			//	@Failure		400		{object}	handlers.ErrorResponse
+			//	@Failure		404		{object}	handlers.ErrorResponse
+			//	@Security		ApiKeyAuth
+			//	@Router			/admin/websites/{id}/move [post]
+			admin.POST("/websites/:id/move", handlers.MoveWebsite(websiteService))
+			//	@Summary		Add a website alias
+			//	@Description	Record another base URL of a website, such as a mirror or a former domain
+			//	@Tags			admin
+			//	@Accept			json
+			//	@Produce		json
+			//	@Param			id		path		int					true	"Website ID"
+			//	@Param			alias	body		object{url=string}	true	"Alias URL"
+			//	@Success		201		{object}	models.WebsiteAlias
+			//	@Failure		400		{object}	handlers.ErrorResponse
+			//	@Failure		404		{object}	handlers.ErrorResponse
+			//	@Security		ApiKeyAuth
+			//	@Router			/admin/websites/{id}/aliases [post]
+			admin.POST("/websites/:id/aliases", handlers.AddWebsiteAlias(websiteService))
+			//	@Summary		Delete a website alias
+			//	@Tags			admin
+			//	@Produce		json
+			//	@Param			alias_id	path		int	true	"Alias ID"
+			//	@Success		200			{object}	handlers.SuccessResponse
+			//	@Failure		404			{object}	handlers.ErrorResponse
+			//	@Security		ApiKeyAuth
+			//	@Router			/admin/websites/aliases/{alias_id} [delete]
+			admin.DELETE("/websites/aliases/:alias_id", handlers.DeleteWebsiteAlias(websiteService))
+			//	@Summary		Scrape a website now
+			//	@Description	Check a website immediately, ignoring its schedule, and report new mangas, chapters and errors
+			//	@Tags			admin
+			//	@Produce		json
+			//	@Param			id	path		int	true	"Website ID"
+			//	@Success		200	{object}	services.ScrapeReport
+			//	@Failure		404	{object}	handlers.ErrorResponse
+			//	@Failure		409	{object}	handlers.ErrorResponse
+			//	@Security		ApiKeyAuth
+			//	@Router			/admin/websites/{id}/scrape [post]
+			admin.POST("/websites/:id/scrape", handlers.ScrapeWebsite(scraperService))
+			//	@Summary		Dry-run a scraper
+			//	@Description	Run a scraper method (latest, details or chapters) against a URL and return the parsed result with warnings; nothing is stored
+			//	@Tags			admin
+			//	@Accept			json
+			//	@Produce		json
+			//	@Param			test	body		handlers.ScraperTestRequest	true	"URL, method and optional slug"
+			//	@Success		200		{object}	services.ScraperTestResult
+			//	@Failure		400		{object}	handlers.ErrorResponse
+			//	@Failure		404		{object}	handlers.ErrorResponse
+			//	@Failure		502		{object}	handlers.ErrorResponse
+			//	@Security		ApiKeyAuth
+			//	@Router			/admin/scrapers/test [post]
+			admin.POST("/scrapers/test", handlers.TestScraper(scraperService))
+			//	@Summary		List notification deliveries
+			//	@Description	List deliveries over notification channels with their status, attempts, last error and next retry, newest first
+			//	@Tags			admin
+			//	@Produce		json
+			//	@Param			status	query		string	false	"pending, sent or failed"
+			//	@Param			channel	query		string	false	"Channel name"
+			//	@Param			user_id	query		int		false	"Recipient"
+			//	@Param			limit	query		int		false	"Maximum entries (1-500, default 100)"
+			//	@Success		200		{array}		models.NotificationDelivery
+			//	@Failure		400		{object}	handlers.ErrorResponse
+			//	@Security		ApiKeyAuth
+			//	@Router			/admin/deliveries [get]
+			admin.GET("/deliveries", handlers.GetDeliveries(deliveryService))
+			//	@Summary		Retry a delivery
+			//	@Description	Send a delivery again on the next dispatcher run, with a fresh attempt budget
+			//	@Tags			admin
+			//	@Produce		json
+			//	@Param			id	path		int	true	"Delivery ID"
+			//	@Success		200	{object}	models.NotificationDelivery
+			//	@Failure		404	{object}	handlers.ErrorResponse
+			//	@Security		ApiKeyAuth
+			//	@Router			/admin/deliveries/{id}/retry [post]
+			admin.POST("/deliveries/:id/retry", handlers.RetryDelivery(deliveryService))
+			//	@Summary		List admin webhooks
+			//	@Description	Webhooks receiving events about every manga, not only a user's favorites
+			//	@Tags			admin
+			//	@Produce		json
+			//	@Success		200	{array}	models.ChannelSubscription
+			//	@Security		ApiKeyAuth
+			//	@Router			/admin/webhooks [get]
+			admin.GET("/webhooks", handlers.GetAdminWebhooks(channelService))
+			//	@Summary		Add an admin webhook
+			//	@Description	Send events about every manga to a webhook; target and config are validated like user webhooks
+			//	@Tags			admin
+			//	@Accept			json
+			//	@Produce		json
+			//	@Param			webhook	body		services.ChannelInput	true	"Webhook URL as target and config (format, secret, events); channel defaults to webhook"
+			//	@Success		201		{object}	models.ChannelSubscription
+			//	@Failure		400		{object}	handlers.ErrorResponse
+			//	@Security		ApiKeyAuth
+			//	@Router			/admin/webhooks [post]
+			admin.POST("/webhooks", handlers.CreateAdminWebhook(channelService))
+			//	@Summary		Update an admin webhook
+			//	@Description	Change the URL or config of an admin webhook, or enable/disable it
+			//	@Tags			admin
+			//	@Accept			json
+			//	@Produce		json
+			//	@Param			id		path		int						true	"Webhook ID"
+			//	@Param			webhook	body		services.ChannelInput	true	"Fields to change"
+			//	@Success		200		{object}	models.ChannelSubscription
+			//	@Failure		400		{object}	handlers.ErrorResponse
+			//	@Failure		404		{object}	handlers.ErrorResponse
+			//	@Security		ApiKeyAuth
+			//	@Router			/admin/webhooks/{id} [put]
+			admin.PUT("/webhooks/:id", handlers.UpdateAdminWebhook(channelService))
+			//	@Summary		Delete an admin webhook
+			//	@Tags			admin
+			//	@Produce		json
+			//	@Param			id	path		int	true	"Webhook ID"
+			//	@Success		200	{object}	handlers.SuccessResponse
+			//	@Failure		404	{object}	handlers.ErrorResponse
+			//	@Security		ApiKeyAuth
+			//	@Router			/admin/webhooks/{id} [delete]
+			admin.DELETE("/webhooks/:id", handlers.DeleteAdminWebhook(channelService))
+			//	@Summary		Get admin webhook deliveries
+			//	@Description	The latest 100 deliveries to an admin webhook with status, attempts and last error
+			//	@Tags			admin
+			//	@Produce		json
+			//	@Param			id	path		int	true	"Webhook ID"
+			//	@Success		200	{array}		models.NotificationDelivery
+			//	@Failure		404	{object}	handlers.ErrorResponse
+			//	@Security		ApiKeyAuth
+			//	@Router			/admin/webhooks/{id}/deliveries [get]
+			admin.GET("/webhooks/:id/deliveries", handlers.GetAdminWebhookDeliveries(channelService, deliveryService))
+			//	@Summary		Send a test event to an admin webhook
+			//	@Description	Deliver a test event right away and report the outcome
+			//	@Tags			admin
+			//	@Produce		json
+			//	@Param			id	path		int	true	"Webhook ID"
+			//	@Success		200	{object}	map[string]string
+			//	@Failure		404	{object}	handlers.ErrorResponse
+			//	@Failure		502	{object}	handlers.ErrorResponse
+			//	@Security		ApiKeyAuth
+			//	@Router			/admin/webhooks/{id}/test [post]
+			admin.POST("/webhooks/:id/test", handlers.SendAdminWebhookTest(channelService))
+
+			adminMangas := admin.Group("/mangas")
+			{
+				//	@Summary		Create a manga
+				//	@Description	Add a manga by hand; the change is recorded in the audit log
+				//	@Tags			admin
+				//	@Accept			json
+				//	@Produce		json
+				//	@Param			manga	body		services.MangaInput	true	"Manga fields; title and website_id are required"
+				//	@Success		201		{object}	models.Manga
+				//	@Failure		400		{object}	handlers.ErrorResponse
+				//	@Failure		403		{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/admin/mangas [post]
+				adminMangas.POST("", handlers.CreateManga(adminService))
+				//	@Summary		Update a manga
+				//	@Description	Correct manga metadata. Set lock to protect the edited fields from later scrapes, or locked_fields to replace the locked set
+				//	@Tags			admin
+				//	@Accept			json
+				//	@Produce		json
+				//	@Param			id		path		int					true	"Manga ID"
+				//	@Param			manga	body		services.MangaInput	true	"Fields to change"
+				//	@Success		200		{object}	models.Manga
+				//	@Failure		400		{object}	handlers.ErrorResponse
+				//	@Failure		404		{object}	handlers.ErrorResponse
+				//	@Failure		409		{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/admin/mangas/{id} [put]
+				adminMangas.PUT("/:id", handlers.UpdateManga(adminService))
+				//	@Summary		Delete a manga
+				//	@Description	Soft-delete a manga; it can be restored
+				//	@Tags			admin
+				//	@Produce		json
+				//	@Param			id	path		int	true	"Manga ID"
+				//	@Success		200	{object}	handlers.SuccessResponse
+				//	@Failure		404	{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/admin/mangas/{id} [delete]
+				adminMangas.DELETE("/:id", handlers.DeleteManga(adminService))
+				//	@Summary		Restore a manga
+				//	@Tags			admin
+				//	@Produce		json
+				//	@Param			id	path		int	true	"Manga ID"
+				//	@Success		200	{object}	models.Manga
+				//	@Failure		404	{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/admin/mangas/{id}/restore [post]
+				adminMangas.POST("/:id/restore", handlers.RestoreManga(adminService))
+				//	@Summary		Refresh manga metadata
+				//	@Description	Re-scrape a manga's metadata from its source now; locked fields are left alone
+				//	@Tags			admin
+				//	@Produce		json
+				//	@Param			id	path		int	true	"Manga ID"
+				//	@Success		200	{array}		models.MangaMetadataChange
+				//	@Failure		404	{object}	handlers.ErrorResponse
+				//	@Failure		502	{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/admin/mangas/{id}/refresh [post]
+				adminMangas.POST("/:id/refresh", handlers.RefreshMangaMetadata(metadataService))
+				//	@Summary		Create a chapter
+				//	@Tags			admin
+				//	@Accept			json
+				//	@Produce		json
+				//	@Param			id		path		int						true	"Manga ID"
+				//	@Param			chapter	body		services.ChapterInput	true	"Chapter fields; number is required"
+				//	@Success		201		{object}	models.Chapter
+				//	@Failure		400		{object}	handlers.ErrorResponse
+				//	@Failure		404		{object}	handlers.ErrorResponse
+				//	@Failure		409		{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/admin/mangas/{id}/chapters [post]
+				adminMangas.POST("/:id/chapters", handlers.CreateChapter(adminService))
+			}
+
+			adminChapters := admin.Group("/chapters")
+			{
+				//	@Summary		Update a chapter
+				//	@Tags			admin
+				//	@Accept			json
+				//	@Produce		json
+				//	@Param			id		path		int						true	"Chapter ID"
+				//	@Param			chapter	body		services.ChapterInput	true	"Fields to change"
+				//	@Success		200		{object}	models.Chapter
+				//	@Failure		404		{object}	handlers.ErrorResponse
+				//	@Failure		409		{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/admin/chapters/{id} [put]
+				adminChapters.PUT("/:id", handlers.UpdateChapter(adminService))
+				//	@Summary		Delete a chapter
+				//	@Description	Soft-delete a chapter; it can be restored
+				//	@Tags			admin
+				//	@Produce		json
+				//	@Param			id	path		int	true	"Chapter ID"
+				//	@Success		200	{object}	handlers.SuccessResponse
+				//	@Failure		404	{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/admin/chapters/{id} [delete]
+				adminChapters.DELETE("/:id", handlers.DeleteChapter(adminService))
+				//	@Summary		Restore a chapter
+				//	@Tags			admin
+				//	@Produce		json
+				//	@Param			id	path		int	true	"Chapter ID"
+				//	@Success		200	{object}	models.Chapter
+				//	@Failure		404	{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/admin/chapters/{id}/restore [post]
+				adminChapters.POST("/:id/restore", handlers.RestoreChapter(adminService))
+			}
+
+			//	@Summary		Get the audit log
+			//	@Description	List recent administrative changes with before/after values, newest first
+			//	@Tags			admin
+			//	@Produce		json
+			//	@Param			entity_type	query		string	false	"manga or chapter"
+			//	@Param			entity_id	query		int		false	"Entity ID"
+			//	@Param			actor_id	query		int		false	"Acting admin's user ID"
+			//	@Param			limit		query		int		false	"Maximum entries (1-500, default 100)"
+			//	@Success		200			{array}		services.AuditEntry
+			//	@Security		ApiKeyAuth
+			//	@Router			/admin/audit-log [get]
+			admin.GET("/audit-log", handlers.GetAuditLog(adminService))
+
+			tags := admin.Group("/tags")
+			{
+				//	@Summary		Create a tag
+				//	@Description	Create a canonical tag; category and content rating default to the built-in taxonomy
+				//	@Tags			admin
+				//	@Accept			json
+				//	@Produce		json
+				//	@Param			tag	body		object{name=string,category=string,content_rating=string}	true	"Tag"
+				//	@Success		201	{object}	models.Tag
+				//	@Failure		400	{object}	handlers.ErrorResponse
+				//	@Failure		409	{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/admin/tags [post]
+				tags.POST("", handlers.CreateTag(tagService))
+				//	@Summary		Update a tag
+				//	@Description	Rename or reclassify a tag; renaming fails with 409 if the name already identifies another tag or alias
+				//	@Tags			admin
+				//	@Accept			json
+				//	@Produce		json
+				//	@Param			id	path		int															true	"Tag ID"
+				//	@Param			tag	body		object{name=string,category=string,content_rating=string}	true	"Fields to change"
+				//	@Success		200	{object}	models.Tag
+				//	@Failure		400	{object}	handlers.ErrorResponse
+				//	@Failure		404	{object}	handlers.ErrorResponse
+				//	@Failure		409	{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/admin/tags/{id} [put]
+				tags.PUT("/:id", handlers.UpdateTag(tagService))
+				//	@Summary		Delete a tag
+				//	@Description	Delete a tag with its aliases and manga associations
+				//	@Tags			admin
+				//	@Produce		json
+				//	@Param			id	path		int	true	"Tag ID"
+				//	@Success		200	{object}	handlers.SuccessResponse
+				//	@Failure		404	{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/admin/tags/{id} [delete]
+				tags.DELETE("/:id", handlers.DeleteTag(tagService))
+				//	@Summary		List tag aliases
+				//	@Description	List the source texts mapped onto a tag
+				//	@Tags			admin
+				//	@Produce		json
+				//	@Param			id	path		int	true	"Tag ID"
+				//	@Success		200	{array}		models.TagAlias
+				//	@Failure		404	{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/admin/tags/{id}/aliases [get]
+				tags.GET("/:id/aliases", handlers.GetTagAliases(tagService))
+				//	@Summary		Add a tag alias
+				//	@Description	Map source text (e.g. "Science Fiction") onto a tag for scraper ingestion
+				//	@Tags			admin
+				//	@Accept			json
+				//	@Produce		json
+				//	@Param			id		path		int						true	"Tag ID"
+				//	@Param			alias	body		object{alias=string}	true	"Source text"
+				//	@Success		201		{object}	models.TagAlias
+				//	@Failure		400		{object}	handlers.ErrorResponse
+				//	@Failure		404		{object}	handlers.ErrorResponse
+				//	@Failure		409		{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/admin/tags/{id}/aliases [post]
+				tags.POST("/:id/aliases", handlers.AddTagAlias(tagService))
+				//	@Summary		Delete a tag alias
+				//	@Tags			admin
+				//	@Produce		json
+				//	@Param			alias_id	path		int	true	"Alias ID"
+				//	@Success		200			{object}	handlers.SuccessResponse
+				//	@Failure		404			{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/admin/tags/aliases/{alias_id} [delete]
+				tags.DELETE("/aliases/:alias_id", handlers.DeleteTagAlias(tagService))
+				//	@Summary		Merge tags
+				//	@Description	Fold source tags into this tag: associations are rewritten and source names become aliases
+				//	@Tags			admin
+				//	@Accept			json
+				//	@Produce		json
+				//	@Param			id		path		int							true	"Target tag ID"
+				//	@Param			merge	body		object{source_ids=[]int}	true	"Tags to merge"
+				//	@Success		200		{object}	handlers.SuccessResponse
+				//	@Failure		400		{object}	handlers.ErrorResponse
+				//	@Failure		404		{object}	handlers.ErrorResponse
+				//	@Security		ApiKeyAuth
+				//	@Router			/admin/tags/{id}/merge [post]
+				tags.POST("/:id/merge", handlers.MergeTags(tagService))
+			}
 		}
 	}
 
-	services.RegisterScrapers() // Register scrapers for supported websites
 
 	// Swagger documentation route
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -1,7 +1,12 @@
 package config
 
 import (
+	"fmt"
+	"net"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -9,15 +14,81 @@ import (
 type Config struct {
 	DatabaseURL   string
 	ServerAddress string
-	JWTSecret     string // Added for JWT
+	JWTSecret     string   // Added for JWT
+	PublicURL     string   // Externally reachable base URL, used for feed and callback links
+	AdminUsers    []string // Usernames granted the admin role at startup
+
+	// SMTP server for email notifications; the email channel is only
+	// available when SMTPHost is set.
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	// Web Push is available when VAPIDSubject, a mailto: or https: contact,
+	// is set. Without VAPIDPrivateKey a key is generated and stored.
+	VAPIDSubject    string
+	VAPIDPrivateKey string
+
+	// The Telegram bot runs when TelegramBotToken is set. It receives updates
+	// by long polling, or by webhook under PublicURL when
+	// TelegramWebhookSecret is set.
+	TelegramBotToken      string
+	TelegramWebhookSecret string
+	TelegramAPIURL        string
+
+	// SelfHostedAllowedNetworks are private networks ntfy and Gotify servers
+	// may be on, e.g. the operator's own LAN; others are refused like for
+	// webhooks.
+	SelfHostedAllowedNetworks []*net.IPNet
+
+	// NotificationRetention is how long read notifications are kept before
+	// they are purged; zero keeps them forever.
+	NotificationRetention time.Duration
 }
 
 func LoadConfig() (*Config, error) {
 	_ = godotenv.Load() // Ignore error if .env not found
 
+	retentionDays, err := strconv.Atoi(getEnvDefault("NOTIFICATION_RETENTION_DAYS", "90"))
+	if err != nil || retentionDays < 0 {
+		return nil, fmt.Errorf("NOTIFICATION_RETENTION_DAYS must be a number of days")
+	}
+
+	var allowedNetworks []*net.IPNet
+	for _, cidr := range strings.FieldsFunc(os.Getenv("SELF_HOSTED_ALLOWED_NETWORKS"), func(r rune) bool { return r == ',' || r == ' ' }) {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("SELF_HOSTED_ALLOWED_NETWORKS must be comma-separated CIDRs: %w", err)
+		}
+		allowedNetworks = append(allowedNetworks, network)
+	}
+
 	return &Config{
-		DatabaseURL:   os.Getenv("DATABASE_URL"),
-		ServerAddress: os.Getenv("SERVER_ADDRESS"),
-		JWTSecret:     os.Getenv("JWT_SECRET"), // Set in .env
+		DatabaseURL:               os.Getenv("DATABASE_URL"),
+		ServerAddress:             os.Getenv("SERVER_ADDRESS"),
+		JWTSecret:                 os.Getenv("JWT_SECRET"), // Set in .env
+		PublicURL:                 strings.TrimSuffix(os.Getenv("PUBLIC_URL"), "/"),
+		AdminUsers:                strings.FieldsFunc(os.Getenv("ADMIN_USERNAMES"), func(r rune) bool { return r == ',' || r == ' ' }),
+		SMTPHost:                  os.Getenv("SMTP_HOST"),
+		SMTPPort:                  getEnvDefault("SMTP_PORT", "587"),
+		SMTPUsername:              os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:              os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:                  os.Getenv("SMTP_FROM"),
+		VAPIDSubject:              os.Getenv("VAPID_SUBJECT"),
+		VAPIDPrivateKey:           os.Getenv("VAPID_PRIVATE_KEY"),
+		TelegramBotToken:          os.Getenv("TELEGRAM_BOT_TOKEN"),
+		TelegramWebhookSecret:     os.Getenv("TELEGRAM_WEBHOOK_SECRET"),
+		TelegramAPIURL:            getEnvDefault("TELEGRAM_API_URL", "https://api.telegram.org"),
+		SelfHostedAllowedNetworks: allowedNetworks,
+		NotificationRetention:     time.Duration(retentionDays) * 24 * time.Hour,
 	}, nil
 }
+
+func getEnvDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,34 @@
+package database
+
+import "gorm.io/gorm"
+
+// creatorStatements credit the free-text author of mangas scraped before
+// creators existed. Joined authors are split on the scraper's ", " separator;
+// the key expression mirrors repositories.CreatorKey.
+var creatorStatements = []string{
+	`INSERT INTO creators (name, key, created_at, updated_at)
+	SELECT DISTINCT ON (key) name, key, now(), now() FROM (
+		SELECT trim(part) AS name, lower(regexp_replace(trim(part), '\s+', ' ', 'g')) AS key
+		FROM mangas CROSS JOIN LATERAL regexp_split_to_table(mangas.author, ',') AS part
+		WHERE mangas.deleted_at IS NULL
+			AND NOT EXISTS (SELECT 1 FROM manga_credits WHERE manga_credits.manga_id = mangas.id)
+	) AS authors
+	WHERE name <> ''
+	ON CONFLICT (key) DO NOTHING`,
+	`INSERT INTO manga_credits (manga_id, creator_id, role)
+	SELECT DISTINCT mangas.id, creators.id, 'author'
+	FROM mangas CROSS JOIN LATERAL regexp_split_to_table(mangas.author, ',') AS part
+		JOIN creators ON creators.key = lower(regexp_replace(trim(part), '\s+', ' ', 'g'))
+	WHERE mangas.deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM manga_credits WHERE manga_credits.manga_id = mangas.id)
+	ON CONFLICT DO NOTHING`,
+}
+
+func setupCreators(db *gorm.DB) error {
+	for _, stmt := range creatorStatements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
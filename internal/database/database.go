@@ -16,13 +16,35 @@ func NewDB(dsn string) (*gorm.DB, error) {
 }
 
 func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	err := db.AutoMigrate(
 		&models.Website{},
+		&models.WebsiteAlias{},
 		&models.Manga{},
 		&models.Tag{},
+		&models.TagAlias{},
+		&models.Creator{},
+		&models.MangaCredit{},
 		&models.Chapter{},
 		&models.User{},
 		&models.Bookmark{},
 		&models.Notification{},
+		&models.ChannelSubscription{},
+		&models.ChannelMute{},
+		&models.FavoritePreference{},
+		&models.NotificationDelivery{},
+		&models.ServerKey{},
+		&models.TelegramLinkCode{},
+		&models.MangaStateTransition{},
+		&models.MangaMetadataChange{},
+		&models.AuditLog{},
 	)
+	if err != nil {
+		return err
+	}
+	for _, setup := range []func(*gorm.DB) error{setupSearch, setupTags, setupCreators, setupSlugs} {
+		if err := setup(db); err != nil {
+			return err
+		}
+	}
+	return nil
 }
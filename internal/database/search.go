@@ -0,0 +1,60 @@
+package database
+
+import "gorm.io/gorm"
+
+// searchStatements maintain two derived columns on mangas:
+//   - search_vector, a weighted tsvector over title and alternative titles (A),
+//     author and tag names (B) and description (C), for full-text search;
+//   - search_title, the lowercased titles with punctuation collapsed to spaces,
+//     trigram-indexed for typo-tolerant matching and autocomplete.
+//
+// Both are kept up to date by triggers so that every write path, including tag
+// association changes, is indexed without application code.
+var searchStatements = []string{
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+	`ALTER TABLE mangas ADD COLUMN IF NOT EXISTS search_vector tsvector`,
+	`ALTER TABLE mangas ADD COLUMN IF NOT EXISTS search_title text`,
+	`CREATE INDEX IF NOT EXISTS idx_mangas_search_vector ON mangas USING GIN (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS idx_mangas_search_title ON mangas USING GIN (search_title gin_trgm_ops)`,
+	`CREATE OR REPLACE FUNCTION mangas_search_vector_update() RETURNS trigger AS $$
+BEGIN
+	NEW.search_title := trim(regexp_replace(
+		lower(coalesce(NEW.title, '') || ' ' || coalesce(NEW.alt_titles, '')),
+		'[^[:alnum:]]+', ' ', 'g'));
+	NEW.search_vector :=
+		setweight(to_tsvector('simple', coalesce(NEW.title, '')), 'A') ||
+		setweight(to_tsvector('simple', coalesce(NEW.alt_titles, '')), 'A') ||
+		setweight(to_tsvector('simple', coalesce(NEW.author, '')), 'B') ||
+		setweight(to_tsvector('simple', coalesce((
+			SELECT string_agg(tags.name, ' ')
+			FROM manga_tags JOIN tags ON tags.id = manga_tags.tag_id
+			WHERE manga_tags.manga_id = NEW.id
+		), '')), 'B') ||
+		setweight(to_tsvector('simple', coalesce(NEW.description, '')), 'C');
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS mangas_search_vector_trigger ON mangas`,
+	`CREATE TRIGGER mangas_search_vector_trigger BEFORE INSERT OR UPDATE ON mangas
+	FOR EACH ROW EXECUTE FUNCTION mangas_search_vector_update()`,
+	`CREATE OR REPLACE FUNCTION manga_tags_search_vector_touch() RETURNS trigger AS $$
+BEGIN
+	UPDATE mangas SET search_vector = NULL WHERE id = COALESCE(NEW.manga_id, OLD.manga_id);
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS manga_tags_search_vector_trigger ON manga_tags`,
+	`CREATE TRIGGER manga_tags_search_vector_trigger AFTER INSERT OR DELETE ON manga_tags
+	FOR EACH ROW EXECUTE FUNCTION manga_tags_search_vector_touch()`,
+	// Backfill rows created before the trigger existed.
+	`UPDATE mangas SET search_vector = NULL WHERE search_vector IS NULL OR search_title IS NULL`,
+}
+
+func setupSearch(db *gorm.DB) error {
+	for _, stmt := range searchStatements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
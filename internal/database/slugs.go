@@ -0,0 +1,29 @@
+package database
+
+import "gorm.io/gorm"
+
+// slugStatements recover the source slug and URL of mangas scraped before
+// they were stored. Chapter URLs have the form "<website>manga/<slug>/...".
+var slugStatements = []string{
+	`UPDATE mangas SET slug = chapter_slugs.slug
+	FROM (
+		SELECT DISTINCT ON (manga_id) manga_id,
+			split_part(substring(url FROM strpos(url, '/manga/') + 7), '/', 1) AS slug
+		FROM chapters
+		WHERE strpos(url, '/manga/') > 0
+		ORDER BY manga_id, id
+	) AS chapter_slugs
+	WHERE chapter_slugs.manga_id = mangas.id AND coalesce(mangas.slug, '') = '' AND chapter_slugs.slug <> ''`,
+	`UPDATE mangas SET external_url = websites.url || 'manga/' || mangas.slug || '/'
+	FROM websites
+	WHERE websites.id = mangas.website_id AND coalesce(mangas.slug, '') <> '' AND coalesce(mangas.external_url, '') = ''`,
+}
+
+func setupSlugs(db *gorm.DB) error {
+	for _, stmt := range slugStatements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
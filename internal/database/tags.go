@@ -0,0 +1,64 @@
+package database
+
+import (
+	"strings"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// contentRatingStatements keep mangas.content_rating equal to the most
+// restrictive rating among the manga's tags. The trigger runs on every write
+// to mangas; tag association changes already touch the manga row through the
+// search triggers, and tag updates re-index the mangas carrying them.
+var contentRatingStatements = []string{
+	`CREATE OR REPLACE FUNCTION mangas_content_rating_update() RETURNS trigger AS $$
+BEGIN
+	NEW.content_rating := CASE (
+		SELECT max(CASE tags.content_rating WHEN 'adult' THEN 2 WHEN 'suggestive' THEN 1 ELSE 0 END)
+		FROM manga_tags JOIN tags ON tags.id = manga_tags.tag_id
+		WHERE manga_tags.manga_id = NEW.id
+	) WHEN 2 THEN 'adult' WHEN 1 THEN 'suggestive' ELSE 'safe' END;
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS mangas_content_rating_trigger ON mangas`,
+	`CREATE TRIGGER mangas_content_rating_trigger BEFORE INSERT OR UPDATE ON mangas
+	FOR EACH ROW EXECUTE FUNCTION mangas_content_rating_update()`,
+}
+
+// setupTags backfills the normalized key of tags created before keys existed,
+// classifies unclassified tags with the default taxonomy and rates mangas that
+// predate content ratings. The key expression mirrors repositories.TagKey for
+// ASCII and most letters.
+func setupTags(db *gorm.DB) error {
+	err := db.Exec(`UPDATE tags SET key = lower(regexp_replace(name, '[^[:alnum:]]+', '', 'g')) WHERE key IS NULL OR key = ''`).Error
+	if err != nil {
+		return err
+	}
+
+	rows := make([]string, 0, len(repositories.DefaultTagTaxonomy))
+	args := make([]interface{}, 0, 3*len(repositories.DefaultTagTaxonomy))
+	for key, class := range repositories.DefaultTagTaxonomy {
+		rows = append(rows, "(?, ?, ?)")
+		args = append(args, key, class.Category, class.ContentRating)
+	}
+	err = db.Exec(`UPDATE tags SET category = t.category, content_rating = t.content_rating
+		FROM (VALUES `+strings.Join(rows, ", ")+`) AS t(key, category, content_rating)
+		WHERE tags.key = t.key AND (tags.category IS NULL OR tags.category = '')`, args...).Error
+	if err != nil {
+		return err
+	}
+	err = db.Exec(`UPDATE tags SET category = ?, content_rating = '' WHERE category IS NULL OR category = ''`, models.TagCategoryGenre).Error
+	if err != nil {
+		return err
+	}
+
+	for _, stmt := range contentRatingStatements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return db.Exec(`UPDATE mangas SET content_rating = NULL WHERE content_rating IS NULL OR content_rating = ''`).Error
+}
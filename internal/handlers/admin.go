@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"github.com/sidler1/manga-backend/internal/services"
+	"github.com/sidler1/manga-backend/scraper"
+	"gorm.io/gorm"
+)
+
+// CreateManga handles the admin request to add a manga by hand
+func CreateManga(s services.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input services.MangaInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		manga, err := s.CreateManga(c.GetUint("userID"), input)
+		if err != nil {
+			respondAdminError(c, err, "manga not found")
+			return
+		}
+		c.JSON(http.StatusCreated, manga)
+	}
+}
+
+// UpdateManga handles the admin request to correct manga metadata
+func UpdateManga(s services.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid manga id")
+		if !ok {
+			return
+		}
+		var input services.MangaInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		manga, err := s.UpdateManga(c.GetUint("userID"), id, input)
+		if err != nil {
+			respondAdminError(c, err, "manga not found")
+			return
+		}
+		c.JSON(http.StatusOK, manga)
+	}
+}
+
+// DeleteManga handles the admin request to soft-delete a manga
+func DeleteManga(s services.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid manga id")
+		if !ok {
+			return
+		}
+		if err := s.DeleteManga(c.GetUint("userID"), id); err != nil {
+			respondAdminError(c, err, "manga not found")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "manga deleted"})
+	}
+}
+
+// RestoreManga handles the admin request to undo a manga deletion
+func RestoreManga(s services.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid manga id")
+		if !ok {
+			return
+		}
+		manga, err := s.RestoreManga(c.GetUint("userID"), id)
+		if err != nil {
+			respondAdminError(c, err, "deleted manga not found")
+			return
+		}
+		c.JSON(http.StatusOK, manga)
+	}
+}
+
+// RefreshMangaMetadata handles the admin request to re-scrape a manga's metadata now
+func RefreshMangaMetadata(s services.MetadataService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid manga id")
+		if !ok {
+			return
+		}
+		changes, err := s.RefreshManga(id)
+		if errors.Is(err, scraper.ErrScrapeFailed) || errors.Is(err, services.ErrNoScraper) {
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			respondAdminError(c, err, "manga not found")
+			return
+		}
+		c.JSON(http.StatusOK, changes)
+	}
+}
+
+// CreateChapter handles the admin request to add a chapter by hand
+func CreateChapter(s services.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		mangaID, ok := parseIDParam(c, "id", "invalid manga id")
+		if !ok {
+			return
+		}
+		var input services.ChapterInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		chapter, err := s.CreateChapter(c.GetUint("userID"), mangaID, input)
+		if err != nil {
+			respondAdminError(c, err, "manga not found")
+			return
+		}
+		c.JSON(http.StatusCreated, chapter)
+	}
+}
+
+// UpdateChapter handles the admin request to correct a chapter
+func UpdateChapter(s services.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid chapter id")
+		if !ok {
+			return
+		}
+		var input services.ChapterInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		chapter, err := s.UpdateChapter(c.GetUint("userID"), id, input)
+		if err != nil {
+			respondAdminError(c, err, "chapter not found")
+			return
+		}
+		c.JSON(http.StatusOK, chapter)
+	}
+}
+
+// DeleteChapter handles the admin request to soft-delete a chapter
+func DeleteChapter(s services.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid chapter id")
+		if !ok {
+			return
+		}
+		if err := s.DeleteChapter(c.GetUint("userID"), id); err != nil {
+			respondAdminError(c, err, "chapter not found")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "chapter deleted"})
+	}
+}
+
+// RestoreChapter handles the admin request to undo a chapter deletion
+func RestoreChapter(s services.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid chapter id")
+		if !ok {
+			return
+		}
+		chapter, err := s.RestoreChapter(c.GetUint("userID"), id)
+		if err != nil {
+			respondAdminError(c, err, "deleted chapter not found")
+			return
+		}
+		c.JSON(http.StatusOK, chapter)
+	}
+}
+
+// GetAuditLog handles the admin request to list recent changes, filterable by
+// entity_type, entity_id and actor_id
+func GetAuditLog(s services.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter := repositories.AuditFilter{EntityType: c.Query("entity_type"), Limit: 100}
+		for name, dst := range map[string]*uint{"entity_id": &filter.EntityID, "actor_id": &filter.ActorID} {
+			if value := c.Query(name); value != "" {
+				id, err := strconv.ParseUint(value, 10, 32)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+					return
+				}
+				*dst = uint(id)
+			}
+		}
+		if value := c.Query("limit"); value != "" {
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit < 1 || limit > 500 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+				return
+			}
+			filter.Limit = limit
+		}
+		entries, err := s.GetAuditLog(filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, entries)
+	}
+}
+
+// respondAdminError maps admin service errors to responses, using notFound
+// as the message for missing records
+func respondAdminError(c *gin.Context, err error, notFound string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+	case errors.Is(err, services.ErrInvalidAdminInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrDeleted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
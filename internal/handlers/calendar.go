@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sidler1/manga-backend/internal/services"
+	"gorm.io/gorm"
+)
+
+const maxCalendarSpan = 366 * 24 * time.Hour
+
+// GetCalendar handles the request to list actual and estimated releases of the user's favorites
+func GetCalendar(s services.CalendarService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetUint("userID")
+		if userID == 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		now := time.Now()
+		from, err := parseCalendarTime(c.Query("from"), now)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from parameter"})
+			return
+		}
+		to, err := parseCalendarTime(c.Query("to"), from.Add(7*24*time.Hour))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to parameter"})
+			return
+		}
+		if !to.After(from) || to.Sub(from) > maxCalendarSpan {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be after from and at most one year later"})
+			return
+		}
+
+		entries, err := s.GetCalendar(userID, from, to)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"from":    from,
+			"to":      to,
+			"entries": entries,
+		})
+	}
+}
+
+// RotateCalendarFeed handles the request to (re)issue the user's secret iCalendar feed URL
+func RotateCalendarFeed(s services.CalendarService, publicURL string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetUint("userID")
+		if userID == 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		token, err := s.RotateFeedToken(userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"url": publicURL + "/api/v1/calendar/" + token + ".ics"})
+	}
+}
+
+// GetCalendarFeed serves the iCalendar feed identified by its secret token
+func GetCalendarFeed(s services.CalendarService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := strings.TrimSuffix(c.Param("token"), ".ics")
+		feed, err := s.RenderFeed(token)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "calendar not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(feed))
+	}
+}
+
+// parseCalendarTime accepts RFC 3339 timestamps and plain dates, returning
+// fallback for empty input.
+func parseCalendarTime(value string, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"github.com/sidler1/manga-backend/internal/services"
+	"github.com/sidler1/manga-backend/notifier"
+	"gorm.io/gorm"
+)
+
+// ChannelsResponse lists the configured channels and the user's subscriptions.
+type ChannelsResponse struct {
+	Channels      []string                     `json:"channels"`
+	Subscriptions []models.ChannelSubscription `json:"subscriptions"`
+}
+
+// GetChannels handles the request to list notification channels and the current user's subscriptions
+func GetChannels(s services.ChannelService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subscriptions, err := s.GetSubscriptions(c.GetUint("userID"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, ChannelsResponse{Channels: s.ListChannels(), Subscriptions: subscriptions})
+	}
+}
+
+func SubscribeChannel(s services.ChannelService) gin.HandlerFunc {
+	return subscribeChannel(s, currentUser)
+}
+
+func subscribeChannel(s services.ChannelService, owner subscriberFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input services.ChannelInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		subscription, err := s.Subscribe(owner(c), input)
+		if err != nil {
+			respondChannelError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, subscription)
+	}
+}
+
+// UpdateChannelSubscription handles the request to change the destination of a subscription or pause it
+func UpdateChannelSubscription(s services.ChannelService) gin.HandlerFunc {
+	return updateChannelSubscription(s, currentUser)
+}
+
+func updateChannelSubscription(s services.ChannelService, owner subscriberFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid subscription id")
+		if !ok {
+			return
+		}
+		var input services.ChannelInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		subscription, err := s.UpdateSubscription(owner(c), id, input)
+		if err != nil {
+			respondChannelError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, subscription)
+	}
+}
+
+func UnsubscribeChannel(s services.ChannelService) gin.HandlerFunc {
+	return unsubscribeChannel(s, currentUser)
+}
+
+func unsubscribeChannel(s services.ChannelService, owner subscriberFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid subscription id")
+		if !ok {
+			return
+		}
+		if err := s.Unsubscribe(owner(c), id); err != nil {
+			respondChannelError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "subscription deleted"})
+	}
+}
+
+// GetChannelDeliveries handles the request to list the recent deliveries of one of the user's subscriptions
+func GetChannelDeliveries(s services.ChannelService, d services.DeliveryService) gin.HandlerFunc {
+	return getChannelDeliveries(s, d, currentUser)
+}
+
+func getChannelDeliveries(s services.ChannelService, d services.DeliveryService, owner subscriberFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid subscription id")
+		if !ok {
+			return
+		}
+		if _, err := s.GetSubscription(owner(c), id); err != nil {
+			respondChannelError(c, err)
+			return
+		}
+		deliveries, err := d.ListDeliveries(repositories.DeliveryFilter{SubscriptionID: id, Limit: 100})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, deliveries)
+	}
+}
+
+// SendChannelTest handles the request to send a test notification over a subscription
+func SendChannelTest(s services.ChannelService) gin.HandlerFunc {
+	return sendChannelTest(s, currentUser)
+}
+
+func sendChannelTest(s services.ChannelService, owner subscriberFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid subscription id")
+		if !ok {
+			return
+		}
+		err := s.SendTest(owner(c), id)
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, services.ErrUnknownChannel) {
+			respondChannelError(c, err)
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "test notification delivered"})
+	}
+}
+
+// GetNotificationSettings handles the request to get when the user's notifications are delivered
+func GetNotificationSettings(d services.DeliveryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		settings, err := d.GetSettings(c.GetUint("userID"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, settings)
+	}
+}
+
+// UpdateNotificationSettings handles the request to choose instant delivery or digests, quiet hours and pausing
+func UpdateNotificationSettings(d services.DeliveryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input services.NotificationSettings
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		settings, err := d.UpdateSettings(c.GetUint("userID"), input)
+		if errors.Is(err, services.ErrInvalidNotificationSettings) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, settings)
+	}
+}
+
+// unsubscribeConfirmPage asks for confirmation before unsubscribing, so link
+// scanners following the email's links do not unsubscribe anyone. The form
+// posts back to the same URL.
+const unsubscribeConfirmPage = `<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif;">
+	<p>Stop these notifications?</p>
+	<form method="post"><button type="submit">Unsubscribe</button></form>
+</body>
+</html>`
+
+// ConfirmUnsubscribe handles opening a signed unsubscribe link in a browser
+func ConfirmUnsubscribe() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(unsubscribeConfirmPage))
+	}
+}
+
+// UnsubscribeByToken handles a signed unsubscribe link, either confirmed in
+// the browser or posted one-click by the mail client (RFC 8058)
+func UnsubscribeByToken(s services.ChannelService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := s.UnsubscribeByToken(c.Param("token"))
+		if errors.Is(err, services.ErrInvalidUnsubscribeToken) || errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "unsubscribe link not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte("<p>You have been unsubscribed.</p>"))
+	}
+}
+
+// GetDeliveries handles the admin request to list notification deliveries,
+// filterable by status, channel and user_id
+func GetDeliveries(s services.DeliveryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter := repositories.DeliveryFilter{Status: c.Query("status"), Channel: c.Query("channel"), Limit: 100}
+		if value := c.Query("user_id"); value != "" {
+			id, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+				return
+			}
+			filter.UserID = uint(id)
+		}
+		if value := c.Query("limit"); value != "" {
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit < 1 || limit > 500 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+				return
+			}
+			filter.Limit = limit
+		}
+		deliveries, err := s.ListDeliveries(filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, deliveries)
+	}
+}
+
+// RetryDelivery handles the admin request to send a delivery again right away
+func RetryDelivery(s services.DeliveryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid delivery id")
+		if !ok {
+			return
+		}
+		delivery, err := s.RetryDelivery(id)
+		if err != nil {
+			respondChannelError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, delivery)
+	}
+}
+
+// GetAdminWebhooks handles the admin request to list the webhooks receiving events about every manga
+func GetAdminWebhooks(s services.ChannelService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		webhooks, err := s.GetSubscriptions(services.IntegrationUserID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, webhooks)
+	}
+}
+
+// CreateAdminWebhook handles the admin request to add a webhook receiving events about every manga
+func CreateAdminWebhook(s services.ChannelService) gin.HandlerFunc {
+	return subscribeChannel(s, integrations)
+}
+
+// UpdateAdminWebhook handles the admin request to change or pause an admin webhook
+func UpdateAdminWebhook(s services.ChannelService) gin.HandlerFunc {
+	return updateChannelSubscription(s, integrations)
+}
+
+// DeleteAdminWebhook handles the admin request to remove an admin webhook
+func DeleteAdminWebhook(s services.ChannelService) gin.HandlerFunc {
+	return unsubscribeChannel(s, integrations)
+}
+
+// GetAdminWebhookDeliveries handles the admin request to list the recent deliveries of an admin webhook
+func GetAdminWebhookDeliveries(s services.ChannelService, d services.DeliveryService) gin.HandlerFunc {
+	return getChannelDeliveries(s, d, integrations)
+}
+
+// SendAdminWebhookTest handles the admin request to send a test event to an admin webhook
+func SendAdminWebhookTest(s services.ChannelService) gin.HandlerFunc {
+	return sendChannelTest(s, integrations)
+}
+
+// subscriberFunc returns whose subscriptions a request manages.
+type subscriberFunc func(c *gin.Context) uint
+
+// currentUser manages the caller's own subscriptions.
+func currentUser(c *gin.Context) uint {
+	return c.GetUint("userID")
+}
+
+// integrations manages the admin webhooks.
+func integrations(*gin.Context) uint {
+	return services.IntegrationUserID
+}
+
+func respondChannelError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.Is(err, services.ErrUnknownChannel), errors.Is(err, notifier.ErrInvalidDestination):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sidler1/manga-backend/internal/services"
+	"gorm.io/gorm"
+)
+
+// GetCreator handles the request to get an author or artist with their works
+func GetCreator(s services.CreatorService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid author id")
+		if !ok {
+			return
+		}
+		profile, err := s.GetCreator(c.GetUint("userID"), id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "author not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, profile)
+	}
+}
+
+func FollowCreator(s services.CreatorService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid author id")
+		if !ok {
+			return
+		}
+		err := s.Follow(c.GetUint("userID"), id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "author not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "author followed"})
+	}
+}
+
+func UnfollowCreator(s services.CreatorService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid author id")
+		if !ok {
+			return
+		}
+		if err := s.Unfollow(c.GetUint("userID"), id); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "author unfollowed"})
+	}
+}
+
+func GetFollowedCreators(s services.CreatorService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		creators, err := s.GetFollowed(c.GetUint("userID"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, creators)
+	}
+}
@@ -1 +1,68 @@
 package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sidler1/manga-backend/internal/services"
+	"gorm.io/gorm"
+)
+
+// GetNotificationPreferences handles the request to list the user's notification preferences about favorites
+func GetNotificationPreferences(s services.NotificationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		preferences, err := s.GetPreferences(c.GetUint("userID"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, preferences)
+	}
+}
+
+// SetNotificationPreference handles the request to mute a favorite or limit its notifications
+func SetNotificationPreference(s services.NotificationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		mangaID, ok := parseIDParam(c, "manga_id", "invalid manga id")
+		if !ok {
+			return
+		}
+		var input services.PreferenceInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		preference, err := s.SetPreference(c.GetUint("userID"), mangaID, input)
+		switch {
+		case errors.Is(err, services.ErrNotFavorite):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidPreference):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusOK, preference)
+		}
+	}
+}
+
+// ResetNotificationPreference handles the request to be notified about everything of a favorite again
+func ResetNotificationPreference(s services.NotificationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		mangaID, ok := parseIDParam(c, "manga_id", "invalid manga id")
+		if !ok {
+			return
+		}
+		err := s.ResetPreference(c.GetUint("userID"), mangaID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "no preference for this manga"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "preference removed"})
+	}
+}
@@ -1,17 +1,57 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sidler1/manga-backend/internal/repositories"
 	"github.com/sidler1/manga-backend/internal/services"
+	"gorm.io/gorm"
 )
 
 // GetMangas handles the request to get a list of mangas with pagination and filters
 func GetMangas(s services.MangaService) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		filter, sort, err := parseMangaListQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		include, err := parseIncludes(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// The page shape stays the default; passing after (empty for the
+		// first page) switches to cursor pages.
+		if _, ok := c.GetQuery("after"); ok {
+			pageReq, err := parsePageRequest(c, 20)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			summaries, info, err := s.GetPage(c.GetUint("userID"), filter, sort, pageReq)
+			if errors.Is(err, repositories.ErrInvalidCursor) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			mangas, err := expandSummaries(s, summaries, include)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusOK, pageResponse("mangas", mangas, pageReq, info))
+			return
+		}
+
 		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 		if page < 1 {
 			page = 1
@@ -21,10 +61,12 @@ func GetMangas(s services.MangaService) gin.HandlerFunc {
 			limit = 20
 		}
 
-		filters := c.QueryMap("filters")
-		sort := c.DefaultQuery("sort", "")
-
-		mangas, total, err := s.GetAll(page, limit, filters, sort)
+		summaries, total, err := s.GetAll(c.GetUint("userID"), page, limit, filter, sort)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		mangas, err := expandSummaries(s, summaries, include)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -48,12 +90,21 @@ func GetMangaChapters(s services.MangaService) gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid manga id"})
 			return
 		}
-		chapters, err := s.GetMangaChapters(uint(id))
+		page, err := parsePageRequest(c, 50)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		chapters, info, err := s.GetMangaChaptersPage(uint(id), page)
+		if errors.Is(err, repositories.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-		c.JSON(http.StatusOK, chapters)
+		c.JSON(http.StatusOK, pageResponse("chapters", chapters, page, info))
 	}
 }
 
@@ -136,7 +187,17 @@ func GetFavoriteUpdates(s services.MangaService) gin.HandlerFunc {
 		} else {
 			since = time.Now().Add(-24 * time.Hour) // Default to last 24 hours
 		}
-		updates, err := s.GetFavoriteUpdates(userID.(uint), since)
+		include, err := parseIncludes(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		summaries, err := s.GetFavoriteUpdates(userID.(uint), since)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		updates, err := expandSummaries(s, summaries, include)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -144,22 +205,127 @@ func GetFavoriteUpdates(s services.MangaService) gin.HandlerFunc {
 		c.JSON(http.StatusOK, updates)
 	}
 }
+
+// GetMangaReleaseHistory handles the request to list release state transitions of a manga
+func GetMangaReleaseHistory(s services.ReleaseStateService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idStr := c.Param("id")
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid manga id"})
+			return
+		}
+		transitions, err := s.GetTransitions(uint(id))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, transitions)
+	}
+}
+
+// GetMangaMetadataHistory handles the request to list metadata changes found by source refreshes
+func GetMangaMetadataHistory(s services.MetadataService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid manga id")
+		if !ok {
+			return
+		}
+		changes, err := s.GetHistory(id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, changes)
+	}
+}
+
+// GetMangaAlternatives handles the request to list other websites carrying a manga
+func GetMangaAlternatives(s services.AvailabilityService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid manga id")
+		if !ok {
+			return
+		}
+		alternatives, err := s.GetAlternatives(id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "manga not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, alternatives)
+	}
+}
+
+// SuggestMangas handles low-latency title autocomplete
+func SuggestMangas(s services.MangaService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := c.Query("q")
+		if len([]rune(query)) < 2 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "q must be at least 2 characters"})
+			return
+		}
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "8"))
+		if limit < 1 || limit > 20 {
+			limit = 8
+		}
+
+		suggestions, err := s.SuggestMangas(c.GetUint("userID"), query, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"suggestions": suggestions})
+	}
+}
+
+// SearchMangas handles full-text search over title, alternative titles, description, author and tags
 func SearchMangas(s services.MangaService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var searchQuery struct {
-			Query string `json:"query" binding:"required"`
+			Query     string `json:"query" binding:"required"`
+			Tag       string `json:"tag"`
+			WebsiteID uint   `json:"website_id"`
+			Status    string `json:"status"`
+			Page      int    `json:"page"`
+			Limit     int    `json:"limit"`
 		}
 		if err := c.ShouldBindJSON(&searchQuery); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid search query"})
 			return
 		}
+		if searchQuery.Status != "" && !services.IsValidReleaseState(searchQuery.Status) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+			return
+		}
+		if searchQuery.Page < 1 {
+			searchQuery.Page = 1
+		}
+		if searchQuery.Limit < 1 || searchQuery.Limit > 100 {
+			searchQuery.Limit = 20
+		}
 
-		results, err := s.SearchMangas(searchQuery.Query)
+		results, total, err := s.SearchMangas(c.GetUint("userID"), repositories.MangaSearchParams{
+			Query:     searchQuery.Query,
+			Tag:       searchQuery.Tag,
+			WebsiteID: searchQuery.WebsiteID,
+			Status:    searchQuery.Status,
+			Page:      searchQuery.Page,
+			Limit:     searchQuery.Limit,
+		})
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{"results": results})
+		c.JSON(http.StatusOK, gin.H{
+			"results": results,
+			"total":   total,
+			"page":    searchQuery.Page,
+			"limit":   searchQuery.Limit,
+		})
 	}
 }
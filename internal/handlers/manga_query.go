@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"github.com/sidler1/manga-backend/internal/services"
+)
+
+// parseMangaListQuery reads the filter and sort grammar of GET /mangas:
+//
+//	tags=a,b            only mangas tagged with every listed tag
+//	exclude_tags=c,d    no manga tagged with any listed tag
+//	status=ongoing,...  release states
+//	website=<id>        source website
+//	author=<text>       case-insensitive author substring
+//	updated_since=<t>   RFC 3339 timestamp or YYYY-MM-DD
+//	has_unread=true     favorites of the caller with chapters past their bookmark
+//	sort=<field>[:asc|:desc]  updated, title, popularity or estimated_next
+//
+// Any other value is rejected with a descriptive error.
+func parseMangaListQuery(c *gin.Context) (repositories.MangaFilter, repositories.MangaSort, error) {
+	var filter repositories.MangaFilter
+	var sort repositories.MangaSort
+
+	filter.IncludeTags = splitList(c.Query("tags"))
+	filter.ExcludeTags = splitList(c.Query("exclude_tags"))
+
+	filter.Statuses = splitList(c.Query("status"))
+	for _, status := range filter.Statuses {
+		if !services.IsValidReleaseState(status) {
+			return filter, sort, fmt.Errorf("invalid status %q: expected ongoing, hiatus, completed or dropped", status)
+		}
+	}
+
+	if website := c.Query("website"); website != "" {
+		id, err := strconv.ParseUint(website, 10, 32)
+		if err != nil || id == 0 {
+			return filter, sort, fmt.Errorf("invalid website %q: expected a website id", website)
+		}
+		filter.WebsiteID = uint(id)
+	}
+
+	filter.Author = strings.TrimSpace(c.Query("author"))
+
+	if since := c.Query("updated_since"); since != "" {
+		t, err := parseCalendarTime(since, time.Time{})
+		if err != nil {
+			return filter, sort, fmt.Errorf("invalid updated_since %q: expected RFC 3339 or YYYY-MM-DD", since)
+		}
+		filter.UpdatedSince = t
+	}
+
+	switch unread := c.Query("has_unread"); unread {
+	case "", "false":
+	case "true":
+		filter.UnreadForUserID = c.GetUint("userID")
+	default:
+		return filter, sort, fmt.Errorf("invalid has_unread %q: expected true or false", unread)
+	}
+
+	if raw := c.Query("sort"); raw != "" {
+		field, direction, _ := strings.Cut(raw, ":")
+		if !repositories.IsValidMangaSortField(field) {
+			return filter, sort, fmt.Errorf("invalid sort field %q: expected updated, title, popularity or estimated_next", field)
+		}
+		sort.Field = repositories.MangaSortField(field)
+		switch direction {
+		case "", "asc":
+		case "desc":
+			sort.Desc = true
+		default:
+			return filter, sort, fmt.Errorf("invalid sort direction %q: expected asc or desc", direction)
+		}
+	}
+
+	return filter, sort, nil
+}
+
+// parseIncludes reads ?include=tags,chapters,website, the associations a
+// client wants nested into list entries instead of the summary projection.
+func parseIncludes(c *gin.Context) (repositories.MangaIncludes, error) {
+	var include repositories.MangaIncludes
+	for _, name := range splitList(c.Query("include")) {
+		switch name {
+		case "tags":
+			include.Tags = true
+		case "chapters":
+			include.Chapters = true
+		case "website":
+			include.Website = true
+		default:
+			return include, fmt.Errorf("invalid include %q: expected tags, chapters or website", name)
+		}
+	}
+	return include, nil
+}
+
+// ExpandedManga is a full manga listed in place of its summary. It keeps the
+// summary's Blurred flag, set by the viewer's adult content preference.
+type ExpandedManga struct {
+	models.Manga
+	Blurred bool `json:"blurred"`
+}
+
+// expandSummaries returns summaries as they are, or the corresponding full
+// mangas with the requested associations when include is set. Summaries are
+// already filtered by the viewer's content policy, so only their mangas are
+// expanded and their Blurred flags carried over.
+func expandSummaries(s services.MangaService, summaries []repositories.MangaSummary, include repositories.MangaIncludes) (interface{}, error) {
+	if include.IsEmpty() {
+		return summaries, nil
+	}
+	ids := make([]uint, len(summaries))
+	blurred := make(map[uint]bool, len(summaries))
+	for i, summary := range summaries {
+		ids[i] = summary.ID
+		blurred[summary.ID] = summary.Blurred
+	}
+	mangas, err := s.ExpandMangas(ids, include)
+	if err != nil {
+		return nil, err
+	}
+	expanded := make([]ExpandedManga, len(mangas))
+	for i, m := range mangas {
+		expanded[i] = ExpandedManga{Manga: m, Blurred: blurred[m.ID]}
+	}
+	return expanded, nil
+}
+
+// splitList splits a comma-separated query value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
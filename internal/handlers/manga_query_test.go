@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"github.com/sidler1/manga-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testContext returns a gin context for a GET of target by userID.
+func testContext(target string, userID uint) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", target, nil)
+	if userID != 0 {
+		c.Set("userID", userID)
+	}
+	return c
+}
+
+func TestParseMangaListQuery(t *testing.T) {
+	tests := []struct {
+		query  string
+		filter repositories.MangaFilter
+		sort   repositories.MangaSort
+	}{
+		{"", repositories.MangaFilter{}, repositories.MangaSort{}},
+		{
+			"tags=Action,+Romance+,,&exclude_tags=Gore",
+			repositories.MangaFilter{IncludeTags: []string{"Action", "Romance"}, ExcludeTags: []string{"Gore"}},
+			repositories.MangaSort{},
+		},
+		{"status=ongoing,hiatus", repositories.MangaFilter{Statuses: []string{"ongoing", "hiatus"}}, repositories.MangaSort{}},
+		{"website=3&author=+Oda+", repositories.MangaFilter{WebsiteID: 3, Author: "Oda"}, repositories.MangaSort{}},
+		{
+			"updated_since=2025-03-01",
+			repositories.MangaFilter{UpdatedSince: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+			repositories.MangaSort{},
+		},
+		{"has_unread=true", repositories.MangaFilter{UnreadForUserID: 7}, repositories.MangaSort{}},
+		{"has_unread=false", repositories.MangaFilter{}, repositories.MangaSort{}},
+		{"sort=title", repositories.MangaFilter{}, repositories.MangaSort{Field: repositories.SortByTitle}},
+		{"sort=popularity:desc", repositories.MangaFilter{}, repositories.MangaSort{Field: repositories.SortByPopularity, Desc: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			filter, sort, err := parseMangaListQuery(testContext("/mangas?"+tt.query, 7))
+			require.NoError(t, err)
+			assert.Equal(t, tt.filter, filter)
+			assert.Equal(t, tt.sort, sort)
+		})
+	}
+}
+
+func TestParseMangaListQuery_Invalid(t *testing.T) {
+	tests := []struct {
+		query string
+		err   string
+	}{
+		{"status=finished", `invalid status "finished"`},
+		{"website=abc", `invalid website "abc"`},
+		{"website=0", `invalid website "0"`},
+		{"updated_since=yesterday", `invalid updated_since "yesterday"`},
+		{"has_unread=yes", `invalid has_unread "yes"`},
+		{"sort=rating", `invalid sort field "rating"`},
+		{"sort=title:up", `invalid sort direction "up"`},
+		{"sort=title%3BDROP+TABLE+mangas", `invalid sort field "title;DROP TABLE mangas"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, _, err := parseMangaListQuery(testContext("/mangas?"+tt.query, 7))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+type expandMangaService struct {
+	services.MangaService
+}
+
+func (expandMangaService) ExpandMangas(ids []uint, include repositories.MangaIncludes) ([]models.Manga, error) {
+	mangas := make([]models.Manga, len(ids))
+	for i, id := range ids {
+		mangas[i].ID = id
+	}
+	return mangas, nil
+}
+
+func TestExpandSummaries_KeepsBlurred(t *testing.T) {
+	summaries := []repositories.MangaSummary{{ID: 1}, {ID: 2, Blurred: true}}
+
+	got, err := expandSummaries(expandMangaService{}, summaries, repositories.MangaIncludes{})
+	require.NoError(t, err)
+	assert.Equal(t, summaries, got)
+
+	got, err = expandSummaries(expandMangaService{}, summaries, repositories.MangaIncludes{Tags: true})
+	require.NoError(t, err)
+	expanded := got.([]ExpandedManga)
+	require.Len(t, expanded, 2)
+	assert.Equal(t, uint(1), expanded[0].ID)
+	assert.False(t, expanded[0].Blurred)
+	assert.Equal(t, uint(2), expanded[1].ID)
+	assert.True(t, expanded[1].Blurred)
+}
+
+type listMangaService struct {
+	services.MangaService
+}
+
+func (listMangaService) GetAll(viewerID uint, page int, limit int, filter repositories.MangaFilter, sort repositories.MangaSort) ([]repositories.MangaSummary, int, error) {
+	return []repositories.MangaSummary{{ID: 1}}, 1, nil
+}
+
+func (listMangaService) GetPage(viewerID uint, filter repositories.MangaFilter, sort repositories.MangaSort, page repositories.PageRequest) ([]repositories.MangaSummary, repositories.PageInfo, error) {
+	return []repositories.MangaSummary{{ID: 1}}, repositories.PageInfo{NextCursor: "next"}, nil
+}
+
+func TestGetMangas_ResponseShape(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		query string
+		keys  []string
+	}{
+		{"", []string{"mangas", "total", "page", "limit"}},
+		{"?page=2&limit=10", []string{"mangas", "total", "page", "limit"}},
+		{"?after=", []string{"mangas", "next_cursor", "limit"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/mangas"+tt.query, nil)
+			c.Set("userID", uint(7))
+			GetMangas(listMangaService{})(c)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			got := make([]string, 0, len(body))
+			for key := range body {
+				got = append(got, key)
+			}
+			assert.ElementsMatch(t, tt.keys, got)
+		})
+	}
+}
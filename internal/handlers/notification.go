@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"github.com/sidler1/manga-backend/internal/services"
+	"gorm.io/gorm"
+)
+
+// maxNotificationBatch caps the IDs of one batch request.
+const maxNotificationBatch = 500
+
+// parseNotificationFilter reads the manga_id, type, unread=true and
+// archived=true filters of the notification list.
+func parseNotificationFilter(c *gin.Context) (repositories.NotificationFilter, error) {
+	filter := repositories.NotificationFilter{
+		Type:       c.Query("type"),
+		UnreadOnly: c.Query("unread") == "true",
+		Archived:   c.Query("archived") == "true",
+	}
+	if value := c.Query("manga_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return filter, errors.New("invalid manga_id")
+		}
+		filter.MangaID = uint(id)
+	}
+	switch filter.Type {
+	case "", models.NotificationTypeChapter, models.NotificationTypeNewWork, models.NotificationTypeRemoved, models.NotificationTypeState:
+	default:
+		return filter, errors.New("type must be chapter, new_work, removed or state")
+	}
+	return filter, nil
+}
+
+// GetUnreadNotificationCount handles the request to count the user's unread notifications
+func GetUnreadNotificationCount(s services.NotificationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		count, err := s.CountUnread(c.GetUint("userID"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"unread": count})
+	}
+}
+
+// MarkNotificationRead handles the request to mark one notification read, or unread
+func MarkNotificationRead(s services.NotificationService, read bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid notification id")
+		if !ok {
+			return
+		}
+		updated, err := s.MarkRead(c.GetUint("userID"), []uint{id}, read)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if updated == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "notification not found"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"updated": updated})
+	}
+}
+
+// MarkNotificationsReadRequest selects notifications by ids or, with before,
+// all sent until then. Read defaults to true.
+type MarkNotificationsReadRequest struct {
+	IDs    []uint     `json:"ids"`
+	Before *time.Time `json:"before"`
+	Read   *bool      `json:"read"`
+}
+
+// MarkNotificationsRead handles the request to mark a batch of notifications, or all before a time, read or unread
+func MarkNotificationsRead(s services.NotificationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input MarkNotificationsReadRequest
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if (len(input.IDs) == 0) == (input.Before == nil) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "either ids or before is required"})
+			return
+		}
+		if len(input.IDs) > maxNotificationBatch {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "at most 500 ids per request"})
+			return
+		}
+		read := input.Read == nil || *input.Read
+
+		userID := c.GetUint("userID")
+		var updated int64
+		var err error
+		if input.Before != nil {
+			updated, err = s.MarkReadBefore(userID, *input.Before, read)
+		} else {
+			updated, err = s.MarkRead(userID, input.IDs, read)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"updated": updated})
+	}
+}
+
+// ArchiveNotification handles the request to archive a notification, or bring it back to the inbox
+func ArchiveNotification(s services.NotificationService, archived bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid notification id")
+		if !ok {
+			return
+		}
+		err := s.ArchiveNotification(c.GetUint("userID"), id, archived)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "notification not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		message := "notification archived"
+		if !archived {
+			message = "notification restored to the inbox"
+		}
+		c.JSON(http.StatusOK, gin.H{"message": message})
+	}
+}
+
+// DeleteNotification handles the request to delete a notification; it is purged for good after the retention period
+func DeleteNotification(s services.NotificationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid notification id")
+		if !ok {
+			return
+		}
+		err := s.DeleteNotification(c.GetUint("userID"), id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "notification not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "notification deleted"})
+	}
+}
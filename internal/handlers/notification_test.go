@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sidler1/manga-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// inboxService owns notification 1 of user 7 only.
+type inboxService struct {
+	services.NotificationService
+	readIDs    []uint
+	readBefore *time.Time
+}
+
+func (s *inboxService) MarkRead(userID uint, ids []uint, read bool) (int64, error) {
+	s.readIDs = ids
+	if userID == 7 && len(ids) == 1 && ids[0] == 1 {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (s *inboxService) MarkReadBefore(userID uint, before time.Time, read bool) (int64, error) {
+	s.readBefore = &before
+	return 4, nil
+}
+
+func (s *inboxService) ArchiveNotification(userID, id uint, archived bool) error {
+	if userID != 7 || id != 1 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (s *inboxService) DeleteNotification(userID, id uint) error {
+	return s.ArchiveNotification(userID, id, true)
+}
+
+// serveNotification runs handler for a request by userID with :id set to id.
+func serveNotification(handler gin.HandlerFunc, userID uint, id, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/notifications", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: id}}
+	c.Set("userID", userID)
+	handler(c)
+	return w
+}
+
+func TestMarkNotificationsRead_Validation(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"ids", `{"ids": [1]}`, http.StatusOK},
+		{"before", `{"before": "2025-03-01T00:00:00Z"}`, http.StatusOK},
+		{"neither", `{}`, http.StatusBadRequest},
+		{"both", `{"ids": [1], "before": "2025-03-01T00:00:00Z"}`, http.StatusBadRequest},
+		{"too many ids", `{"ids": [` + strings.Repeat("1,", maxNotificationBatch) + `1]}`, http.StatusBadRequest},
+		{"invalid before", `{"before": "yesterday"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &inboxService{}
+			w := serveNotification(MarkNotificationsRead(s), 7, "", tt.body)
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+			if tt.status != http.StatusOK {
+				assert.Nil(t, s.readIDs)
+				assert.Nil(t, s.readBefore)
+			}
+		})
+	}
+}
+
+func TestNotificationHandlers_NotFound(t *testing.T) {
+	s := &inboxService{}
+	handlers := map[string]gin.HandlerFunc{
+		"read":    MarkNotificationRead(s, true),
+		"archive": ArchiveNotification(s, true),
+		"delete":  DeleteNotification(s),
+	}
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, http.StatusOK, serveNotification(handler, 7, "1", "").Code)
+			assert.Equal(t, http.StatusNotFound, serveNotification(handler, 8, "1", "").Code, "another user's notification")
+			assert.Equal(t, http.StatusNotFound, serveNotification(handler, 7, "2", "").Code)
+			assert.Equal(t, http.StatusBadRequest, serveNotification(handler, 7, "x", "").Code)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sidler1/manga-backend/internal/repositories"
+)
+
+// wantsCursorPage reports whether the client asked for a cursor page on an
+// endpoint that still returns a plain array by default.
+func wantsCursorPage(c *gin.Context) bool {
+	_, hasAfter := c.GetQuery("after")
+	_, hasLimit := c.GetQuery("limit")
+	return hasAfter || hasLimit
+}
+
+// parsePageRequest reads the keyset pagination parameters after, limit and
+// count=true.
+func parsePageRequest(c *gin.Context, defaultLimit int) (repositories.PageRequest, error) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit < 1 || limit > 100 {
+		return repositories.PageRequest{}, errors.New("limit must be between 1 and 100")
+	}
+	after, err := repositories.DecodeCursor(c.Query("after"))
+	if err != nil {
+		return repositories.PageRequest{}, err
+	}
+	return repositories.PageRequest{
+		After:     after,
+		Limit:     limit,
+		WithTotal: c.Query("count") == "true",
+	}, nil
+}
+
+// pageResponse renders a keyset page under key, with next_cursor and the
+// optional total.
+func pageResponse(key string, items interface{}, page repositories.PageRequest, info repositories.PageInfo) gin.H {
+	response := gin.H{
+		key:           items,
+		"next_cursor": info.NextCursor,
+		"limit":       page.Limit,
+	}
+	if info.Total != nil {
+		response["total"] = *info.Total
+	}
+	return response
+}
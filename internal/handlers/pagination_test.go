@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePageRequest(t *testing.T) {
+	cursor := repositories.Cursor{Sort: "updated:desc", Value: "2025-03-01T00:00:00Z", ID: 4}
+
+	page, err := parsePageRequest(testContext("/mangas", 0), 20)
+	require.NoError(t, err)
+	assert.Equal(t, repositories.PageRequest{Limit: 20}, page)
+
+	page, err = parsePageRequest(testContext("/mangas?limit=5&count=true&after="+cursor.Encode(), 0), 20)
+	require.NoError(t, err)
+	assert.Equal(t, repositories.PageRequest{After: &cursor, Limit: 5, WithTotal: true}, page)
+
+	for _, query := range []string{"limit=0", "limit=101", "limit=ten", "after=garbage"} {
+		_, err := parsePageRequest(testContext("/mangas?"+query, 0), 20)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestWantsCursorPage(t *testing.T) {
+	assert.False(t, wantsCursorPage(testContext("/users/favorites", 0)))
+	assert.True(t, wantsCursorPage(testContext("/users/favorites?limit=10", 0)))
+	assert.True(t, wantsCursorPage(testContext("/users/favorites?after=", 0)))
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sidler1/manga-backend/internal/services"
+)
+
+// GetPushPublicKey handles the request for the VAPID public key browsers subscribe with
+func GetPushPublicKey(s services.PushService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, err := s.PublicKey()
+		if err != nil {
+			respondPushError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"public_key": key})
+	}
+}
+
+// RegisterPushSubscription handles the request to receive Web Push notifications on a device
+func RegisterPushSubscription(s services.PushService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input services.PushSubscriptionInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		subscription, err := s.Register(c.GetUint("userID"), input)
+		if err != nil {
+			respondPushError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, subscription)
+	}
+}
+
+// UnregisterPushSubscription handles the request to stop Web Push notifications on a device
+func UnregisterPushSubscription(s services.PushService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input services.PushSubscriptionInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := s.Unregister(c.GetUint("userID"), input.Endpoint); err != nil {
+			respondPushError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "push subscription deleted"})
+	}
+}
+
+func respondPushError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrPushNotConfigured) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	respondChannelError(c, err)
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sidler1/manga-backend/internal/services"
+	"gorm.io/gorm"
+)
+
+// GetTags handles the request to list all tags with their usage counts,
+// optionally restricted to ?category=
+func GetTags(s services.TagService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tags, err := s.ListTags(c.Query("category"))
+		if err != nil {
+			respondTagError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, tags)
+	}
+}
+
+func CreateTag(s services.TagService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Name          string `json:"name" binding:"required"`
+			Category      string `json:"category"`
+			ContentRating string `json:"content_rating"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		tag, err := s.CreateTag(req.Name, req.Category, req.ContentRating)
+		if err != nil {
+			respondTagError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, tag)
+	}
+}
+
+func UpdateTag(s services.TagService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid tag id")
+		if !ok {
+			return
+		}
+		var req struct {
+			Name          *string `json:"name"`
+			Category      *string `json:"category"`
+			ContentRating *string `json:"content_rating"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		tag, err := s.UpdateTag(id, services.TagUpdate{
+			Name:          req.Name,
+			Category:      req.Category,
+			ContentRating: req.ContentRating,
+		})
+		if err != nil {
+			respondTagError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, tag)
+	}
+}
+
+func DeleteTag(s services.TagService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid tag id")
+		if !ok {
+			return
+		}
+		if err := s.DeleteTag(id); err != nil {
+			respondTagError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "tag deleted"})
+	}
+}
+
+func GetTagAliases(s services.TagService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid tag id")
+		if !ok {
+			return
+		}
+		aliases, err := s.GetAliases(id)
+		if err != nil {
+			respondTagError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, aliases)
+	}
+}
+
+func AddTagAlias(s services.TagService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid tag id")
+		if !ok {
+			return
+		}
+		var req struct {
+			Alias string `json:"alias" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		alias, err := s.AddAlias(id, req.Alias)
+		if err != nil {
+			respondTagError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, alias)
+	}
+}
+
+func DeleteTagAlias(s services.TagService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "alias_id", "invalid alias id")
+		if !ok {
+			return
+		}
+		if err := s.RemoveAlias(id); err != nil {
+			respondTagError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "alias deleted"})
+	}
+}
+
+// MergeTags handles the request to fold source tags into the tag given by id
+func MergeTags(s services.TagService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid tag id")
+		if !ok {
+			return
+		}
+		var req struct {
+			SourceIDs []uint `json:"source_ids" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := s.MergeTags(id, req.SourceIDs); err != nil {
+			respondTagError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "tags merged"})
+	}
+}
+
+func respondTagError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "tag not found"})
+	case errors.Is(err, services.ErrTagConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidTag), errors.Is(err, services.ErrInvalidMerge):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// parseIDParam parses a numeric path parameter, answering 400 with message
+// when it is invalid.
+func parseIDParam(c *gin.Context, name, message string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return 0, false
+	}
+	return uint(id), true
+}
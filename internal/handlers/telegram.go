@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sidler1/manga-backend/internal/services"
+	"github.com/sidler1/manga-backend/notifier"
+)
+
+// CreateTelegramLink handles the request for a one-time code linking a Telegram chat to the current user
+func CreateTelegramLink(s services.TelegramService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		link, err := s.CreateLink(c.GetUint("userID"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, link)
+	}
+}
+
+// TelegramWebhook handles updates pushed by Telegram in webhook mode,
+// authenticated by the secret token set with the webhook
+func TelegramWebhook(s services.TelegramService, secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader("X-Telegram-Bot-Api-Secret-Token")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid secret token"})
+			return
+		}
+		var update notifier.TelegramUpdate
+		if err := c.ShouldBindJSON(&update); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		s.HandleUpdate(update)
+		c.Status(http.StatusOK)
+	}
+}
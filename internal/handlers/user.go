@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sidler1/manga-backend/internal/models"
 	"github.com/sidler1/manga-backend/internal/repositories"
 	"github.com/sidler1/manga-backend/internal/services"
 )
@@ -15,7 +17,47 @@ func GetUserFavorites(s services.MangaService) gin.HandlerFunc {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 			return
 		}
-		favorites, err := s.GetUserFavorites(userID)
+		state := c.Query("state")
+		if state != "" && !services.IsValidReleaseState(state) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid state parameter"})
+			return
+		}
+		if wantsCursorPage(c) {
+			page, err := parsePageRequest(c, 20)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			include, err := parseIncludes(c)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			summaries, info, err := s.GetUserFavoritesPage(userID, state, page)
+			if errors.Is(err, repositories.ErrInvalidCursor) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			favorites, err := expandSummaries(s, summaries, include)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusOK, pageResponse("favorites", favorites, page, info))
+			return
+		}
+
+		var favorites []models.Manga
+		var err error
+		if state != "" {
+			favorites, err = s.GetUserFavoritesByReleaseState(userID, state)
+		} else {
+			favorites, err = s.GetUserFavorites(userID)
+		}
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -31,7 +73,31 @@ func GetNotifications(s services.NotificationService) gin.HandlerFunc {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 			return
 		}
-		notifications, err := s.GetNotifications(userID)
+		filter, err := parseNotificationFilter(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if wantsCursorPage(c) {
+			page, err := parsePageRequest(c, 20)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			notifications, info, err := s.GetNotificationsPage(userID, filter, page)
+			if errors.Is(err, repositories.ErrInvalidCursor) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusOK, pageResponse("notifications", notifications, page, info))
+			return
+		}
+
+		notifications, err := s.GetNotifications(userID, filter)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -40,10 +106,10 @@ func GetNotifications(s services.NotificationService) gin.HandlerFunc {
 	}
 }
 
-// Placeholder for auth middleware extraction
+// getUserIDFromContext returns the user ID set by the auth middleware, or 0
+// for unauthenticated requests.
 func getUserIDFromContext(c *gin.Context) uint {
-	// Implement with JWT or similar; return user ID from c.Get("userID")
-	return 1 // Dummy
+	return c.GetUint("userID")
 }
 
 func GetCurrentUser(userRepo repositories.UserRepository) gin.HandlerFunc {
@@ -55,10 +121,40 @@ func GetCurrentUser(userRepo repositories.UserRepository) gin.HandlerFunc {
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{
-			"id":       user.ID,
-			"username": user.Username,
-			"email":    user.Email,
+			"id":            user.ID,
+			"username":      user.Username,
+			"email":         user.Email,
+			"adult_content": user.AdultContent,
 			// Omit password and other sensitive fields
 		})
 	}
 }
+
+// UpdateUserSettings handles the request to change the caller's account
+// settings. adult_content is one of hide, blur or show.
+func UpdateUserSettings(userRepo repositories.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			AdultContent string `json:"adult_content" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !services.IsValidAdultContentMode(req.AdultContent) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid adult_content: expected hide, blur or show"})
+			return
+		}
+		user, err := userRepo.FindByID(c.GetUint("userID"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch user"})
+			return
+		}
+		user.AdultContent = req.AdultContent
+		if err := userRepo.Update(user); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update settings"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"adult_content": user.AdultContent})
+	}
+}
@@ -1,37 +1,40 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
-	_ "strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/sidler1/manga-backend/internal/repositories"
 	"github.com/sidler1/manga-backend/internal/services"
+	"github.com/sidler1/manga-backend/scraper"
+	"gorm.io/gorm"
 )
 
-func AddWebsite(s services.MangaService) gin.HandlerFunc {
+// WebsiteRequest is the body of a request to add a website.
+type WebsiteRequest struct {
+	URL  string `json:"url" binding:"required"`
+	Name string `json:"name"`
+}
+
+func AddWebsite(s services.WebsiteService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var req struct {
-			URL  string `json:"url"`
-			Name string `json:"name"`
-		}
-		if err := c.BindJSON(&req); err != nil {
+		var req WebsiteRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		// Assume admin check middleware
-		err := s.AddWebsite(req.URL, req.Name)
+		website, err := s.AddWebsite(req.URL, req.Name)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			respondWebsiteError(c, err)
 			return
 		}
-		c.JSON(http.StatusCreated, gin.H{"message": "website added"})
+		c.JSON(http.StatusCreated, website)
 	}
 }
 
-func GetWebsites(repo repositories.WebsiteRepository) gin.HandlerFunc {
+func GetWebsites(s services.WebsiteService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		websites, err := repo.FindAll()
+		websites, err := s.ListWebsites()
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -39,3 +42,166 @@ func GetWebsites(repo repositories.WebsiteRepository) gin.HandlerFunc {
 		c.JSON(http.StatusOK, websites)
 	}
 }
+
+// UpdateWebsite handles the request to rename, enable or disable a website or
+// change its check schedule
+func UpdateWebsite(s services.WebsiteService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid website id")
+		if !ok {
+			return
+		}
+		var input services.WebsiteInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		website, err := s.UpdateWebsite(id, input)
+		if err != nil {
+			respondWebsiteError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, website)
+	}
+}
+
+func DeleteWebsite(s services.WebsiteService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid website id")
+		if !ok {
+			return
+		}
+		if err := s.DeleteWebsite(id); err != nil {
+			respondWebsiteError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "website deleted"})
+	}
+}
+
+// MoveWebsite handles the admin request to move a website to a new base URL,
+// rewriting stored manga and chapter URLs
+func MoveWebsite(s services.WebsiteService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid website id")
+		if !ok {
+			return
+		}
+		var req struct {
+			URL string `json:"url" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		move, err := s.MoveWebsite(id, req.URL)
+		if err != nil {
+			respondWebsiteError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, move)
+	}
+}
+
+func AddWebsiteAlias(s services.WebsiteService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid website id")
+		if !ok {
+			return
+		}
+		var req struct {
+			URL string `json:"url" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		alias, err := s.AddAlias(id, req.URL)
+		if err != nil {
+			respondWebsiteError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, alias)
+	}
+}
+
+func DeleteWebsiteAlias(s services.WebsiteService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "alias_id", "invalid alias id")
+		if !ok {
+			return
+		}
+		if err := s.RemoveAlias(id); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "alias not found"})
+				return
+			}
+			respondWebsiteError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "alias deleted"})
+	}
+}
+
+// ScrapeWebsite handles the admin request to check a website right away
+func ScrapeWebsite(s services.ScraperService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id", "invalid website id")
+		if !ok {
+			return
+		}
+		report, err := s.ScrapeNow(id)
+		if err != nil {
+			respondWebsiteError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+// ScraperTestRequest is the body of a scraper dry run. Method is latest,
+// details or chapters; Slug overrides the one taken from URL.
+type ScraperTestRequest struct {
+	URL    string `json:"url" binding:"required"`
+	Method string `json:"method" binding:"required"`
+	Slug   string `json:"slug"`
+}
+
+// TestScraper handles the admin request to dry-run a scraper method
+func TestScraper(s services.ScraperService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ScraperTestRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		result, err := s.TestScraper(req.URL, req.Method, req.Slug)
+		if errors.Is(err, scraper.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, scraper.ErrScrapeFailed) {
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			respondWebsiteError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+func respondWebsiteError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "website not found"})
+	case errors.Is(err, services.ErrNoScraper), errors.Is(err, services.ErrInvalidWebsite),
+		errors.Is(err, services.ErrInvalidScraperTest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrScrapeInProgress):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
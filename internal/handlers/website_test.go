@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sidler1/manga-backend/internal/services"
+	"github.com/sidler1/manga-backend/scraper"
+	"github.com/stretchr/testify/assert"
+)
+
+type dryRunService struct {
+	services.ScraperService
+	err error
+}
+
+func (s dryRunService) TestScraper(url, method, slug string) (*services.ScraperTestResult, error) {
+	return nil, s.err
+}
+
+func TestTestScraper_Errors(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{fmt.Errorf("details %q: %w", "gone", scraper.ErrNotFound), http.StatusNotFound},
+		{fmt.Errorf("%w: https://unknown.example/", services.ErrNoScraper), http.StatusBadRequest},
+		{scraper.ErrScrapeFailed, http.StatusBadGateway},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/admin/scrapers/test",
+				strings.NewReader(`{"url": "https://www.mangaread.net/manga/gone/", "method": "details"}`))
+			c.Request.Header.Set("Content-Type", "application/json")
+			TestScraper(dryRunService{err: tt.err})(c)
+			assert.Equal(t, tt.code, w.Code)
+		})
+	}
+}
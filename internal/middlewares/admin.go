@@ -1,16 +1,29 @@
 package middlewares
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
+	"github.com/sidler1/manga-backend/internal/models"
 	"github.com/sidler1/manga-backend/internal/repositories"
 )
 
-func AdminMiddleware(userRepo repositories.UserRepository, jwtSecret string) gin.HandlerFunc {
+// AdminMiddleware only lets users with the admin role through. It must run
+// after AuthMiddleware, which validates the token and sets userID.
+func AdminMiddleware(userRepo repositories.UserRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Implement JWT token validation using jwtSecret
-		// Extract user ID from the token
-		// Check if the user is an admin
-		// If not an admin, return Forbidden status
-		// Otherwise, call c.Next()
+		userID := c.GetUint("userID")
+		if userID == 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			c.Abort()
+			return
+		}
+		user, err := userRepo.FindByID(userID)
+		if err != nil || user.Role != models.RoleAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+			c.Abort()
+			return
+		}
+		c.Next()
 	}
 }
@@ -8,32 +8,139 @@ import (
 
 type Website struct {
 	gorm.Model
-	URL         string `gorm:"uniqueIndex"`
-	Name        string
-	LastChecked time.Time
+	URL           string `gorm:"uniqueIndex"`
+	Name          string
+	LastChecked   time.Time
+	Enabled       bool `gorm:"default:true"`
+	CheckInterval int  `gorm:"default:60"` // Minutes between update checks
+	ActiveFrom    int  // Hour of day (UTC) from which the site is checked
+	ActiveUntil   int  // Hour of day (UTC) until which the site is checked; equal to ActiveFrom means all day
+	Aliases       []WebsiteAlias
+}
+
+// WebsiteAlias is another base URL of a website, such as its domain before a
+// move or a mirror.
+type WebsiteAlias struct {
+	gorm.Model
+	WebsiteID uint   `gorm:"index"`
+	URL       string `gorm:"uniqueIndex"`
 }
 
 type Manga struct {
 	gorm.Model
 	Title         string
+	AltTitles     string // Alternative titles joined with "; "
 	Slug          string
 	Description   string
 	WebsiteID     uint
 	Website       Website
 	Tags          []Tag `gorm:"many2many:manga_tags;"`
 	Chapters      []Chapter
+	Credits       []MangaCredit
 	LastChapter   string
 	UpdateTime    time.Time
 	EstimatedNext time.Time
 	ExternalURL   string
-	Author        string
+	CoverURL      string
+	Author        string // Credited authors as scraped, joined with ", "
+	Status        string // Publication status as scraped from the source, e.g. "OnGoing"
+	ReleaseState  string `gorm:"index;default:ongoing"`
+	StateSince    time.Time
+	ContentRating string `gorm:"index"` // Derived from the ratings of its tags by a database trigger
+	LockedFields  string // Comma-separated metadata fields that scrapes must not overwrite
+	// Last time the metadata was re-scraped from the source
+	MetadataRefreshedAt time.Time `gorm:"index"`
+	// Source availability: consecutive checks that found the manga or its
+	// latest chapter gone, and when it was declared removed from the source
+	SourceMisses        int
+	SourceCheckedAt     time.Time  `gorm:"index"`
+	RemovedFromSourceAt *time.Time `gorm:"index"`
+}
+
+// MangaMetadataChange records a metadata difference found by a refresh from
+// the source. Changes to locked fields are recorded but not applied.
+type MangaMetadataChange struct {
+	gorm.Model
+	MangaID   uint `gorm:"index"`
+	Field     string
+	OldValue  string
+	NewValue  string
+	Applied   bool
+	ChangedAt time.Time
+}
+
+// Release states assigned by the periodic release analysis.
+const (
+	ReleaseStateOngoing   = "ongoing"
+	ReleaseStateHiatus    = "hiatus"
+	ReleaseStateCompleted = "completed"
+	ReleaseStateDropped   = "dropped"
+)
+
+// MangaStateTransition records a change of a manga's ReleaseState.
+type MangaStateTransition struct {
+	gorm.Model
+	MangaID        uint `gorm:"index"`
+	FromState      string
+	ToState        string
+	Reason         string
+	TransitionedAt time.Time
 }
 
 type Tag struct {
 	gorm.Model
-	Name string `gorm:"unique"`
+	Name          string `gorm:"unique"`
+	Key           string `gorm:"index"` // Normalized name used to match scraped genre text
+	Category      string `gorm:"index"`
+	ContentRating string // Rating implied for mangas carrying the tag; empty for none
+}
+
+// Tag categories.
+const (
+	TagCategoryGenre          = "genre"
+	TagCategoryTheme          = "theme"
+	TagCategoryDemographic    = "demographic"
+	TagCategoryFormat         = "format"
+	TagCategoryContentWarning = "content_warning"
+)
+
+// Content ratings, from least to most restricted. A manga is rated by the most
+// restrictive rating among its tags.
+const (
+	ContentRatingSafe       = "safe"
+	ContentRatingSuggestive = "suggestive"
+	ContentRatingAdult      = "adult"
+)
+
+// TagAlias maps source text such as "Science Fiction" onto a canonical tag.
+type TagAlias struct {
+	gorm.Model
+	TagID uint `gorm:"index"`
+	Alias string
+	Key   string `gorm:"uniqueIndex"`
+}
+
+// Creator is an author or artist credited on mangas.
+type Creator struct {
+	gorm.Model
+	Name string
+	Key  string `gorm:"uniqueIndex"` // Lowercased name with collapsed whitespace
 }
 
+// MangaCredit credits a creator on a manga in a role.
+type MangaCredit struct {
+	MangaID   uint   `gorm:"primaryKey"`
+	CreatorID uint   `gorm:"primaryKey;index"`
+	Role      string `gorm:"primaryKey"`
+	Creator   Creator
+}
+
+// Credit roles.
+const (
+	CreditRoleAuthor = "author"
+	CreditRoleArtist = "artist"
+)
+
 type Chapter struct {
 	gorm.Model
 	MangaID     uint
@@ -49,10 +156,50 @@ type User struct {
 	Email     string
 	Password  string  // Hashed
 	Favorites []Manga `gorm:"many2many:user_favorites;"`
-	Bookmarks []Bookmark
-	// Role string // For admin, add later if needed
+	// FollowedCreators are notified about when new works crediting them are found
+	FollowedCreators []Creator `gorm:"many2many:user_followed_creators;" json:"-"`
+	Bookmarks        []Bookmark
+	// CalendarToken authenticates the user's iCalendar feed URL
+	CalendarToken string `gorm:"index" json:"-"`
+	// AdultContent is how mangas rated adult are shown: hide, blur or show
+	AdultContent string `gorm:"default:hide"`
+	Role         string `gorm:"default:user"`
+	// Notifications are delivered instantly or batched into digests: hourly,
+	// daily at DigestTime or weekly on DigestWeekday, in the user's Timezone
+	DigestMode    string `gorm:"default:instant"`
+	DigestTime    string `gorm:"default:08:00"` // HH:MM
+	DigestWeekday int    `gorm:"default:1"`     // 0 is Sunday
+	Timezone      string `gorm:"default:UTC"`   // IANA name, e.g. "Europe/Berlin"
+	// NotificationsPaused stops deliveries over all channels; notifications
+	// are still kept
+	NotificationsPaused bool
+	// Deliveries falling between QuietHoursStart and QuietHoursEnd (HH:MM in
+	// the user's Timezone, possibly spanning midnight) wait until the end
+	QuietHoursStart string
+	QuietHoursEnd   string
 }
 
+// User roles.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Adult content preferences of a user.
+const (
+	AdultContentHide = "hide"
+	AdultContentBlur = "blur"
+	AdultContentShow = "show"
+)
+
+// Digest modes.
+const (
+	DigestModeInstant = "instant" // Every notification is delivered on its own
+	DigestModeHourly  = "hourly"
+	DigestModeDaily   = "daily"
+	DigestModeWeekly  = "weekly"
+)
+
 type Bookmark struct {
 	gorm.Model
 	UserID  uint
@@ -60,11 +207,120 @@ type Bookmark struct {
 	Chapter uint
 }
 
+// AuditLog records an administrative change to an entity.
+type AuditLog struct {
+	gorm.Model
+	ActorID    uint   `gorm:"index"`
+	EntityType string `gorm:"index:idx_audit_logs_entity"`
+	EntityID   uint   `gorm:"index:idx_audit_logs_entity"`
+	Action     string
+	Changes    string `gorm:"type:jsonb"` // {"field": {"from": ..., "to": ...}}
+}
+
+// Audited actions.
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+)
+
 type Notification struct {
 	gorm.Model
-	UserID  uint
-	MangaID uint
-	Message string
-	SentAt  time.Time
-	Read    bool // Add to track read status
+	UserID   uint
+	MangaID  uint
+	Type     string `gorm:"index"` // What the notification is about, e.g. "chapter"
+	Message  string
+	URL      string // Where to read about it, e.g. the manga's source page
+	Chapter  string // Chapter label of chapter notifications, e.g. "Chapter 12"
+	Priority int    // How much it matters, from 1 (min) to 5 (max)
+	SentAt   time.Time
+	Read     bool       `gorm:"index"`
+	ReadAt   *time.Time // When it was last marked read; old read notifications are purged
+	Archived bool       `gorm:"not null;default:false"` // Hidden from the inbox unless archived ones are asked for
+}
+
+// Notification types.
+const (
+	NotificationTypeChapter = "chapter"  // New chapter of a favorite
+	NotificationTypeNewWork = "new_work" // New manga by a followed creator
+	NotificationTypeRemoved = "removed"  // Favorite removed from its source
+	NotificationTypeState   = "state"    // Release state of a favorite changed
+)
+
+// ChannelSubscription is a user's opt-in to receive notifications over a
+// delivery channel such as email or a webhook.
+type ChannelSubscription struct {
+	gorm.Model
+	UserID  uint   `gorm:"index"` // 0 for admin webhooks, which get events about every manga
+	Channel string `gorm:"index"`
+	Target  string // Channel-specific address, e.g. an email address or webhook URL
+	Config  string // Channel-specific JSON settings
+	Enabled bool   `gorm:"default:true"`
+}
+
+// ChannelMute stops notifications about one manga over one channel
+// subscription, as set by the per-manga unsubscribe link.
+type ChannelMute struct {
+	ID             uint `gorm:"primaryKey"`
+	SubscriptionID uint `gorm:"uniqueIndex:idx_channel_mute"`
+	MangaID        uint `gorm:"uniqueIndex:idx_channel_mute"`
+	CreatedAt      time.Time
+}
+
+// FavoritePreference tunes the notifications about one of a user's
+// favorites. Favorites without one notify about everything.
+type FavoritePreference struct {
+	ID      uint `gorm:"primaryKey"`
+	UserID  uint `gorm:"uniqueIndex:idx_favorite_preference"`
+	MangaID uint `gorm:"uniqueIndex:idx_favorite_preference"`
+	Muted   bool // No notifications about the manga at all
+	// EveryChapters notifies about every Nth new chapter only; 0 and 1 mean
+	// every chapter
+	EveryChapters int
+	// CompletionOnly notifies only when the series is completed
+	CompletionOnly bool
+	// SkippedChapters counts the new chapters since the last one notified
+	SkippedChapters int `json:"-"`
+	UpdatedAt       time.Time
+}
+
+// TelegramLinkCode is a one-time code linking the Telegram chat it is sent
+// from to the account that requested it.
+type TelegramLinkCode struct {
+	Code      string `gorm:"primaryKey"`
+	UserID    uint
+	ExpiresAt time.Time
 }
+
+// ServerKey is a key the server generated for itself, such as the VAPID key
+// of Web Push, kept so it stays the same across restarts.
+type ServerKey struct {
+	Name      string `gorm:"primaryKey"`
+	Value     string // Encoded private key
+	CreatedAt time.Time
+}
+
+// NotificationDelivery tracks sending one notification over one channel
+// subscription, including retries.
+type NotificationDelivery struct {
+	gorm.Model
+	NotificationID uint `gorm:"index"`
+	Notification   Notification
+	SubscriptionID uint `gorm:"index"`
+	Subscription   ChannelSubscription
+	Channel        string
+	Status         string `gorm:"index"`
+	Attempts       int
+	LastError      string
+	NextAttemptAt  time.Time `gorm:"index"`
+	SentAt         *time.Time
+}
+
+// Delivery statuses.
+const (
+	DeliveryStatusPending = "pending" // Waiting for its first or next attempt
+	DeliveryStatusHeld    = "held"    // Waiting for the user's next digest
+	DeliveryStatusSent    = "sent"
+	DeliveryStatusFailed  = "failed" // Gave up after too many or permanent errors
+)
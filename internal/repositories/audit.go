@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"github.com/sidler1/manga-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// AuditFilter narrows the audit log; zero values match everything.
+type AuditFilter struct {
+	EntityType string
+	EntityID   uint
+	ActorID    uint
+	Limit      int
+}
+
+type AuditRepository interface {
+	Create(entry *models.AuditLog) error
+	Find(filter AuditFilter) ([]models.AuditLog, error)
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Create(entry *models.AuditLog) error {
+	return r.db.Create(entry).Error
+}
+
+// Find returns the most recent matching entries first.
+func (r *auditRepository) Find(filter AuditFilter) ([]models.AuditLog, error) {
+	entries := []models.AuditLog{}
+	query := r.db.Model(&models.AuditLog{})
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != 0 {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	err := query.Order("created_at DESC, id DESC").Limit(filter.Limit).Find(&entries).Error
+	return entries, err
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"github.com/sidler1/manga-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ChannelRepository interface {
+	Create(subscription *models.ChannelSubscription) error
+	FindByID(id uint) (*models.ChannelSubscription, error)
+	FindByUserID(userID uint) ([]models.ChannelSubscription, error)
+	FindByTarget(userID uint, channel, target string) (*models.ChannelSubscription, error)
+	FindByChannelTarget(channel, target string) (*models.ChannelSubscription, error)
+	FindEnabledForManga(userID, mangaID uint) ([]models.ChannelSubscription, error)
+	Update(subscription *models.ChannelSubscription) error
+	Delete(id uint) error
+	Mute(subscriptionID, mangaID uint) error
+}
+
+type channelRepository struct {
+	db *gorm.DB
+}
+
+func NewChannelRepository(db *gorm.DB) ChannelRepository {
+	return &channelRepository{db: db}
+}
+
+func (r *channelRepository) Create(subscription *models.ChannelSubscription) error {
+	return r.db.Create(subscription).Error
+}
+
+func (r *channelRepository) FindByID(id uint) (*models.ChannelSubscription, error) {
+	var subscription models.ChannelSubscription
+	err := r.db.First(&subscription, id).Error
+	return &subscription, err
+}
+
+func (r *channelRepository) FindByUserID(userID uint) ([]models.ChannelSubscription, error) {
+	var subscriptions []models.ChannelSubscription
+	err := r.db.Where("user_id = ?", userID).Order("id ASC").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *channelRepository) FindByTarget(userID uint, channel, target string) (*models.ChannelSubscription, error) {
+	var subscription models.ChannelSubscription
+	err := r.db.Where("user_id = ? AND channel = ? AND target = ?", userID, channel, target).First(&subscription).Error
+	return &subscription, err
+}
+
+// FindByChannelTarget returns the subscription of any user delivering to
+// target, e.g. the account a Telegram chat is linked to.
+func (r *channelRepository) FindByChannelTarget(channel, target string) (*models.ChannelSubscription, error) {
+	var subscription models.ChannelSubscription
+	err := r.db.Where("channel = ? AND target = ?", channel, target).First(&subscription).Error
+	return &subscription, err
+}
+
+// FindEnabledForManga returns the user's enabled subscriptions that have not
+// muted the manga.
+func (r *channelRepository) FindEnabledForManga(userID, mangaID uint) ([]models.ChannelSubscription, error) {
+	var subscriptions []models.ChannelSubscription
+	err := r.db.Where("user_id = ? AND enabled", userID).
+		Where("id NOT IN (SELECT subscription_id FROM channel_mutes WHERE manga_id = ?)", mangaID).
+		Order("id ASC").
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *channelRepository) Update(subscription *models.ChannelSubscription) error {
+	return r.db.Save(subscription).Error
+}
+
+func (r *channelRepository) Delete(id uint) error {
+	return r.db.Delete(&models.ChannelSubscription{}, id).Error
+}
+
+// Mute stops notifications about the manga over the subscription; muting
+// twice is a no-op.
+func (r *channelRepository) Mute(subscriptionID, mangaID uint) error {
+	mute := models.ChannelMute{SubscriptionID: subscriptionID, MangaID: mangaID}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&mute).Error
+}
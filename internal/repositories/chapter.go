@@ -1,6 +1,9 @@
 package repositories
 
 import (
+	"strconv"
+	"time"
+
 	"github.com/sidler1/manga-backend/internal/models"
 
 	"gorm.io/gorm"
@@ -8,7 +11,13 @@ import (
 
 type ChapterRepository interface {
 	Create(chapter *models.Chapter) error
+	Update(chapter *models.Chapter) error
+	Delete(id uint) error
+	Restore(id uint) error
+	FindByIDWithDeleted(id uint) (*models.Chapter, error)
 	FindByMangaID(mangaID uint) ([]models.Chapter, error)
+	FindReleasedBetween(mangaIDs []uint, from, to time.Time) ([]models.Chapter, error)
+	FindPageByMangaID(mangaID uint, page PageRequest) ([]models.Chapter, PageInfo, error)
 }
 
 type chapterRepository struct {
@@ -23,8 +32,79 @@ func (r *chapterRepository) Create(chapter *models.Chapter) error {
 	return r.db.Create(chapter).Error
 }
 
+func (r *chapterRepository) Update(chapter *models.Chapter) error {
+	return r.db.Save(chapter).Error
+}
+
+// Delete soft-deletes a chapter; it can be brought back with Restore.
+func (r *chapterRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Chapter{}, id).Error
+}
+
+func (r *chapterRepository) Restore(id uint) error {
+	result := r.db.Unscoped().Model(&models.Chapter{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+// FindByIDWithDeleted loads a chapter, including soft-deleted ones.
+func (r *chapterRepository) FindByIDWithDeleted(id uint) (*models.Chapter, error) {
+	var chapter models.Chapter
+	err := r.db.Unscoped().First(&chapter, id).Error
+	return &chapter, err
+}
+
 func (r *chapterRepository) FindByMangaID(mangaID uint) ([]models.Chapter, error) {
 	var chapters []models.Chapter
 	err := r.db.Where("manga_id = ?", mangaID).Order("number ASC").Find(&chapters).Error
 	return chapters, err
 }
+
+func (r *chapterRepository) FindReleasedBetween(mangaIDs []uint, from, to time.Time) ([]models.Chapter, error) {
+	var chapters []models.Chapter
+	if len(mangaIDs) == 0 {
+		return chapters, nil
+	}
+	err := r.db.Where("manga_id IN ? AND release_date >= ? AND release_date < ?", mangaIDs, from, to).
+		Order("release_date ASC").
+		Find(&chapters).Error
+	return chapters, err
+}
+
+// FindPageByMangaID returns one keyset page of a manga's chapters, latest first.
+func (r *chapterRepository) FindPageByMangaID(mangaID uint, page PageRequest) ([]models.Chapter, PageInfo, error) {
+	var afterValue interface{}
+	if page.After != nil {
+		if page.After.Sort != "chapters" {
+			return nil, PageInfo{}, ErrInvalidCursor
+		}
+		var err error
+		if afterValue, err = parseCursorInt(page.After); err != nil {
+			return nil, PageInfo{}, err
+		}
+	}
+
+	query := r.db.Model(&models.Chapter{}).Where("manga_id = ?", mangaID)
+	total, err := countIf(query, page)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+
+	var chapters []models.Chapter
+	err = keyset(query, "number", "id", true, page.After, afterValue, page.Limit).Find(&chapters).Error
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+
+	info := PageInfo{Total: total}
+	if len(chapters) > page.Limit {
+		chapters = chapters[:page.Limit]
+		last := chapters[len(chapters)-1]
+		info.NextCursor = Cursor{Sort: "chapters", Value: strconv.FormatUint(uint64(last.Number), 10), ID: last.ID}.Encode()
+	}
+	return chapters, info, nil
+}
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"strings"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// CreatorWork is a manga credited to a creator, with the creator's roles on it.
+type CreatorWork struct {
+	MangaSummary
+	Roles []string `json:"roles"`
+}
+
+type CreatorRepository interface {
+	FindOrCreate(name string) (*models.Creator, error)
+	FindByID(id uint) (*models.Creator, error)
+	Credit(mangaID, creatorID uint, role string) error
+	FindWorks(creatorID uint, hideAdult bool) ([]CreatorWork, error)
+	Follow(userID, creatorID uint) error
+	Unfollow(userID, creatorID uint) error
+	FindFollowed(userID uint) ([]models.Creator, error)
+}
+
+type creatorRepository struct {
+	db *gorm.DB
+}
+
+func NewCreatorRepository(db *gorm.DB) CreatorRepository {
+	return &creatorRepository{db: db}
+}
+
+// CreatorKey normalizes a creator name for matching: lowercase with runs of
+// whitespace collapsed, so "Chugong" and " CHUGONG " are the same creator.
+func CreatorKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+func (r *creatorRepository) FindOrCreate(name string) (*models.Creator, error) {
+	var creator models.Creator
+	name = strings.Join(strings.Fields(name), " ")
+	key := CreatorKey(name)
+	err := r.db.Where("key = ?", key).
+		Attrs(models.Creator{Name: name, Key: key}).
+		FirstOrCreate(&creator).Error
+	return &creator, err
+}
+
+func (r *creatorRepository) FindByID(id uint) (*models.Creator, error) {
+	var creator models.Creator
+	err := r.db.First(&creator, id).Error
+	return &creator, err
+}
+
+func (r *creatorRepository) Credit(mangaID, creatorID uint, role string) error {
+	return r.db.Exec("INSERT INTO manga_credits (manga_id, creator_id, role) VALUES (?, ?, ?) ON CONFLICT DO NOTHING",
+		mangaID, creatorID, role).Error
+}
+
+// FindWorks lists the mangas crediting a creator, most recently updated first.
+func (r *creatorRepository) FindWorks(creatorID uint, hideAdult bool) ([]CreatorWork, error) {
+	query := r.db.Model(&models.Manga{}).
+		Where("mangas.id IN (SELECT manga_id FROM manga_credits WHERE creator_id = ?)", creatorID)
+	if hideAdult {
+		query = withoutAdult(query)
+	}
+	summaries, err := findSummaries(query.Order("mangas.update_time DESC, mangas.id ASC"))
+	if err != nil {
+		return nil, err
+	}
+
+	var credits []models.MangaCredit
+	if err := r.db.Where("creator_id = ?", creatorID).Order("role ASC").Find(&credits).Error; err != nil {
+		return nil, err
+	}
+	roles := make(map[uint][]string, len(summaries))
+	for _, credit := range credits {
+		roles[credit.MangaID] = append(roles[credit.MangaID], credit.Role)
+	}
+
+	works := make([]CreatorWork, len(summaries))
+	for i, summary := range summaries {
+		works[i] = CreatorWork{MangaSummary: summary, Roles: roles[summary.ID]}
+	}
+	return works, nil
+}
+
+func (r *creatorRepository) Follow(userID, creatorID uint) error {
+	return r.db.Exec("INSERT INTO user_followed_creators (user_id, creator_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
+		userID, creatorID).Error
+}
+
+func (r *creatorRepository) Unfollow(userID, creatorID uint) error {
+	return r.db.Exec("DELETE FROM user_followed_creators WHERE user_id = ? AND creator_id = ?", userID, creatorID).Error
+}
+
+func (r *creatorRepository) FindFollowed(userID uint) ([]models.Creator, error) {
+	creators := []models.Creator{}
+	err := r.db.Joins("JOIN user_followed_creators ON user_followed_creators.creator_id = creators.id").
+		Where("user_followed_creators.user_id = ?", userID).
+		Order("creators.name ASC").
+		Find(&creators).Error
+	return creators, err
+}
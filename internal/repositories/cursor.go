@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidCursor is returned for cursors that cannot be decoded or that were
+// issued for a different ordering.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks the last row of a keyset page: the value of its ordering key and
+// its id. Clients only ever see it as an opaque string.
+type Cursor struct {
+	Sort  string `json:"s,omitempty"`
+	Value string `json:"v,omitempty"`
+	ID    uint   `json:"id"`
+}
+
+// Encode returns the opaque representation of c.
+func (c Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor parses an opaque cursor. An empty string yields a nil cursor.
+func DecodeCursor(s string) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// PageRequest asks for the rows following After. Total is only counted when
+// WithTotal is set, since a COUNT(*) over a large table is expensive.
+type PageRequest struct {
+	After     *Cursor
+	Limit     int
+	WithTotal bool
+}
+
+// PageInfo describes a keyset page. NextCursor is empty on the last page.
+type PageInfo struct {
+	NextCursor string
+	Total      *int64
+}
+
+// keyset orders query by (key, id) in one direction and, when after is set,
+// keeps only the rows strictly past (afterValue, after.ID). One extra row is
+// requested so callers can tell whether another page exists.
+func keyset(query *gorm.DB, key, id string, desc bool, after *Cursor, afterValue interface{}, limit int) *gorm.DB {
+	op, direction := ">", "ASC"
+	if desc {
+		op, direction = "<", "DESC"
+	}
+	if after != nil {
+		query = query.Where("("+key+", "+id+") "+op+" (?, ?)", afterValue, after.ID)
+	}
+	return query.Order(key + " " + direction + ", " + id + " " + direction).Limit(limit + 1)
+}
+
+// countIf counts query when the page request asks for a total.
+func countIf(query *gorm.DB, page PageRequest) (*int64, error) {
+	if !page.WithTotal {
+		return nil, nil
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+	return &total, nil
+}
+
+func formatCursorTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func parseCursorTime(c *Cursor) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, c.Value)
+	if err != nil {
+		return time.Time{}, ErrInvalidCursor
+	}
+	return t, nil
+}
+
+func parseCursorInt(c *Cursor) (int64, error) {
+	n, err := strconv.ParseInt(c.Value, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	return n, nil
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	cursor := Cursor{Sort: "updated:desc", Value: formatCursorTime(time.Date(2025, 3, 1, 12, 0, 0, 5, time.UTC)), ID: 42}
+
+	decoded, err := DecodeCursor(cursor.Encode())
+	require.NoError(t, err)
+
+	assert.Equal(t, &cursor, decoded)
+	value, err := parseCursorTime(decoded)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 1, 12, 0, 0, 5, time.UTC), value)
+}
+
+func TestDecodeCursor(t *testing.T) {
+	empty, err := DecodeCursor("")
+	assert.NoError(t, err)
+	assert.Nil(t, empty)
+
+	for name, raw := range map[string]string{
+		"not base64":  "%%%",
+		"not JSON":    base64.RawURLEncoding.EncodeToString([]byte("nope")),
+		"without id":  base64.RawURLEncoding.EncodeToString([]byte(`{"s":"updated:desc","v":"x"}`)),
+		"padded form": base64.URLEncoding.EncodeToString([]byte(`{"id":1}`)) + "=",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := DecodeCursor(raw)
+			assert.ErrorIs(t, err, ErrInvalidCursor)
+		})
+	}
+}
+
+func TestMangaSort_CursorValue(t *testing.T) {
+	byTitle := MangaSort{Field: SortByTitle}
+	value, err := byTitle.cursorValue(&Cursor{Sort: "title:asc", Value: "Berserk", ID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "Berserk", value)
+
+	// A cursor only continues the ordering it was issued for.
+	_, err = byTitle.cursorValue(&Cursor{Sort: "title:desc", Value: "Berserk", ID: 1})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	byPopularity := MangaSort{Field: SortByPopularity, Desc: true}
+	value, err = byPopularity.cursorValue(&Cursor{Sort: "popularity:desc", Value: "17", ID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(17), value)
+	_, err = byPopularity.cursorValue(&Cursor{Sort: "popularity:desc", Value: "many", ID: 1})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = DefaultMangaSort.cursorValue(&Cursor{Sort: "updated:desc", Value: "yesterday", ID: 1})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestKeyset(t *testing.T) {
+	tests := []struct {
+		name  string
+		desc  bool
+		after *Cursor
+		sql   string
+		vars  []interface{}
+	}{
+		{"first page", true, nil, `ORDER BY sent_at DESC, id DESC LIMIT $1`, []interface{}{21}},
+		{
+			"descending after a cursor", true, &Cursor{ID: 9},
+			`WHERE (sent_at, id) < ($1, $2) ORDER BY sent_at DESC, id DESC LIMIT $3`, []interface{}{"v", uint(9), 21},
+		},
+		{
+			"ascending after a cursor", false, &Cursor{ID: 9},
+			`WHERE (sent_at, id) > ($1, $2) ORDER BY sent_at ASC, id ASC LIMIT $3`, []interface{}{"v", uint(9), 21},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rows []map[string]interface{}
+			query := dryRunDB(t).Table("notifications")
+			stmt := keyset(query, "sent_at", "id", tt.desc, tt.after, "v", 20).Find(&rows).Statement
+			assert.Contains(t, stmt.SQL.String(), tt.sql)
+			assert.Equal(t, tt.vars, stmt.Vars)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DeliveryFilter narrows a delivery listing; zero fields are ignored.
+type DeliveryFilter struct {
+	Status         string
+	Channel        string
+	UserID         uint
+	SubscriptionID uint
+	Limit          int
+}
+
+type DeliveryRepository interface {
+	Create(deliveries []models.NotificationDelivery) error
+	FindByID(id uint) (*models.NotificationDelivery, error)
+	FindDue(now time.Time, limit int) ([]models.NotificationDelivery, error)
+	FindDueHeld(now time.Time, limit int) ([]models.NotificationDelivery, error)
+	RescheduleHeld(userID uint, status string, nextAttemptAt time.Time) error
+	Find(filter DeliveryFilter) ([]models.NotificationDelivery, error)
+	Update(delivery *models.NotificationDelivery) error
+}
+
+type deliveryRepository struct {
+	db *gorm.DB
+}
+
+func NewDeliveryRepository(db *gorm.DB) DeliveryRepository {
+	return &deliveryRepository{db: db}
+}
+
+func (r *deliveryRepository) Create(deliveries []models.NotificationDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.Omit(clause.Associations).Create(&deliveries).Error
+}
+
+func (r *deliveryRepository) FindByID(id uint) (*models.NotificationDelivery, error) {
+	var delivery models.NotificationDelivery
+	err := r.db.Preload("Notification").Preload("Subscription").First(&delivery, id).Error
+	return &delivery, err
+}
+
+// FindDue returns pending deliveries whose next attempt is due, oldest first,
+// with their notification and subscription.
+func (r *deliveryRepository) FindDue(now time.Time, limit int) ([]models.NotificationDelivery, error) {
+	var deliveries []models.NotificationDelivery
+	err := r.db.Preload("Notification").Preload("Subscription").
+		Where("status = ? AND next_attempt_at <= ?", models.DeliveryStatusPending, now).
+		Order("next_attempt_at ASC, id ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// FindDueHeld returns deliveries held for a digest that is due, grouped by
+// subscription, with their notification and subscription. limit caps the
+// subscriptions rather than the deliveries, so that each digest is complete.
+func (r *deliveryRepository) FindDueHeld(now time.Time, limit int) ([]models.NotificationDelivery, error) {
+	subscriptions := r.db.Model(&models.NotificationDelivery{}).
+		Distinct("subscription_id").
+		Where("status = ? AND next_attempt_at <= ?", models.DeliveryStatusHeld, now).
+		Order("subscription_id ASC").
+		Limit(limit)
+	var deliveries []models.NotificationDelivery
+	err := r.db.Preload("Notification").Preload("Subscription").
+		Where("status = ? AND next_attempt_at <= ?", models.DeliveryStatusHeld, now).
+		Where("subscription_id IN (?)", subscriptions).
+		Order("subscription_id ASC, id ASC").
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// RescheduleHeld moves the user's held deliveries to status and their next
+// attempt to nextAttemptAt, e.g. after the digest settings changed.
+func (r *deliveryRepository) RescheduleHeld(userID uint, status string, nextAttemptAt time.Time) error {
+	return r.db.Model(&models.NotificationDelivery{}).
+		Where("status = ?", models.DeliveryStatusHeld).
+		Where("subscription_id IN (SELECT id FROM channel_subscriptions WHERE user_id = ?)", userID).
+		Updates(map[string]any{"status": status, "next_attempt_at": nextAttemptAt}).Error
+}
+
+// Find lists deliveries matching filter, newest first.
+func (r *deliveryRepository) Find(filter DeliveryFilter) ([]models.NotificationDelivery, error) {
+	query := r.db.Model(&models.NotificationDelivery{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Channel != "" {
+		query = query.Where("channel = ?", filter.Channel)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("subscription_id IN (SELECT id FROM channel_subscriptions WHERE user_id = ?)", filter.UserID)
+	}
+	if filter.SubscriptionID != 0 {
+		query = query.Where("subscription_id = ?", filter.SubscriptionID)
+	}
+	var deliveries []models.NotificationDelivery
+	err := query.Order("id DESC").Limit(filter.Limit).Find(&deliveries).Error
+	return deliveries, err
+}
+
+// Update saves the delivery's own columns.
+func (r *deliveryRepository) Update(delivery *models.NotificationDelivery) error {
+	return r.db.Omit(clause.Associations).Save(delivery).Error
+}
@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestDeliveryRepository_FindDueHeld_LimitsSubscriptions(t *testing.T) {
+	db := dryRunDB(t)
+	var stmt *gorm.Statement
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+		stmt = tx.Statement // The outer query, built after its subquery
+	}))
+	r := &deliveryRepository{db: db}
+	now := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	_, err := r.FindDueHeld(now, 50)
+	require.NoError(t, err)
+
+	sql := stmt.SQL.String()
+	assert.Contains(t, sql, `subscription_id IN (SELECT DISTINCT "subscription_id" FROM`)
+	assert.Contains(t, sql, "ORDER BY subscription_id ASC LIMIT $")
+	assert.NotContains(t, sql, "ORDER BY subscription_id ASC, id ASC LIMIT")
+	assert.Equal(t, []interface{}{models.DeliveryStatusHeld, now, models.DeliveryStatusHeld, now, 50}, stmt.Vars)
+}
@@ -1,23 +1,36 @@
 package repositories
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/sidler1/manga-backend/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MangaRepository interface {
 	FindAll() ([]models.Manga, error)
 	FindByID(id uint) (*models.Manga, error)
+	FindByIDs(ids []uint, include MangaIncludes) ([]models.Manga, error)
 	SearchByTags(tags []string) ([]models.Manga, error)
 	Update(manga *models.Manga) error
 	Create(manga *models.Manga) error
+	Delete(id uint) error
+	Restore(id uint) error
+	FindByIDWithDeleted(id uint) (*models.Manga, error)
 	FindByWebsiteID(websiteID uint) ([]models.Manga, error)
-	FindAllWithPagination(page, limit int, filters map[string]string, sort string) ([]models.Manga, int, error)
+	FindAllWithPagination(page, limit int, filter MangaFilter, sort MangaSort) ([]MangaSummary, int, error)
 	FindChaptersByMangaID(mangaID uint) ([]models.Chapter, error)
-	FindFavoritesWithUpdates(userID uint, since time.Time) ([]models.Manga, error)
+	FindFavoritesWithUpdates(userID uint, since time.Time, hideAdult bool) ([]MangaSummary, error)
 	FindBySlug(slug string) (*models.Manga, error)
+	FindFavoritesByReleaseState(userID uint, state string, hideAdult bool) ([]models.Manga, error)
+	Search(params MangaSearchParams) ([]MangaSearchResult, int, error)
+	FindPage(filter MangaFilter, sort MangaSort, page PageRequest) ([]MangaSummary, PageInfo, error)
+	FindFavoritesPage(userID uint, state string, hideAdult bool, page PageRequest) ([]MangaSummary, PageInfo, error)
+	FindDueForMetadataRefresh(before time.Time, limit int) ([]models.Manga, error)
+	FindDueForSourceCheck(before time.Time, limit int) ([]models.Manga, error)
+	UpdateFields(id uint, fields map[string]interface{}) error
 }
 
 type mangaRepository struct {
@@ -30,13 +43,13 @@ func NewMangaRepository(db *gorm.DB) MangaRepository {
 
 func (r *mangaRepository) FindAll() ([]models.Manga, error) {
 	var mangas []models.Manga
-	err := r.db.Preload("Tags").Preload("Chapters").Preload("Website").Find(&mangas).Error
+	err := r.db.Find(&mangas).Error
 	return mangas, err
 }
 
 func (r *mangaRepository) FindByID(id uint) (*models.Manga, error) {
 	var manga models.Manga
-	err := r.db.Preload("Tags").Preload("Chapters").Preload("Website").First(&manga, id).Error
+	err := r.db.Preload("Tags").Preload("Chapters").Preload("Website").Preload("Credits.Creator").First(&manga, id).Error
 	return &manga, err
 }
 
@@ -52,8 +65,65 @@ func (r *mangaRepository) SearchByTags(tags []string) ([]models.Manga, error) {
 	return mangas, err
 }
 
+// Update saves the manga's own columns; tags, chapters and credits have their
+// own write paths.
 func (r *mangaRepository) Update(manga *models.Manga) error {
-	return r.db.Save(manga).Error
+	return r.db.Omit(clause.Associations).Save(manga).Error
+}
+
+// UpdateFields sets only the given columns, so background jobs do not
+// overwrite fields changed while they ran.
+func (r *mangaRepository) UpdateFields(id uint, fields map[string]interface{}) error {
+	return r.db.Model(&models.Manga{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// FindDueForMetadataRefresh returns up to limit mangas with a source slug whose
+// metadata was last refreshed before before, least recently refreshed first.
+// Mangas never refreshed, stored before the column existed, come first.
+// Mangas removed from their source are skipped.
+func (r *mangaRepository) FindDueForMetadataRefresh(before time.Time, limit int) ([]models.Manga, error) {
+	var mangas []models.Manga
+	err := r.db.Preload("Tags").Preload("Website").
+		Where("slug <> '' AND (metadata_refreshed_at IS NULL OR metadata_refreshed_at < ?) AND removed_from_source_at IS NULL", before).
+		Order("metadata_refreshed_at ASC NULLS FIRST, id ASC").
+		Limit(limit).
+		Find(&mangas).Error
+	return mangas, err
+}
+
+// FindDueForSourceCheck returns up to limit mangas with a source slug whose
+// availability was last checked before before, least recently checked first.
+// Mangas never checked, stored before the column existed, come first.
+func (r *mangaRepository) FindDueForSourceCheck(before time.Time, limit int) ([]models.Manga, error) {
+	var mangas []models.Manga
+	err := r.db.Preload("Website").
+		Where("slug <> '' AND (source_checked_at IS NULL OR source_checked_at < ?)", before).
+		Order("source_checked_at ASC NULLS FIRST, id ASC").
+		Limit(limit).
+		Find(&mangas).Error
+	return mangas, err
+}
+
+// Delete soft-deletes a manga; it can be brought back with Restore.
+func (r *mangaRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Manga{}, id).Error
+}
+
+func (r *mangaRepository) Restore(id uint) error {
+	result := r.db.Unscoped().Model(&models.Manga{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+// FindByIDWithDeleted loads a manga without associations, including soft-deleted ones.
+func (r *mangaRepository) FindByIDWithDeleted(id uint) (*models.Manga, error) {
+	var manga models.Manga
+	err := r.db.Unscoped().First(&manga, id).Error
+	return &manga, err
 }
 
 func (r *mangaRepository) Create(manga *models.Manga) error {
@@ -89,48 +159,124 @@ func (r *mangaRepository) FindUserFavorites(userID uint, page, limit int) ([]mod
 	return favorites, int(total), err
 }
 
-func (r *mangaRepository) FindAllWithPagination(page, limit int, filters map[string]string, sort string) ([]models.Manga, int, error) {
-	var mangas []models.Manga
+func (r *mangaRepository) FindAllWithPagination(page, limit int, filter MangaFilter, sort MangaSort) ([]MangaSummary, int, error) {
 	var total int64
 
 	offset := (page - 1) * limit
 
-	query := r.db.Model(&models.Manga{}).Preload("Tags").Preload("Chapters").Preload("Website")
-
-	// Apply filters
-	for key, value := range filters {
-		query = query.Where(key+" ILIKE ?", "%"+value+"%")
+	orderBy, err := sort.orderClause()
+	if err != nil {
+		return nil, 0, err
 	}
 
-	// Apply sorting
-	if sort != "" {
-		query = query.Order(sort)
-	}
+	query := filter.apply(r.db.Model(&models.Manga{}))
 
-	err := query.Count(&total).Error
+	err = query.Count(&total).Error
 	if err != nil {
 		return nil, 0, err
 	}
 
-	err = query.Offset(offset).Limit(limit).Find(&mangas).Error
+	mangas, err := findSummaries(query.Order(orderBy).Offset(offset).Limit(limit))
 	return mangas, int(total), err
 }
 
+// FindPage returns one keyset page of the filtered manga list.
+func (r *mangaRepository) FindPage(filter MangaFilter, sort MangaSort, page PageRequest) ([]MangaSummary, PageInfo, error) {
+	if sort.Field == "" {
+		sort = DefaultMangaSort
+	}
+	column, ok := mangaSortColumns[sort.Field]
+	if !ok {
+		return nil, PageInfo{}, fmt.Errorf("unsupported sort field %q", sort.Field)
+	}
+	var afterValue interface{}
+	if page.After != nil {
+		var err error
+		if afterValue, err = sort.cursorValue(page.After); err != nil {
+			return nil, PageInfo{}, err
+		}
+	}
+
+	query := filter.apply(r.db.Model(&models.Manga{}))
+	total, err := countIf(query, page)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+
+	mangas, err := findSummaries(keyset(query, column, "mangas.id", sort.Desc, page.After, afterValue, page.Limit))
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+
+	info := PageInfo{Total: total}
+	if len(mangas) > page.Limit {
+		mangas = mangas[:page.Limit]
+		last := mangas[len(mangas)-1]
+		value, err := summarySortValue(sort.Field, &last)
+		if err != nil {
+			return nil, PageInfo{}, err
+		}
+		info.NextCursor = Cursor{Sort: sort.cursorKey(), Value: value, ID: last.ID}.Encode()
+	}
+	return mangas, info, nil
+}
+
+// FindFavoritesPage returns one keyset page of a user's favorites, most
+// recently updated first, optionally restricted to a release state.
+func (r *mangaRepository) FindFavoritesPage(userID uint, state string, hideAdult bool, page PageRequest) ([]MangaSummary, PageInfo, error) {
+	var afterValue interface{}
+	if page.After != nil {
+		if page.After.Sort != "favorites" {
+			return nil, PageInfo{}, ErrInvalidCursor
+		}
+		var err error
+		if afterValue, err = parseCursorTime(page.After); err != nil {
+			return nil, PageInfo{}, err
+		}
+	}
+
+	query := r.db.Model(&models.Manga{}).
+		Joins("JOIN user_favorites ON user_favorites.manga_id = mangas.id").
+		Where("user_favorites.user_id = ?", userID)
+	if state != "" {
+		query = query.Where("mangas.release_state = ?", state)
+	}
+	if hideAdult {
+		query = withoutAdult(query)
+	}
+	total, err := countIf(query, page)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+
+	mangas, err := findSummaries(keyset(query, "mangas.update_time", "mangas.id", true, page.After, afterValue, page.Limit))
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+
+	info := PageInfo{Total: total}
+	if len(mangas) > page.Limit {
+		mangas = mangas[:page.Limit]
+		last := mangas[len(mangas)-1]
+		info.NextCursor = Cursor{Sort: "favorites", Value: formatCursorTime(last.UpdateTime), ID: last.ID}.Encode()
+	}
+	return mangas, info, nil
+}
+
 func (r *mangaRepository) FindChaptersByMangaID(mangaID uint) ([]models.Chapter, error) {
 	var chapters []models.Chapter
 	err := r.db.Where("manga_id = ?", mangaID).Order("number DESC").Find(&chapters).Error // DESC for latest first
 	return chapters, err
 }
 
-func (r *mangaRepository) FindFavoritesWithUpdates(userID uint, since time.Time) ([]models.Manga, error) {
-	var mangas []models.Manga
-	err := r.db.Table("mangas").
+func (r *mangaRepository) FindFavoritesWithUpdates(userID uint, since time.Time, hideAdult bool) ([]MangaSummary, error) {
+	query := r.db.Model(&models.Manga{}).
 		Joins("JOIN user_favorites ON user_favorites.manga_id = mangas.id").
-		Where("user_favorites.user_id = ? AND mangas.update_time > ?", userID, since).
-		Preload("Tags").Preload("Chapters").Preload("Website").
-		Order("mangas.update_time DESC").
-		Find(&mangas).Error
-	return mangas, err
+		Where("user_favorites.user_id = ? AND mangas.update_time > ?", userID, since)
+	if hideAdult {
+		query = withoutAdult(query)
+	}
+	return findSummaries(query.Order("mangas.update_time DESC"))
 }
 
 func (r *mangaRepository) FindBySlug(slug string) (*models.Manga, error) {
@@ -141,3 +287,16 @@ func (r *mangaRepository) FindBySlug(slug string) (*models.Manga, error) {
 	}
 	return &manga, nil
 }
+
+func (r *mangaRepository) FindFavoritesByReleaseState(userID uint, state string, hideAdult bool) ([]models.Manga, error) {
+	var mangas []models.Manga
+	query := r.db.Joins("JOIN user_favorites ON user_favorites.manga_id = mangas.id").
+		Where("user_favorites.user_id = ? AND mangas.release_state = ?", userID, state)
+	if hideAdult {
+		query = withoutAdult(query)
+	}
+	err := query.Preload("Tags").Preload("Website").
+		Order("mangas.title ASC").
+		Find(&mangas).Error
+	return mangas, err
+}
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// capturedQuery returns a dry-run repository and the first query it builds.
+func capturedQuery(t *testing.T) (*mangaRepository, func() *gorm.Statement) {
+	db := dryRunDB(t)
+	var stmt *gorm.Statement
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+		if stmt == nil {
+			stmt = tx.Statement
+		}
+	}))
+	return &mangaRepository{db: db}, func() *gorm.Statement { return stmt }
+}
+
+func TestMangaRepository_FindDueForMetadataRefresh_IncludesNeverRefreshed(t *testing.T) {
+	r, stmt := capturedQuery(t)
+	before := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := r.FindDueForMetadataRefresh(before, 10)
+	require.NoError(t, err)
+
+	sql := stmt().SQL.String()
+	assert.Contains(t, sql, "(metadata_refreshed_at IS NULL OR metadata_refreshed_at < $1)")
+	assert.Contains(t, sql, "ORDER BY metadata_refreshed_at ASC NULLS FIRST, id ASC")
+	assert.Equal(t, before, stmt().Vars[0])
+}
+
+func TestMangaRepository_FindDueForSourceCheck_IncludesNeverChecked(t *testing.T) {
+	r, stmt := capturedQuery(t)
+	before := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := r.FindDueForSourceCheck(before, 10)
+	require.NoError(t, err)
+
+	sql := stmt().SQL.String()
+	assert.Contains(t, sql, "(source_checked_at IS NULL OR source_checked_at < $1)")
+	assert.Contains(t, sql, "ORDER BY source_checked_at ASC NULLS FIRST, id ASC")
+	assert.Equal(t, before, stmt().Vars[0])
+}
@@ -0,0 +1,140 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// MangaFilter holds the whitelisted filters of the manga list. Values are only
+// ever bound as query parameters; column names come from this file.
+type MangaFilter struct {
+	IncludeTags     []string // Manga must carry every one of these tags
+	ExcludeTags     []string // Manga must carry none of these tags
+	Statuses        []string // Release states, see models.ReleaseState*
+	WebsiteID       uint
+	Author          string // Case-insensitive substring
+	UpdatedSince    time.Time
+	UnreadForUserID uint // Only favorites of this user with chapters past their bookmark
+	HideAdult       bool // Exclude mangas rated adult
+}
+
+// MangaSortField is a sortable attribute of the manga list.
+type MangaSortField string
+
+const (
+	SortByUpdated       MangaSortField = "updated"
+	SortByTitle         MangaSortField = "title"
+	SortByPopularity    MangaSortField = "popularity"
+	SortByEstimatedNext MangaSortField = "estimated_next"
+)
+
+var mangaSortColumns = map[MangaSortField]string{
+	SortByUpdated:       "mangas.update_time",
+	SortByTitle:         "mangas.title",
+	SortByPopularity:    "(SELECT COUNT(*) FROM user_favorites WHERE user_favorites.manga_id = mangas.id)",
+	SortByEstimatedNext: "mangas.estimated_next",
+}
+
+// IsValidMangaSortField reports whether field can be used in a MangaSort.
+func IsValidMangaSortField(field string) bool {
+	_, ok := mangaSortColumns[MangaSortField(field)]
+	return ok
+}
+
+// MangaSort orders the manga list. The zero value sorts by most recently updated.
+type MangaSort struct {
+	Field MangaSortField
+	Desc  bool
+}
+
+// DefaultMangaSort lists the most recently updated mangas first.
+var DefaultMangaSort = MangaSort{Field: SortByUpdated, Desc: true}
+
+func (s MangaSort) orderClause() (string, error) {
+	if s.Field == "" {
+		s = DefaultMangaSort
+	}
+	column, ok := mangaSortColumns[s.Field]
+	if !ok {
+		return "", fmt.Errorf("unsupported sort field %q", s.Field)
+	}
+	direction := "ASC NULLS LAST"
+	if s.Desc {
+		direction = "DESC NULLS LAST"
+	}
+	// The id tiebreaker keeps pages stable between requests.
+	return column + " " + direction + ", mangas.id ASC", nil
+}
+
+// cursorKey identifies the ordering a cursor was issued for.
+func (s MangaSort) cursorKey() string {
+	if s.Desc {
+		return string(s.Field) + ":desc"
+	}
+	return string(s.Field) + ":asc"
+}
+
+// cursorValue parses the ordering key stored in a cursor of this sort.
+func (s MangaSort) cursorValue(c *Cursor) (interface{}, error) {
+	if c.Sort != s.cursorKey() {
+		return nil, ErrInvalidCursor
+	}
+	switch s.Field {
+	case SortByUpdated, SortByEstimatedNext:
+		return parseCursorTime(c)
+	case SortByPopularity:
+		return parseCursorInt(c)
+	default:
+		return c.Value, nil
+	}
+}
+
+func (f MangaFilter) apply(query *gorm.DB) *gorm.DB {
+	for _, tag := range f.IncludeTags {
+		query = query.Where("EXISTS (SELECT 1 FROM manga_tags JOIN tags ON tags.id = manga_tags.tag_id "+
+			"WHERE manga_tags.manga_id = mangas.id AND lower(tags.name) = lower(?))", tag)
+	}
+	if len(f.ExcludeTags) > 0 {
+		lowered := make([]string, len(f.ExcludeTags))
+		for i, tag := range f.ExcludeTags {
+			lowered[i] = strings.ToLower(tag)
+		}
+		query = query.Where("NOT EXISTS (SELECT 1 FROM manga_tags JOIN tags ON tags.id = manga_tags.tag_id "+
+			"WHERE manga_tags.manga_id = mangas.id AND lower(tags.name) IN ?)", lowered)
+	}
+	if len(f.Statuses) > 0 {
+		query = query.Where("mangas.release_state IN ?", f.Statuses)
+	}
+	if f.WebsiteID != 0 {
+		query = query.Where("mangas.website_id = ?", f.WebsiteID)
+	}
+	if f.Author != "" {
+		query = query.Where("mangas.author ILIKE ?", "%"+escapeLikePattern(f.Author)+"%")
+	}
+	if !f.UpdatedSince.IsZero() {
+		query = query.Where("mangas.update_time >= ?", f.UpdatedSince)
+	}
+	if f.UnreadForUserID != 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM user_favorites WHERE user_favorites.manga_id = mangas.id AND user_favorites.user_id = ?)", f.UnreadForUserID).
+			Where("EXISTS (SELECT 1 FROM chapters WHERE chapters.manga_id = mangas.id AND chapters.deleted_at IS NULL "+
+				"AND chapters.number > COALESCE((SELECT MAX(bookmarks.chapter) FROM bookmarks "+
+				"WHERE bookmarks.manga_id = mangas.id AND bookmarks.user_id = ? AND bookmarks.deleted_at IS NULL), 0))", f.UnreadForUserID)
+	}
+	if f.HideAdult {
+		query = withoutAdult(query)
+	}
+	return query
+}
+
+// withoutAdult excludes mangas rated adult from query.
+func withoutAdult(query *gorm.DB) *gorm.DB {
+	return query.Where("mangas.content_rating <> ?", models.ContentRatingAdult)
+}
+
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB builds statements without a database, so tests can check the SQL
+// and bound values repositories generate.
+func dryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+	return db
+}
+
+func TestMangaFilter_Apply(t *testing.T) {
+	since := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		filter MangaFilter
+		sql    []string
+		vars   []interface{}
+	}{
+		{"empty", MangaFilter{}, nil, []interface{}{}},
+		{
+			"included tags each need a match",
+			MangaFilter{IncludeTags: []string{"Action", "Romance"}},
+			[]string{"lower(tags.name) = lower($1)", "lower(tags.name) = lower($2)"},
+			[]interface{}{"Action", "Romance"},
+		},
+		{
+			"excluded tags are lowered",
+			MangaFilter{ExcludeTags: []string{"Gore"}},
+			[]string{"NOT EXISTS", "lower(tags.name) IN ($1)"},
+			[]interface{}{"gore"},
+		},
+		{"statuses", MangaFilter{Statuses: []string{"ongoing"}}, []string{"mangas.release_state IN ($1)"}, []interface{}{"ongoing"}},
+		{"website", MangaFilter{WebsiteID: 3}, []string{"mangas.website_id = $1"}, []interface{}{uint(3)}},
+		{"author is an escaped substring", MangaFilter{Author: "50%_off"}, []string{"mangas.author ILIKE $1"}, []interface{}{`%50\%\_off%`}},
+		{"updated since", MangaFilter{UpdatedSince: since}, []string{"mangas.update_time >= $1"}, []interface{}{since}},
+		{"unread", MangaFilter{UnreadForUserID: 7}, []string{"user_favorites.user_id = $1", "bookmarks.user_id = $2"}, []interface{}{uint(7), uint(7)}},
+		{"adult hidden", MangaFilter{HideAdult: true}, []string{"mangas.content_rating <> $1"}, []interface{}{models.ContentRatingAdult}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mangas []models.Manga
+			stmt := tt.filter.apply(dryRunDB(t).Model(&models.Manga{})).Find(&mangas).Statement
+			for _, fragment := range tt.sql {
+				assert.Contains(t, stmt.SQL.String(), fragment)
+			}
+			assert.Equal(t, tt.vars, stmt.Vars)
+		})
+	}
+}
+
+func TestMangaSort_OrderClause(t *testing.T) {
+	tests := []struct {
+		sort   MangaSort
+		clause string
+	}{
+		{MangaSort{}, "mangas.update_time DESC NULLS LAST, mangas.id ASC"},
+		{MangaSort{Field: SortByTitle}, "mangas.title ASC NULLS LAST, mangas.id ASC"},
+		{MangaSort{Field: SortByEstimatedNext, Desc: true}, "mangas.estimated_next DESC NULLS LAST, mangas.id ASC"},
+	}
+	for _, tt := range tests {
+		clause, err := tt.sort.orderClause()
+		require.NoError(t, err)
+		assert.Equal(t, tt.clause, clause)
+	}
+
+	_, err := MangaSort{Field: "id; DROP TABLE mangas"}.orderClause()
+	assert.Error(t, err)
+	assert.False(t, IsValidMangaSortField("rating"))
+}
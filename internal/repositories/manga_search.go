@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/sidler1/manga-backend/internal/models"
+)
+
+// MangaSearchParams describes a full-text search over mangas.
+type MangaSearchParams struct {
+	Query     string
+	Tag       string
+	WebsiteID uint
+	Status    string // Release state, see models.ReleaseState*
+	HideAdult bool   // Exclude mangas rated adult
+	Page      int
+	Limit     int
+}
+
+// MangaSearchResult is a ranked search hit with highlighted excerpts.
+type MangaSearchResult struct {
+	Manga          models.Manga `json:"manga"`
+	Rank           float64      `json:"rank"`
+	TitleHighlight string       `json:"title_highlight"`
+	Snippet        string       `json:"snippet"`
+	Blurred        bool         `json:"blurred"`
+}
+
+type mangaSearchHit struct {
+	ID             uint
+	Rank           float64
+	TitleHighlight string
+	Snippet        string
+}
+
+const searchHeadlineOptions = "StartSel=<mark>, StopSel=</mark>"
+
+// Search ranks mangas against a prefix tsquery built from params.Query. Only
+// the requested page is loaded with its tags and website.
+func (r *mangaRepository) Search(params MangaSearchParams) ([]MangaSearchResult, int, error) {
+	tsQuery := buildPrefixTSQuery(params.Query)
+	if tsQuery == "" {
+		return []MangaSearchResult{}, 0, nil
+	}
+
+	query := r.db.Table("mangas").
+		Joins("CROSS JOIN to_tsquery('simple', ?) AS q", tsQuery).
+		Where("mangas.deleted_at IS NULL AND mangas.search_vector @@ q")
+	if params.Tag != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM manga_tags JOIN tags ON tags.id = manga_tags.tag_id WHERE manga_tags.manga_id = mangas.id AND tags.name = ?)", params.Tag)
+	}
+	if params.WebsiteID != 0 {
+		query = query.Where("mangas.website_id = ?", params.WebsiteID)
+	}
+	if params.Status != "" {
+		query = query.Where("mangas.release_state = ?", params.Status)
+	}
+	if params.HideAdult {
+		query = withoutAdult(query)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var hits []mangaSearchHit
+	err := query.
+		Select("mangas.id, ts_rank_cd(mangas.search_vector, q) AS rank, "+
+			"ts_headline('simple', mangas.title, q, ?) AS title_highlight, "+
+			"ts_headline('simple', mangas.description, q, ?) AS snippet",
+			searchHeadlineOptions+", HighlightAll=true",
+			searchHeadlineOptions+", MaxWords=35, MinWords=15, MaxFragments=2").
+		Order("rank DESC, mangas.id ASC").
+		Offset((params.Page - 1) * params.Limit).Limit(params.Limit).
+		Scan(&hits).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(hits) == 0 {
+		return []MangaSearchResult{}, int(total), nil
+	}
+
+	ids := make([]uint, len(hits))
+	for i, h := range hits {
+		ids[i] = h.ID
+	}
+	var mangas []models.Manga
+	if err := r.db.Preload("Tags").Preload("Website").Where("id IN ?", ids).Find(&mangas).Error; err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[uint]models.Manga, len(mangas))
+	for _, m := range mangas {
+		byID[m.ID] = m
+	}
+
+	results := make([]MangaSearchResult, 0, len(hits))
+	for _, h := range hits {
+		m, ok := byID[h.ID]
+		if !ok {
+			continue
+		}
+		results = append(results, MangaSearchResult{
+			Manga:          m,
+			Rank:           h.Rank,
+			TitleHighlight: h.TitleHighlight,
+			Snippet:        h.Snippet,
+		})
+	}
+	return results, int(total), nil
+}
+
+// buildPrefixTSQuery turns free text into a tsquery that requires every word
+// as a prefix, e.g. "solo lev" becomes "solo:* & lev:*". Characters with a
+// meaning in tsquery syntax are dropped.
+func buildPrefixTSQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		terms = append(terms, w+":*")
+	}
+	return strings.Join(terms, " & ")
+}
@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// MangaSummary is the list-view projection of a manga. It is built by a single
+// query instead of preloading every tag, chapter and website.
+type MangaSummary struct {
+	ID                  uint       `json:"id"`
+	Title               string     `json:"title"`
+	CoverURL            string     `json:"cover_url"`
+	ReleaseState        string     `json:"release_state"`
+	ContentRating       string     `json:"content_rating"`
+	Blurred             bool       `json:"blurred" gorm:"-"`
+	LatestChapterNumber *uint      `json:"latest_chapter_number"`
+	LatestChapterAt     *time.Time `json:"latest_chapter_at"`
+	UpdateTime          time.Time  `json:"updated_at"`
+	EstimatedNext       time.Time  `json:"estimated_next"`
+	WebsiteID           uint       `json:"website_id"`
+	WebsiteName         string     `json:"website_name"`
+	FavoriteCount       int64      `json:"favorite_count"`
+	RemovedFromSourceAt *time.Time `json:"removed_from_source_at"`
+	TagNames            string     `json:"-"`
+	Tags                []string   `json:"tags" gorm:"-"`
+}
+
+// MangaIncludes selects the associations loaded when a client expands list
+// entries into full mangas with ?include=.
+type MangaIncludes struct {
+	Tags     bool
+	Chapters bool
+	Website  bool
+}
+
+// IsEmpty reports whether no expansion was requested.
+func (i MangaIncludes) IsEmpty() bool {
+	return !i.Tags && !i.Chapters && !i.Website
+}
+
+// tagNameSeparator joins tag names in the aggregated column; the ASCII unit
+// separator cannot appear in scraped genre text.
+const tagNameSeparator = "\x1f"
+
+const mangaSummaryColumns = "mangas.id, mangas.title, mangas.cover_url, mangas.release_state, mangas.content_rating, " +
+	"mangas.update_time, mangas.estimated_next, mangas.removed_from_source_at, mangas.website_id, websites.name AS website_name, " +
+	"latest.number AS latest_chapter_number, latest.release_date AS latest_chapter_at, " +
+	"(SELECT COUNT(*) FROM user_favorites WHERE user_favorites.manga_id = mangas.id) AS favorite_count, " +
+	"(SELECT string_agg(tags.name, chr(31) ORDER BY tags.name) FROM manga_tags JOIN tags ON tags.id = manga_tags.tag_id " +
+	"WHERE manga_tags.manga_id = mangas.id) AS tag_names"
+
+// selectSummaries turns a query over mangas into a MangaSummary projection.
+func selectSummaries(query *gorm.DB) *gorm.DB {
+	return query.Select(mangaSummaryColumns).
+		Joins("LEFT JOIN websites ON websites.id = mangas.website_id").
+		Joins("LEFT JOIN LATERAL (SELECT chapters.number, chapters.release_date FROM chapters " +
+			"WHERE chapters.manga_id = mangas.id AND chapters.deleted_at IS NULL " +
+			"ORDER BY chapters.number DESC LIMIT 1) AS latest ON true")
+}
+
+// findSummaries runs a summary query and splits the aggregated tag names.
+func findSummaries(query *gorm.DB) ([]MangaSummary, error) {
+	summaries := []MangaSummary{}
+	if err := selectSummaries(query).Find(&summaries).Error; err != nil {
+		return nil, err
+	}
+	for i := range summaries {
+		summaries[i].Tags = []string{}
+		if summaries[i].TagNames != "" {
+			summaries[i].Tags = strings.Split(summaries[i].TagNames, tagNameSeparator)
+		}
+	}
+	return summaries, nil
+}
+
+// FindByIDs loads full mangas with the requested associations, in ids order.
+func (r *mangaRepository) FindByIDs(ids []uint, include MangaIncludes) ([]models.Manga, error) {
+	if len(ids) == 0 {
+		return []models.Manga{}, nil
+	}
+	query := r.db.Where("id IN ?", ids)
+	if include.Tags {
+		query = query.Preload("Tags")
+	}
+	if include.Chapters {
+		query = query.Preload("Chapters", func(db *gorm.DB) *gorm.DB { return db.Order("number DESC") })
+	}
+	if include.Website {
+		query = query.Preload("Website")
+	}
+	var mangas []models.Manga
+	if err := query.Find(&mangas).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]models.Manga, len(mangas))
+	for _, m := range mangas {
+		byID[m.ID] = m
+	}
+	ordered := make([]models.Manga, 0, len(ids))
+	for _, id := range ids {
+		if m, ok := byID[id]; ok {
+			ordered = append(ordered, m)
+		}
+	}
+	return ordered, nil
+}
+
+// summarySortValue returns the ordering key of a summary for a cursor.
+func summarySortValue(field MangaSortField, summary *MangaSummary) (string, error) {
+	switch field {
+	case SortByUpdated:
+		return formatCursorTime(summary.UpdateTime), nil
+	case SortByEstimatedNext:
+		return formatCursorTime(summary.EstimatedNext), nil
+	case SortByPopularity:
+		return fmt.Sprint(summary.FavoriteCount), nil
+	case SortByTitle:
+		return summary.Title, nil
+	default:
+		return "", fmt.Errorf("unsupported sort field %q", field)
+	}
+}
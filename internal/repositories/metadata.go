@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"github.com/sidler1/manga-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type MetadataRepository interface {
+	CreateChanges(changes []models.MangaMetadataChange) error
+	FindChangesByMangaID(mangaID uint) ([]models.MangaMetadataChange, error)
+}
+
+type metadataRepository struct {
+	db *gorm.DB
+}
+
+func NewMetadataRepository(db *gorm.DB) MetadataRepository {
+	return &metadataRepository{db: db}
+}
+
+func (r *metadataRepository) CreateChanges(changes []models.MangaMetadataChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	return r.db.Create(&changes).Error
+}
+
+func (r *metadataRepository) FindChangesByMangaID(mangaID uint) ([]models.MangaMetadataChange, error) {
+	var changes []models.MangaMetadataChange
+	err := r.db.Where("manga_id = ?", mangaID).Order("changed_at DESC, id DESC").Find(&changes).Error
+	return changes, err
+}
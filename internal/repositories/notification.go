@@ -1,14 +1,31 @@
 package repositories
 
 import (
+	"time"
+
 	"github.com/sidler1/manga-backend/internal/models"
 	"gorm.io/gorm"
 )
 
+// NotificationFilter narrows a user's notifications. Archived notifications
+// are only listed with Archived set, and then only them.
+type NotificationFilter struct {
+	MangaID    uint
+	Type       string
+	UnreadOnly bool
+	Archived   bool
+}
+
 type NotificationRepository interface {
 	Create(notification *models.Notification) error
-	FindByUserID(userID uint) ([]models.Notification, error)
-	// Add other methods as needed
+	FindByUserID(userID uint, filter NotificationFilter) ([]models.Notification, error)
+	FindPageByUserID(userID uint, filter NotificationFilter, page PageRequest) ([]models.Notification, PageInfo, error)
+	CountUnread(userID uint) (int64, error)
+	SetRead(userID uint, ids []uint, read bool, now time.Time) (int64, error)
+	SetReadBefore(userID uint, before time.Time, read bool, now time.Time) (int64, error)
+	SetArchived(userID, id uint, archived bool) error
+	Delete(userID, id uint) error
+	PurgeRead(readBefore time.Time) (int64, error)
 }
 
 type notificationRepository struct {
@@ -23,8 +40,129 @@ func (r *notificationRepository) Create(notification *models.Notification) error
 	return r.db.Create(notification).Error
 }
 
-func (r *notificationRepository) FindByUserID(userID uint) ([]models.Notification, error) {
+func (r *notificationRepository) FindByUserID(userID uint, filter NotificationFilter) ([]models.Notification, error) {
 	var notifications []models.Notification
-	err := r.db.Where("user_id = ?", userID).Order("sent_at DESC").Find(&notifications).Error
+	err := r.filtered(userID, filter).Order("sent_at DESC").Find(&notifications).Error
 	return notifications, err
 }
+
+func (r *notificationRepository) filtered(userID uint, filter NotificationFilter) *gorm.DB {
+	query := r.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if filter.Archived {
+		query = query.Where("archived")
+	} else {
+		query = query.Where("archived IS NOT TRUE") // Also rows stored before the column existed
+	}
+	if filter.MangaID != 0 {
+		query = query.Where("manga_id = ?", filter.MangaID)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.UnreadOnly {
+		query = query.Where("read = ?", false)
+	}
+	return query
+}
+
+// FindPageByUserID returns one keyset page of a user's notifications, newest first.
+func (r *notificationRepository) FindPageByUserID(userID uint, filter NotificationFilter, page PageRequest) ([]models.Notification, PageInfo, error) {
+	var afterValue interface{}
+	if page.After != nil {
+		if page.After.Sort != "notifications" {
+			return nil, PageInfo{}, ErrInvalidCursor
+		}
+		var err error
+		if afterValue, err = parseCursorTime(page.After); err != nil {
+			return nil, PageInfo{}, err
+		}
+	}
+
+	query := r.filtered(userID, filter)
+	total, err := countIf(query, page)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+
+	var notifications []models.Notification
+	err = keyset(query, "sent_at", "id", true, page.After, afterValue, page.Limit).Find(&notifications).Error
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+
+	info := PageInfo{Total: total}
+	if len(notifications) > page.Limit {
+		notifications = notifications[:page.Limit]
+		last := notifications[len(notifications)-1]
+		info.NextCursor = Cursor{Sort: "notifications", Value: formatCursorTime(last.SentAt), ID: last.ID}.Encode()
+	}
+	return notifications, info, nil
+}
+
+// CountUnread counts the user's unread notifications in the inbox, leaving
+// out archived ones.
+func (r *notificationRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := r.filtered(userID, NotificationFilter{UnreadOnly: true}).Count(&count).Error
+	return count, err
+}
+
+// SetRead marks the user's notifications with the given IDs read or unread
+// and returns how many were found.
+func (r *notificationRepository) SetRead(userID uint, ids []uint, read bool, now time.Time) (int64, error) {
+	result := r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND id IN ?", userID, ids).
+		Updates(readUpdates(read, now))
+	return result.RowsAffected, result.Error
+}
+
+// SetReadBefore marks the user's notifications sent at or before before
+// read or unread and returns how many changed.
+func (r *notificationRepository) SetReadBefore(userID uint, before time.Time, read bool, now time.Time) (int64, error) {
+	result := r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND sent_at <= ? AND read <> ?", userID, before, read).
+		Updates(readUpdates(read, now))
+	return result.RowsAffected, result.Error
+}
+
+func readUpdates(read bool, now time.Time) map[string]any {
+	if read {
+		return map[string]any{"read": true, "read_at": now}
+	}
+	return map[string]any{"read": false, "read_at": nil}
+}
+
+func (r *notificationRepository) SetArchived(userID, id uint, archived bool) error {
+	result := r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND id = ?", userID, id).
+		Update("archived", archived)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+func (r *notificationRepository) Delete(userID, id uint) error {
+	result := r.db.Where("user_id = ?", userID).Delete(&models.Notification{}, id)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+// PurgeRead permanently removes notifications read before readBefore, or
+// deleted before it, along with their deliveries.
+func (r *notificationRepository) PurgeRead(readBefore time.Time) (int64, error) {
+	var purged int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		old := tx.Unscoped().Model(&models.Notification{}).Select("id").
+			Where("(read AND read_at < ?) OR deleted_at < ?", readBefore, readBefore)
+		if err := tx.Unscoped().Where("notification_id IN (?)", old).Delete(&models.NotificationDelivery{}).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("(read AND read_at < ?) OR deleted_at < ?", readBefore, readBefore).Delete(&models.Notification{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
+}
@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// capturedStatements returns a dry-run notification repository and the
+// statements of the queries, updates and deletes it runs.
+func capturedStatements(t *testing.T) (*notificationRepository, *[]*gorm.Statement) {
+	db := dryRunDB(t)
+	var stmts []*gorm.Statement
+	capture := func(tx *gorm.DB) { stmts = append(stmts, tx.Statement) }
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:capture", capture))
+	require.NoError(t, db.Callback().Update().After("gorm:update").Register("test:capture", capture))
+	require.NoError(t, db.Callback().Delete().After("gorm:delete").Register("test:capture", capture))
+	// Writes skip the default transaction, which would connect.
+	return &notificationRepository{db: db.Session(&gorm.Session{SkipDefaultTransaction: true})}, &stmts
+}
+
+func TestNotificationRepository_Filtered(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter NotificationFilter
+		where  string
+		vars   []interface{}
+	}{
+		{"inbox", NotificationFilter{}, "WHERE user_id = $1 AND archived IS NOT TRUE AND", []interface{}{uint(7)}},
+		{"archived", NotificationFilter{Archived: true}, "WHERE user_id = $1 AND archived AND", []interface{}{uint(7)}},
+		{
+			"manga and type", NotificationFilter{MangaID: 3, Type: "chapter"},
+			"WHERE user_id = $1 AND archived IS NOT TRUE AND manga_id = $2 AND type = $3 AND",
+			[]interface{}{uint(7), uint(3), "chapter"},
+		},
+		{"unread", NotificationFilter{UnreadOnly: true}, "WHERE user_id = $1 AND archived IS NOT TRUE AND read = $2 AND", []interface{}{uint(7), false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, stmts := capturedStatements(t)
+			_, err := r.FindByUserID(7, tt.filter)
+			require.NoError(t, err)
+
+			require.Len(t, *stmts, 1)
+			stmt := (*stmts)[0]
+			assert.Contains(t, stmt.SQL.String(), tt.where)
+			assert.Contains(t, stmt.SQL.String(), `"notifications"."deleted_at" IS NULL ORDER BY sent_at DESC`)
+			assert.Equal(t, tt.vars, stmt.Vars)
+		})
+	}
+}
+
+func TestNotificationRepository_SetRead(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		read bool
+		set  string
+		vars []interface{}
+	}{
+		{true, `SET "read"=$1,"read_at"=$2,"updated_at"=$3`, []interface{}{true, now}},
+		{false, `SET "read"=$1,"read_at"=$2,"updated_at"=$3`, []interface{}{false, nil}},
+	}
+	for _, tt := range tests {
+		r, stmts := capturedStatements(t)
+		_, err := r.SetRead(7, []uint{1, 2}, tt.read, now)
+		require.NoError(t, err)
+
+		stmt := (*stmts)[0]
+		assert.Contains(t, stmt.SQL.String(), tt.set)
+		assert.Contains(t, stmt.SQL.String(), "WHERE (user_id = $4 AND id IN ($5,$6))")
+		assert.Equal(t, tt.vars, stmt.Vars[:2])
+		assert.Equal(t, []interface{}{uint(7), uint(1), uint(2)}, stmt.Vars[3:])
+	}
+}
+
+func TestNotificationRepository_SetReadBefore(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	before := now.Add(-time.Hour)
+	r, stmts := capturedStatements(t)
+
+	_, err := r.SetReadBefore(7, before, true, now)
+	require.NoError(t, err)
+
+	stmt := (*stmts)[0]
+	assert.Contains(t, stmt.SQL.String(), "WHERE (user_id = $4 AND sent_at <= $5 AND read <> $6)")
+	assert.Equal(t, []interface{}{uint(7), before, true}, stmt.Vars[3:])
+}
+
+func TestNotificationRepository_ArchiveAndDeleteOwnNotifications(t *testing.T) {
+	r, stmts := capturedStatements(t)
+
+	// Dry runs affect no rows, as for other users' or unknown notifications.
+	assert.ErrorIs(t, r.SetArchived(7, 3, true), gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, r.Delete(7, 3), gorm.ErrRecordNotFound)
+
+	require.Len(t, *stmts, 2)
+	assert.Contains(t, (*stmts)[0].SQL.String(), `SET "archived"=$1,"updated_at"=$2 WHERE (user_id = $3 AND id = $4)`)
+	assert.Contains(t, (*stmts)[1].SQL.String(), `SET "deleted_at"=$1 WHERE user_id = $2 AND "notifications"."id" = $3`)
+	assert.Equal(t, []interface{}{uint(7), uint(3)}, (*stmts)[0].Vars[2:])
+	assert.Equal(t, []interface{}{uint(7), uint(3)}, (*stmts)[1].Vars[1:])
+}
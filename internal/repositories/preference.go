@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"github.com/sidler1/manga-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PreferenceRepository interface {
+	FindByUserID(userID uint) ([]models.FavoritePreference, error)
+	FindByMangaID(mangaID uint) ([]models.FavoritePreference, error)
+	Save(preference *models.FavoritePreference) error
+	Delete(userID, mangaID uint) error
+}
+
+type preferenceRepository struct {
+	db *gorm.DB
+}
+
+func NewPreferenceRepository(db *gorm.DB) PreferenceRepository {
+	return &preferenceRepository{db: db}
+}
+
+func (r *preferenceRepository) FindByUserID(userID uint) ([]models.FavoritePreference, error) {
+	var preferences []models.FavoritePreference
+	err := r.db.Where("user_id = ?", userID).Order("manga_id ASC").Find(&preferences).Error
+	return preferences, err
+}
+
+// FindByMangaID returns the preferences of all users about a manga.
+func (r *preferenceRepository) FindByMangaID(mangaID uint) ([]models.FavoritePreference, error) {
+	var preferences []models.FavoritePreference
+	err := r.db.Where("manga_id = ?", mangaID).Find(&preferences).Error
+	return preferences, err
+}
+
+// Save creates or replaces the user's preference about the manga.
+func (r *preferenceRepository) Save(preference *models.FavoritePreference) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "manga_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"muted", "every_chapters", "completion_only", "skipped_chapters", "updated_at"}),
+	}).Create(preference).Error
+}
+
+func (r *preferenceRepository) Delete(userID, mangaID uint) error {
+	result := r.db.Where("user_id = ? AND manga_id = ?", userID, mangaID).Delete(&models.FavoritePreference{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"github.com/sidler1/manga-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type ReleaseStateRepository interface {
+	CreateTransition(transition *models.MangaStateTransition) error
+	FindTransitionsByMangaID(mangaID uint) ([]models.MangaStateTransition, error)
+}
+
+type releaseStateRepository struct {
+	db *gorm.DB
+}
+
+func NewReleaseStateRepository(db *gorm.DB) ReleaseStateRepository {
+	return &releaseStateRepository{db: db}
+}
+
+func (r *releaseStateRepository) CreateTransition(transition *models.MangaStateTransition) error {
+	return r.db.Create(transition).Error
+}
+
+func (r *releaseStateRepository) FindTransitionsByMangaID(mangaID uint) ([]models.MangaStateTransition, error) {
+	var transitions []models.MangaStateTransition
+	err := r.db.Where("manga_id = ?", mangaID).Order("transitioned_at DESC").Find(&transitions).Error
+	return transitions, err
+}
@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"github.com/sidler1/manga-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ServerKeyRepository interface {
+	// FindOrCreate returns the key stored under key.Name, storing key first
+	// when there is none. Concurrent callers all get the first stored key.
+	FindOrCreate(key *models.ServerKey) (*models.ServerKey, error)
+}
+
+type serverKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewServerKeyRepository(db *gorm.DB) ServerKeyRepository {
+	return &serverKeyRepository{db: db}
+}
+
+func (r *serverKeyRepository) FindOrCreate(key *models.ServerKey) (*models.ServerKey, error) {
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(key).Error; err != nil {
+		return nil, err
+	}
+	var stored models.ServerKey
+	err := r.db.Where("name = ?", key.Name).First(&stored).Error
+	return &stored, err
+}
@@ -1,14 +1,41 @@
 package repositories
 
 import (
+	"strings"
+	"unicode"
+
 	"github.com/sidler1/manga-backend/internal/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// TagUsage is a tag with the number of mangas carrying it.
+type TagUsage struct {
+	ID            uint   `json:"id"`
+	Name          string `json:"name"`
+	Category      string `json:"category"`
+	ContentRating string `json:"content_rating,omitempty"`
+	UsageCount    int64  `json:"usage_count"`
+}
+
 type TagRepository interface {
 	FindOrCreate(name string) (*models.Tag, error)
 	AddTagToManga(mangaID uint, tag string) error
+	AttachToManga(mangaID, tagID uint) error
+	SetMangaTags(mangaID uint, tagIDs []uint) error
+	FindByID(id uint) (*models.Tag, error)
+	FindByKey(key string) (*models.Tag, error)
+	FindAllWithUsage(category string) ([]TagUsage, error)
+	Create(tag *models.Tag) error
+	Update(tag *models.Tag) error
+	Delete(id uint) error
+	FindAliasByKey(key string) (*models.TagAlias, error)
+	FindAliasByID(id uint) (*models.TagAlias, error)
+	FindAliases(tagID uint) ([]models.TagAlias, error)
+	CreateAlias(alias *models.TagAlias) error
+	DeleteAlias(id uint) error
+	Merge(targetID uint, sourceIDs []uint) error
 }
 
 type tagRepository struct {
@@ -19,14 +46,181 @@ func NewTagRepository(db *gorm.DB) TagRepository {
 	return &tagRepository{db: db}
 }
 
+// TagKey normalizes tag text for matching: lowercase letters and digits only,
+// so "Sci-fi", "Sci Fi" and "sci fi" share the key "scifi".
+func TagKey(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// FindOrCreate returns the tag named name, creating it with its default
+// class from DefaultTagTaxonomy when it does not exist.
 func (r *tagRepository) FindOrCreate(name string) (*models.Tag, error) {
 	var tag models.Tag
-	err := r.db.Where("name = ?", name).FirstOrCreate(&tag).Error
+	key := TagKey(name)
+	class := ClassifyTag(key)
+	err := r.db.Where("name = ?", name).
+		Attrs(models.Tag{Name: name, Key: key, Category: class.Category, ContentRating: class.ContentRating}).
+		FirstOrCreate(&tag).Error
 	return &tag, err
 }
 
 func (r *tagRepository) AddTagToManga(mangaID uint, tag string) error {
-	// Implementation to add a tag to a manga
-	// This is just an example and may need to be adjusted based on your actual database schema
-	return r.db.Exec("INSERT INTO manga_tags (manga_id, tag) VALUES (?, ?)", mangaID, tag).Error
+	t, err := r.FindOrCreate(tag)
+	if err != nil {
+		return err
+	}
+	return r.AttachToManga(mangaID, t.ID)
+}
+
+func (r *tagRepository) AttachToManga(mangaID, tagID uint) error {
+	return r.db.Exec("INSERT INTO manga_tags (manga_id, tag_id) VALUES (?, ?) ON CONFLICT DO NOTHING", mangaID, tagID).Error
+}
+
+// SetMangaTags replaces the tags of a manga with tagIDs.
+func (r *tagRepository) SetMangaTags(mangaID uint, tagIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		remove := tx.Exec("DELETE FROM manga_tags WHERE manga_id = ?", mangaID)
+		if len(tagIDs) > 0 {
+			remove = tx.Exec("DELETE FROM manga_tags WHERE manga_id = ? AND tag_id NOT IN ?", mangaID, tagIDs)
+		}
+		if err := remove.Error; err != nil {
+			return err
+		}
+		for _, tagID := range tagIDs {
+			if err := tx.Exec("INSERT INTO manga_tags (manga_id, tag_id) VALUES (?, ?) ON CONFLICT DO NOTHING", mangaID, tagID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *tagRepository) FindByID(id uint) (*models.Tag, error) {
+	var tag models.Tag
+	err := r.db.First(&tag, id).Error
+	return &tag, err
+}
+
+func (r *tagRepository) FindByKey(key string) (*models.Tag, error) {
+	var tag models.Tag
+	err := r.db.Where("key = ?", key).Order("id ASC").First(&tag).Error
+	return &tag, err
+}
+
+func (r *tagRepository) FindAllWithUsage(category string) ([]TagUsage, error) {
+	usages := []TagUsage{}
+	query := r.db.Model(&models.Tag{}).
+		Select("tags.id, tags.name, tags.category, tags.content_rating, COUNT(manga_tags.manga_id) AS usage_count").
+		Joins("LEFT JOIN manga_tags ON manga_tags.tag_id = tags.id")
+	if category != "" {
+		query = query.Where("tags.category = ?", category)
+	}
+	err := query.
+		Group("tags.id, tags.name, tags.category, tags.content_rating").
+		Order("usage_count DESC, tags.name ASC").
+		Find(&usages).Error
+	return usages, err
+}
+
+func (r *tagRepository) Create(tag *models.Tag) error {
+	return r.db.Create(tag).Error
+}
+
+// Update saves tag and re-indexes the mangas carrying it, since tag names are
+// part of their search vector and tag ratings determine their content rating.
+func (r *tagRepository) Update(tag *models.Tag) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(tag).Error; err != nil {
+			return err
+		}
+		return tx.Exec("UPDATE mangas SET search_vector = NULL WHERE id IN (SELECT manga_id FROM manga_tags WHERE tag_id = ?)", tag.ID).Error
+	})
+}
+
+// Delete removes a tag together with its aliases and manga associations. Tags
+// are deleted permanently so that the name can be reused.
+func (r *tagRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM manga_tags WHERE tag_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("tag_id = ?", id).Delete(&models.TagAlias{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.Tag{}, id).Error
+	})
+}
+
+func (r *tagRepository) FindAliasByKey(key string) (*models.TagAlias, error) {
+	var alias models.TagAlias
+	err := r.db.Where("key = ?", key).First(&alias).Error
+	return &alias, err
+}
+
+func (r *tagRepository) FindAliasByID(id uint) (*models.TagAlias, error) {
+	var alias models.TagAlias
+	err := r.db.First(&alias, id).Error
+	return &alias, err
+}
+
+func (r *tagRepository) FindAliases(tagID uint) ([]models.TagAlias, error) {
+	var aliases []models.TagAlias
+	err := r.db.Where("tag_id = ?", tagID).Order("alias ASC").Find(&aliases).Error
+	return aliases, err
+}
+
+func (r *tagRepository) CreateAlias(alias *models.TagAlias) error {
+	return r.db.Create(alias).Error
+}
+
+func (r *tagRepository) DeleteAlias(id uint) error {
+	return r.db.Unscoped().Delete(&models.TagAlias{}, id).Error
+}
+
+// Merge folds the source tags into the target: manga associations and aliases
+// move to the target, the source names become aliases of it, and the source
+// tags are deleted. It runs in a single transaction.
+func (r *tagRepository) Merge(targetID uint, sourceIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var sources []models.Tag
+		if err := tx.Where("id IN ?", sourceIDs).Find(&sources).Error; err != nil {
+			return err
+		}
+		if len(sources) != len(sourceIDs) {
+			return gorm.ErrRecordNotFound
+		}
+
+		err := tx.Exec("INSERT INTO manga_tags (manga_id, tag_id) SELECT manga_id, ? FROM manga_tags WHERE tag_id IN ? ON CONFLICT DO NOTHING",
+			targetID, sourceIDs).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM manga_tags WHERE tag_id IN ?", sourceIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.TagAlias{}).Where("tag_id IN ?", sourceIDs).Update("tag_id", targetID).Error; err != nil {
+			return err
+		}
+
+		for _, source := range sources {
+			alias := models.TagAlias{TagID: targetID, Alias: source.Name, Key: TagKey(source.Name)}
+			if alias.Key == "" {
+				continue
+			}
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "key"}},
+				DoUpdates: clause.AssignmentColumns([]string{"tag_id"}),
+			}).Create(&alias).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Delete(&models.Tag{}, sourceIDs).Error
+	})
 }
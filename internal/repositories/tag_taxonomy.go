@@ -0,0 +1,77 @@
+package repositories
+
+import "github.com/sidler1/manga-backend/internal/models"
+
+// TagClass is the category and implied content rating of a tag.
+type TagClass struct {
+	Category      string
+	ContentRating string
+}
+
+// DefaultTagTaxonomy classifies well-known genre texts by their TagKey. Tags
+// not listed here are plain genres without a rating; admins can reclassify any
+// tag later.
+var DefaultTagTaxonomy = map[string]TagClass{
+	// Demographics
+	"shounen": {Category: models.TagCategoryDemographic},
+	"shonen":  {Category: models.TagCategoryDemographic},
+	"seinen":  {Category: models.TagCategoryDemographic},
+	"shoujo":  {Category: models.TagCategoryDemographic},
+	"shojo":   {Category: models.TagCategoryDemographic},
+	"josei":   {Category: models.TagCategoryDemographic},
+	"kodomo":  {Category: models.TagCategoryDemographic},
+
+	// Formats
+	"oneshot":    {Category: models.TagCategoryFormat},
+	"webtoon":    {Category: models.TagCategoryFormat},
+	"webtoons":   {Category: models.TagCategoryFormat},
+	"longstrip":  {Category: models.TagCategoryFormat},
+	"fullcolor":  {Category: models.TagCategoryFormat},
+	"doujinshi":  {Category: models.TagCategoryFormat},
+	"manhwa":     {Category: models.TagCategoryFormat},
+	"manhua":     {Category: models.TagCategoryFormat},
+	"4koma":      {Category: models.TagCategoryFormat},
+	"anthology":  {Category: models.TagCategoryFormat},
+	"adaptation": {Category: models.TagCategoryFormat},
+
+	// Themes
+	"isekai":          {Category: models.TagCategoryTheme},
+	"reincarnation":   {Category: models.TagCategoryTheme},
+	"timetravel":      {Category: models.TagCategoryTheme},
+	"harem":           {Category: models.TagCategoryTheme},
+	"reverseharem":    {Category: models.TagCategoryTheme},
+	"martialarts":     {Category: models.TagCategoryTheme},
+	"schoollife":      {Category: models.TagCategoryTheme},
+	"historical":      {Category: models.TagCategoryTheme},
+	"military":        {Category: models.TagCategoryTheme},
+	"cooking":         {Category: models.TagCategoryTheme},
+	"music":           {Category: models.TagCategoryTheme},
+	"vampires":        {Category: models.TagCategoryTheme},
+	"zombies":         {Category: models.TagCategoryTheme},
+	"monsters":        {Category: models.TagCategoryTheme},
+	"villainess":      {Category: models.TagCategoryTheme},
+	"survival":        {Category: models.TagCategoryTheme},
+	"postapocalyptic": {Category: models.TagCategoryTheme},
+	"magic":           {Category: models.TagCategoryTheme},
+
+	// Content warnings and ratings
+	"ecchi":          {Category: models.TagCategoryGenre, ContentRating: models.ContentRatingSuggestive},
+	"smut":           {Category: models.TagCategoryGenre, ContentRating: models.ContentRatingAdult},
+	"hentai":         {Category: models.TagCategoryGenre, ContentRating: models.ContentRatingAdult},
+	"mature":         {Category: models.TagCategoryContentWarning, ContentRating: models.ContentRatingAdult},
+	"adult":          {Category: models.TagCategoryContentWarning, ContentRating: models.ContentRatingAdult},
+	"pornographic":   {Category: models.TagCategoryContentWarning, ContentRating: models.ContentRatingAdult},
+	"sexualviolence": {Category: models.TagCategoryContentWarning, ContentRating: models.ContentRatingAdult},
+	"gore":           {Category: models.TagCategoryContentWarning},
+	"selfharm":       {Category: models.TagCategoryContentWarning},
+	"suicide":        {Category: models.TagCategoryContentWarning},
+}
+
+// ClassifyTag returns the default class of a tag key: its DefaultTagTaxonomy
+// entry, or an unrated genre.
+func ClassifyTag(key string) TagClass {
+	if class, ok := DefaultTagTaxonomy[key]; ok {
+		return class
+	}
+	return TagClass{Category: models.TagCategoryGenre}
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestTagKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{"Action", "action"},
+		{"Sci-fi", "scifi"},
+		{"Sci Fi", "scifi"},
+		{"  sci_fi!  ", "scifi"},
+		{"Boys' Love", "boyslove"},
+		{"4-Koma", "4koma"},
+		{"Isekai ", "isekai"},
+		{"Ecchi/エッチ", "ecchiエッチ"},
+		{"---", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.key, TagKey(tt.name), tt.name)
+	}
+}
+
+func TestTagRepository_FindAllWithUsage(t *testing.T) {
+	tests := []struct {
+		category string
+		vars     []interface{}
+	}{
+		{"", []interface{}{}},
+		{models.TagCategoryGenre, []interface{}{models.TagCategoryGenre}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.category, func(t *testing.T) {
+			db := dryRunDB(t)
+			var stmt *gorm.Statement
+			require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+				stmt = tx.Statement
+			}))
+			_, err := (&tagRepository{db: db}).FindAllWithUsage(tt.category)
+			require.NoError(t, err)
+
+			sql := stmt.SQL.String()
+			assert.Contains(t, sql, "tags.category, tags.content_rating, COUNT(manga_tags.manga_id)")
+			assert.Contains(t, sql, "GROUP BY tags.id, tags.name, tags.category, tags.content_rating")
+			assert.Equal(t, tt.category != "", strings.Contains(sql, "tags.category = $1"))
+			assert.Equal(t, tt.vars, stmt.Vars)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TelegramRepository interface {
+	CreateLinkCode(code *models.TelegramLinkCode) error
+	ConsumeLinkCode(code string, now time.Time) (*models.TelegramLinkCode, error)
+}
+
+type telegramRepository struct {
+	db *gorm.DB
+}
+
+func NewTelegramRepository(db *gorm.DB) TelegramRepository {
+	return &telegramRepository{db: db}
+}
+
+// CreateLinkCode stores a new code, dropping expired ones on the way.
+func (r *telegramRepository) CreateLinkCode(code *models.TelegramLinkCode) error {
+	if err := r.db.Where("expires_at <= ?", time.Now()).Delete(&models.TelegramLinkCode{}).Error; err != nil {
+		return err
+	}
+	return r.db.Create(code).Error
+}
+
+// ConsumeLinkCode deletes and returns a code that has not expired at now, so
+// each code links at most one chat.
+func (r *telegramRepository) ConsumeLinkCode(code string, now time.Time) (*models.TelegramLinkCode, error) {
+	var linkCode models.TelegramLinkCode
+	result := r.db.Clauses(clause.Returning{}).
+		Where("code = ? AND expires_at > ?", code, now).
+		Delete(&linkCode)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &linkCode, nil
+}
@@ -0,0 +1,166 @@
+package repositories
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// MangaSuggestion is a compact autocomplete entry.
+type MangaSuggestion struct {
+	ID            uint      `json:"id"`
+	Title         string    `json:"title"`
+	CoverURL      string    `json:"cover_url"`
+	ContentRating string    `json:"content_rating"`
+	Blurred       bool      `json:"blurred" gorm:"-"`
+	LatestChapter string    `json:"latest_chapter"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Score         float64   `json:"score"`
+}
+
+// AlternativeSource is a manga on another website that appears to be the
+// same series.
+type AlternativeSource struct {
+	ID            uint    `json:"id"`
+	Title         string  `json:"title"`
+	WebsiteID     uint    `json:"website_id"`
+	WebsiteName   string  `json:"website_name"`
+	ExternalURL   string  `json:"external_url"`
+	LatestChapter string  `json:"latest_chapter"`
+	Score         float64 `json:"score"`
+}
+
+// alternativeMinScore is the titleScoreExpr a manga on another website needs
+// to be suggested as the same series.
+const alternativeMinScore = 1.8
+
+// TitleSearchRepository performs typo-tolerant title matching using trigram
+// similarity over the normalized mangas.search_title column.
+type TitleSearchRepository interface {
+	Suggest(query string, limit int, hideAdult bool) ([]MangaSuggestion, error)
+	FuzzySearch(params MangaSearchParams) ([]MangaSearchResult, int, error)
+	FindAlternatives(manga *models.Manga, limit int) ([]AlternativeSource, error)
+}
+
+type titleSearchRepository struct {
+	db *gorm.DB
+}
+
+func NewTitleSearchRepository(db *gorm.DB) TitleSearchRepository {
+	return &titleSearchRepository{db: db}
+}
+
+// Suggest returns the best title matches for query in a single index-backed
+// query. Substring matches rank first, followed by trigram word similarity.
+func (r *titleSearchRepository) Suggest(query string, limit int, hideAdult bool) ([]MangaSuggestion, error) {
+	normalized := normalizeTitle(query)
+	suggestions := []MangaSuggestion{}
+	if normalized == "" {
+		return suggestions, nil
+	}
+	err := r.titleMatches(normalized, hideAdult).
+		Select("mangas.id, mangas.title, mangas.cover_url, mangas.content_rating, mangas.last_chapter AS latest_chapter, "+
+			"mangas.update_time AS updated_at, "+titleScoreExpr+" AS score", normalized, normalized).
+		Order("score DESC, mangas.update_time DESC").
+		Limit(limit).
+		Scan(&suggestions).Error
+	return suggestions, err
+}
+
+// FuzzySearch pages through title matches of params.Query ordered by
+// similarity. Only the query, paging and HideAdult are used.
+func (r *titleSearchRepository) FuzzySearch(params MangaSearchParams) ([]MangaSearchResult, int, error) {
+	page, limit := params.Page, params.Limit
+	normalized := normalizeTitle(params.Query)
+	if normalized == "" {
+		return []MangaSearchResult{}, 0, nil
+	}
+
+	var total int64
+	if err := r.titleMatches(normalized, params.HideAdult).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var hits []struct {
+		ID    uint
+		Score float64
+	}
+	err := r.titleMatches(normalized, params.HideAdult).
+		Select("mangas.id, "+titleScoreExpr+" AS score", normalized, normalized).
+		Order("score DESC, mangas.id ASC").
+		Offset((page - 1) * limit).Limit(limit).
+		Scan(&hits).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(hits) == 0 {
+		return []MangaSearchResult{}, int(total), nil
+	}
+
+	ids := make([]uint, len(hits))
+	for i, h := range hits {
+		ids[i] = h.ID
+	}
+	var mangas []models.Manga
+	if err := r.db.Preload("Tags").Preload("Website").Where("id IN ?", ids).Find(&mangas).Error; err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[uint]models.Manga, len(mangas))
+	for _, m := range mangas {
+		byID[m.ID] = m
+	}
+
+	results := make([]MangaSearchResult, 0, len(hits))
+	for _, h := range hits {
+		if m, ok := byID[h.ID]; ok {
+			results = append(results, MangaSearchResult{Manga: m, Rank: h.Score, TitleHighlight: m.Title})
+		}
+	}
+	return results, int(total), nil
+}
+
+// FindAlternatives returns mangas on other websites, still available there,
+// whose titles closely match the title of manga, best match first.
+func (r *titleSearchRepository) FindAlternatives(manga *models.Manga, limit int) ([]AlternativeSource, error) {
+	alternatives := []AlternativeSource{}
+	normalized := normalizeTitle(manga.Title)
+	if normalized == "" {
+		return alternatives, nil
+	}
+	err := r.titleMatches(normalized, false).
+		Select("mangas.id, mangas.title, mangas.website_id, websites.name AS website_name, mangas.external_url, "+
+			"mangas.last_chapter AS latest_chapter, "+titleScoreExpr+" AS score", normalized, normalized).
+		Joins("JOIN websites ON websites.id = mangas.website_id AND websites.deleted_at IS NULL").
+		Where("mangas.website_id <> ? AND mangas.removed_from_source_at IS NULL", manga.WebsiteID).
+		Where(titleScoreExpr+" >= ?", normalized, normalized, alternativeMinScore).
+		Order("score DESC, mangas.id ASC").
+		Limit(limit).
+		Scan(&alternatives).Error
+	return alternatives, err
+}
+
+// titleScoreExpr scores a match in [0, 2]: one point for containing the query
+// as a substring, plus its trigram word similarity. It takes the normalized
+// query twice.
+const titleScoreExpr = "(CASE WHEN strpos(mangas.search_title, ?) > 0 THEN 1 ELSE 0 END + word_similarity(?, mangas.search_title))"
+
+func (r *titleSearchRepository) titleMatches(normalized string, hideAdult bool) *gorm.DB {
+	query := r.db.Table("mangas").
+		Where("mangas.deleted_at IS NULL").
+		Where("(mangas.search_title LIKE ? OR ? <% mangas.search_title)", "%"+normalized+"%", normalized)
+	if hideAdult {
+		query = withoutAdult(query)
+	}
+	return query
+}
+
+// normalizeTitle mirrors the search_title trigger: lowercase, with every run of
+// characters other than letters and digits collapsed into a single space.
+func normalizeTitle(title string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), " ")
+}
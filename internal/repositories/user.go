@@ -10,8 +10,11 @@ type UserRepository interface {
 	FindFavorites(userID uint) ([]models.Manga, error)
 	Update(user *models.User) error
 	FindUsersByFavoriteManga(mangaID uint) ([]models.User, error)
+	FindUsersFollowingCreators(creatorIDs []uint) ([]models.User, error)
 	Create(user *models.User) error
 	FindByUsername(username string) (*models.User, error)
+	FindByCalendarToken(token string) (*models.User, error)
+	SetRole(usernames []string, role string) error
 }
 
 type userRepository struct {
@@ -30,7 +33,8 @@ func (r *userRepository) FindByID(id uint) (*models.User, error) {
 
 func (r *userRepository) FindFavorites(userID uint) ([]models.Manga, error) {
 	var favorites []models.Manga
-	err := r.db.Model(&models.User{}).Where("id = ?", userID).Association("Favorites").Find(&favorites)
+	user := models.User{Model: gorm.Model{ID: userID}}
+	err := r.db.Model(&user).Association("Favorites").Find(&favorites)
 	return favorites, err
 }
 
@@ -46,6 +50,17 @@ func (r *userRepository) FindUsersByFavoriteManga(mangaID uint) ([]models.User,
 	return users, err
 }
 
+// FindUsersFollowingCreators returns each user following any of the creators once.
+func (r *userRepository) FindUsersFollowingCreators(creatorIDs []uint) ([]models.User, error) {
+	var users []models.User
+	if len(creatorIDs) == 0 {
+		return users, nil
+	}
+	err := r.db.Where("id IN (SELECT user_id FROM user_followed_creators WHERE creator_id IN ?)", creatorIDs).
+		Find(&users).Error
+	return users, err
+}
+
 func (r *userRepository) Create(user *models.User) error {
 	return r.db.Create(user).Error
 }
@@ -55,3 +70,16 @@ func (r *userRepository) FindByUsername(username string) (*models.User, error) {
 	err := r.db.Where("username = ?", username).First(&user).Error
 	return &user, err
 }
+
+func (r *userRepository) FindByCalendarToken(token string) (*models.User, error) {
+	var user models.User
+	err := r.db.Where("calendar_token = ? AND calendar_token <> ''", token).First(&user).Error
+	return &user, err
+}
+
+func (r *userRepository) SetRole(usernames []string, role string) error {
+	if len(usernames) == 0 {
+		return nil
+	}
+	return r.db.Model(&models.User{}).Where("username IN ?", usernames).Update("role", role).Error
+}
@@ -1,8 +1,11 @@
 package repositories
 
 import (
+	"time"
+
 	"github.com/sidler1/manga-backend/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type WebsiteRepository interface {
@@ -10,7 +13,23 @@ type WebsiteRepository interface {
 	FindByID(id uint) (*models.Website, error)
 	FindAll() ([]models.Website, error)
 	Update(website *models.Website) error
+	UpdateLastChecked(id uint, checked time.Time) error
 	Delete(id uint) error
+	FindByURLWithDeleted(url string) (*models.Website, error)
+	Restore(website *models.Website) error
+	FindAliasByURL(url string) (*models.WebsiteAlias, error)
+	FindAliasByID(id uint) (*models.WebsiteAlias, error)
+	CreateAlias(alias *models.WebsiteAlias) error
+	DeleteAlias(id uint) error
+	Move(website *models.Website, to string) (*WebsiteMove, error)
+}
+
+// WebsiteMove reports the rows rewritten by moving a website to a new base URL.
+type WebsiteMove struct {
+	From            string `json:"from"`
+	To              string `json:"to"`
+	MangasUpdated   int64  `json:"mangas_updated"`
+	ChaptersUpdated int64  `json:"chapters_updated"`
 }
 
 type websiteRepository struct {
@@ -27,20 +46,96 @@ func (r *websiteRepository) Create(website *models.Website) error {
 
 func (r *websiteRepository) FindByID(id uint) (*models.Website, error) {
 	var website models.Website
-	err := r.db.First(&website, id).Error
+	err := r.db.Preload("Aliases").First(&website, id).Error
 	return &website, err
 }
 
 func (r *websiteRepository) FindAll() ([]models.Website, error) {
 	var websites []models.Website
-	err := r.db.Find(&websites).Error
+	err := r.db.Preload("Aliases").Find(&websites).Error
 	return websites, err
 }
 
 func (r *websiteRepository) Update(website *models.Website) error {
-	return r.db.Save(website).Error
+	return r.db.Omit(clause.Associations).Save(website).Error
 }
 
 func (r *websiteRepository) Delete(id uint) error {
 	return r.db.Delete(&models.Website{}, id).Error
 }
+
+// UpdateLastChecked only touches last_checked, so a scrape finishing does not
+// overwrite settings changed while it ran.
+func (r *websiteRepository) UpdateLastChecked(id uint, checked time.Time) error {
+	return r.db.Model(&models.Website{}).Where("id = ?", id).Update("last_checked", checked).Error
+}
+
+func (r *websiteRepository) FindByURLWithDeleted(url string) (*models.Website, error) {
+	var website models.Website
+	err := r.db.Unscoped().Where("url = ?", url).First(&website).Error
+	return &website, err
+}
+
+// Restore undeletes a soft-deleted website and saves its fields.
+func (r *websiteRepository) Restore(website *models.Website) error {
+	website.DeletedAt = gorm.DeletedAt{}
+	return r.db.Unscoped().Omit(clause.Associations).Save(website).Error
+}
+
+func (r *websiteRepository) FindAliasByURL(url string) (*models.WebsiteAlias, error) {
+	var alias models.WebsiteAlias
+	err := r.db.Where("url = ?", url).First(&alias).Error
+	return &alias, err
+}
+
+func (r *websiteRepository) FindAliasByID(id uint) (*models.WebsiteAlias, error) {
+	var alias models.WebsiteAlias
+	err := r.db.First(&alias, id).Error
+	return &alias, err
+}
+
+func (r *websiteRepository) CreateAlias(alias *models.WebsiteAlias) error {
+	return r.db.Create(alias).Error
+}
+
+func (r *websiteRepository) DeleteAlias(id uint) error {
+	return r.db.Unscoped().Delete(&models.WebsiteAlias{}, id).Error
+}
+
+// Move changes the base URL of website to to in a single transaction: the old
+// URL becomes an alias, and manga external URLs and chapter URLs starting
+// with it are rewritten to start with to.
+func (r *websiteRepository) Move(website *models.Website, to string) (*WebsiteMove, error) {
+	move := &WebsiteMove{From: website.URL, To: to}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("url = ?", to).Delete(&models.WebsiteAlias{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.WebsiteAlias{WebsiteID: website.ID, URL: move.From}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Website{}).Where("id = ?", website.ID).Update("url", to).Error; err != nil {
+			return err
+		}
+		// left() and substr() avoid LIKE, whose wildcards URLs may contain.
+		prefixLen := len([]rune(move.From))
+		result := tx.Exec("UPDATE mangas SET external_url = ? || substr(external_url, ?) WHERE website_id = ? AND left(external_url, ?) = ?",
+			to, prefixLen+1, website.ID, prefixLen, move.From)
+		if result.Error != nil {
+			return result.Error
+		}
+		move.MangasUpdated = result.RowsAffected
+		result = tx.Exec("UPDATE chapters SET url = ? || substr(url, ?) WHERE left(url, ?) = ? AND manga_id IN (SELECT id FROM mangas WHERE website_id = ?)",
+			to, prefixLen+1, prefixLen, move.From, website.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		move.ChaptersUpdated = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	website.URL = to
+	return move, nil
+}
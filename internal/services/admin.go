@@ -0,0 +1,393 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+)
+
+// ErrInvalidAdminInput is returned for edits that are missing required fields
+// or name unknown fields.
+var ErrInvalidAdminInput = errors.New("invalid input")
+
+// ErrDeleted is returned for edits of soft-deleted mangas and chapters, which
+// have to be restored first.
+var ErrDeleted = errors.New("deleted, restore it first")
+
+// Audited entity types.
+const (
+	AuditEntityManga   = "manga"
+	AuditEntityChapter = "chapter"
+)
+
+// LockableMangaFields are the metadata fields admins can lock so that scrapes
+// leave manual corrections alone.
+var LockableMangaFields = []string{"title", "alt_titles", "slug", "description", "external_url", "cover_url", "author", "status", "tags"}
+
+// MangaInput holds the manga fields of an admin create or edit; nil fields are
+// left as they are.
+type MangaInput struct {
+	Title        *string   `json:"title"`
+	AltTitles    *string   `json:"alt_titles"`
+	Slug         *string   `json:"slug"`
+	Description  *string   `json:"description"`
+	WebsiteID    *uint     `json:"website_id"`
+	ExternalURL  *string   `json:"external_url"`
+	CoverURL     *string   `json:"cover_url"`
+	Author       *string   `json:"author"`
+	Status       *string   `json:"status"`
+	LockedFields *[]string `json:"locked_fields"` // Replaces the set of locked fields
+	Lock         bool      `json:"lock"`          // Also lock every lockable field set in this input
+}
+
+// ChapterInput holds the chapter fields of an admin create or edit; nil fields
+// are left as they are.
+type ChapterInput struct {
+	Number      *uint      `json:"number"`
+	Title       *string    `json:"title"`
+	ReleaseDate *time.Time `json:"release_date"`
+	URL         *string    `json:"url"`
+}
+
+// AuditEntry is an audit log record with its changes decoded.
+type AuditEntry struct {
+	ID         uint            `json:"id"`
+	ActorID    uint            `json:"actor_id"`
+	EntityType string          `json:"entity_type"`
+	EntityID   uint            `json:"entity_id"`
+	Action     string          `json:"action"`
+	Changes    json.RawMessage `json:"changes"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// fieldChange is the before and after value of one audited field.
+type fieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+type AdminService interface {
+	CreateManga(actorID uint, input MangaInput) (*models.Manga, error)
+	UpdateManga(actorID, id uint, input MangaInput) (*models.Manga, error)
+	DeleteManga(actorID, id uint) error
+	RestoreManga(actorID, id uint) (*models.Manga, error)
+	CreateChapter(actorID, mangaID uint, input ChapterInput) (*models.Chapter, error)
+	UpdateChapter(actorID, id uint, input ChapterInput) (*models.Chapter, error)
+	DeleteChapter(actorID, id uint) error
+	RestoreChapter(actorID, id uint) (*models.Chapter, error)
+	GetAuditLog(filter repositories.AuditFilter) ([]AuditEntry, error)
+}
+
+type adminService struct {
+	mangaRepo   repositories.MangaRepository
+	chapterRepo repositories.ChapterRepository
+	websiteRepo repositories.WebsiteRepository
+	auditRepo   repositories.AuditRepository
+}
+
+func NewAdminService(mangaRepo repositories.MangaRepository, chapterRepo repositories.ChapterRepository, websiteRepo repositories.WebsiteRepository, auditRepo repositories.AuditRepository) AdminService {
+	return &adminService{
+		mangaRepo:   mangaRepo,
+		chapterRepo: chapterRepo,
+		websiteRepo: websiteRepo,
+		auditRepo:   auditRepo,
+	}
+}
+
+func (s *adminService) CreateManga(actorID uint, input MangaInput) (*models.Manga, error) {
+	if input.Title == nil || strings.TrimSpace(*input.Title) == "" {
+		return nil, fmt.Errorf("%w: title is required", ErrInvalidAdminInput)
+	}
+	if input.WebsiteID == nil {
+		return nil, fmt.Errorf("%w: website_id is required", ErrInvalidAdminInput)
+	}
+	manga := &models.Manga{UpdateTime: time.Now()}
+	if err := s.applyMangaInput(manga, input); err != nil {
+		return nil, err
+	}
+	if err := s.mangaRepo.Create(manga); err != nil {
+		return nil, err
+	}
+	return manga, s.audit(actorID, AuditEntityManga, manga.ID, models.AuditActionCreate, nil, mangaAuditFields(manga))
+}
+
+func (s *adminService) UpdateManga(actorID, id uint, input MangaInput) (*models.Manga, error) {
+	manga, err := s.mangaRepo.FindByIDWithDeleted(id)
+	if err != nil {
+		return nil, err
+	}
+	if manga.DeletedAt.Valid {
+		return nil, fmt.Errorf("manga %d is %w", id, ErrDeleted)
+	}
+	before := mangaAuditFields(manga)
+	if err := s.applyMangaInput(manga, input); err != nil {
+		return nil, err
+	}
+	if err := s.mangaRepo.Update(manga); err != nil {
+		return nil, err
+	}
+	return manga, s.audit(actorID, AuditEntityManga, id, models.AuditActionUpdate, before, mangaAuditFields(manga))
+}
+
+func (s *adminService) DeleteManga(actorID, id uint) error {
+	manga, err := s.mangaRepo.FindByIDWithDeleted(id)
+	if err != nil {
+		return err
+	}
+	if manga.DeletedAt.Valid {
+		return nil
+	}
+	if err := s.mangaRepo.Delete(id); err != nil {
+		return err
+	}
+	return s.audit(actorID, AuditEntityManga, id, models.AuditActionDelete, mangaAuditFields(manga), nil)
+}
+
+func (s *adminService) RestoreManga(actorID, id uint) (*models.Manga, error) {
+	if err := s.mangaRepo.Restore(id); err != nil {
+		return nil, err
+	}
+	manga, err := s.mangaRepo.FindByIDWithDeleted(id)
+	if err != nil {
+		return nil, err
+	}
+	return manga, s.audit(actorID, AuditEntityManga, id, models.AuditActionRestore, nil, mangaAuditFields(manga))
+}
+
+func (s *adminService) CreateChapter(actorID, mangaID uint, input ChapterInput) (*models.Chapter, error) {
+	manga, err := s.mangaRepo.FindByIDWithDeleted(mangaID)
+	if err != nil {
+		return nil, err
+	}
+	if manga.DeletedAt.Valid {
+		return nil, fmt.Errorf("manga %d is %w", mangaID, ErrDeleted)
+	}
+	if input.Number == nil {
+		return nil, fmt.Errorf("%w: number is required", ErrInvalidAdminInput)
+	}
+	chapter := &models.Chapter{MangaID: mangaID, ReleaseDate: time.Now()}
+	applyChapterInput(chapter, input)
+	if err := s.chapterRepo.Create(chapter); err != nil {
+		return nil, err
+	}
+	return chapter, s.audit(actorID, AuditEntityChapter, chapter.ID, models.AuditActionCreate, nil, chapterAuditFields(chapter))
+}
+
+func (s *adminService) UpdateChapter(actorID, id uint, input ChapterInput) (*models.Chapter, error) {
+	chapter, err := s.chapterRepo.FindByIDWithDeleted(id)
+	if err != nil {
+		return nil, err
+	}
+	if chapter.DeletedAt.Valid {
+		return nil, fmt.Errorf("chapter %d is %w", id, ErrDeleted)
+	}
+	before := chapterAuditFields(chapter)
+	applyChapterInput(chapter, input)
+	if err := s.chapterRepo.Update(chapter); err != nil {
+		return nil, err
+	}
+	return chapter, s.audit(actorID, AuditEntityChapter, id, models.AuditActionUpdate, before, chapterAuditFields(chapter))
+}
+
+func (s *adminService) DeleteChapter(actorID, id uint) error {
+	chapter, err := s.chapterRepo.FindByIDWithDeleted(id)
+	if err != nil {
+		return err
+	}
+	if chapter.DeletedAt.Valid {
+		return nil
+	}
+	if err := s.chapterRepo.Delete(id); err != nil {
+		return err
+	}
+	return s.audit(actorID, AuditEntityChapter, id, models.AuditActionDelete, chapterAuditFields(chapter), nil)
+}
+
+func (s *adminService) RestoreChapter(actorID, id uint) (*models.Chapter, error) {
+	if err := s.chapterRepo.Restore(id); err != nil {
+		return nil, err
+	}
+	chapter, err := s.chapterRepo.FindByIDWithDeleted(id)
+	if err != nil {
+		return nil, err
+	}
+	return chapter, s.audit(actorID, AuditEntityChapter, id, models.AuditActionRestore, nil, chapterAuditFields(chapter))
+}
+
+func (s *adminService) GetAuditLog(filter repositories.AuditFilter) ([]AuditEntry, error) {
+	logs, err := s.auditRepo.Find(filter)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]AuditEntry, len(logs))
+	for i, l := range logs {
+		entries[i] = AuditEntry{
+			ID:         l.ID,
+			ActorID:    l.ActorID,
+			EntityType: l.EntityType,
+			EntityID:   l.EntityID,
+			Action:     l.Action,
+			Changes:    json.RawMessage(l.Changes),
+			CreatedAt:  l.CreatedAt,
+		}
+	}
+	return entries, nil
+}
+
+// applyMangaInput copies the set fields of input onto manga and updates its
+// locked fields.
+func (s *adminService) applyMangaInput(manga *models.Manga, input MangaInput) error {
+	if input.WebsiteID != nil {
+		if _, err := s.websiteRepo.FindByID(*input.WebsiteID); err != nil {
+			return fmt.Errorf("%w: unknown website_id %d", ErrInvalidAdminInput, *input.WebsiteID)
+		}
+		manga.WebsiteID = *input.WebsiteID
+	}
+
+	locked := lockedFields(manga)
+	if input.LockedFields != nil {
+		locked = make(map[string]bool, len(*input.LockedFields))
+		for _, field := range *input.LockedFields {
+			if !isLockableMangaField(field) {
+				return fmt.Errorf("%w: field %q cannot be locked", ErrInvalidAdminInput, field)
+			}
+			locked[field] = true
+		}
+	}
+
+	fields := []struct {
+		name  string
+		value *string
+		dst   *string
+	}{
+		{"title", input.Title, &manga.Title},
+		{"alt_titles", input.AltTitles, &manga.AltTitles},
+		{"slug", input.Slug, &manga.Slug},
+		{"description", input.Description, &manga.Description},
+		{"external_url", input.ExternalURL, &manga.ExternalURL},
+		{"cover_url", input.CoverURL, &manga.CoverURL},
+		{"author", input.Author, &manga.Author},
+		{"status", input.Status, &manga.Status},
+	}
+	for _, f := range fields {
+		if f.value == nil {
+			continue
+		}
+		*f.dst = strings.TrimSpace(*f.value)
+		if input.Lock {
+			locked[f.name] = true
+		}
+	}
+
+	names := make([]string, 0, len(locked))
+	for name := range locked {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	manga.LockedFields = strings.Join(names, ",")
+	return nil
+}
+
+func applyChapterInput(chapter *models.Chapter, input ChapterInput) {
+	if input.Number != nil {
+		chapter.Number = *input.Number
+	}
+	if input.Title != nil {
+		chapter.Title = strings.TrimSpace(*input.Title)
+	}
+	if input.ReleaseDate != nil {
+		chapter.ReleaseDate = *input.ReleaseDate
+	}
+	if input.URL != nil {
+		chapter.URL = strings.TrimSpace(*input.URL)
+	}
+}
+
+// audit records a change. before is nil for creations and restores of
+// deleted entities, after is nil for deletions; only differing fields are kept.
+func (s *adminService) audit(actorID uint, entityType string, entityID uint, action string, before, after map[string]interface{}) error {
+	changes := make(map[string]fieldChange)
+	for field, to := range after {
+		from := before[field]
+		if !sameAuditValue(from, to) {
+			changes[field] = fieldChange{From: from, To: to}
+		}
+	}
+	for field, from := range before {
+		if _, ok := after[field]; !ok {
+			changes[field] = fieldChange{From: from}
+		}
+	}
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	return s.auditRepo.Create(&models.AuditLog{
+		ActorID:    actorID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Changes:    string(encoded),
+	})
+}
+
+func sameAuditValue(a, b interface{}) bool {
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := b.(time.Time); ok {
+			return ta.Equal(tb)
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func mangaAuditFields(m *models.Manga) map[string]interface{} {
+	return map[string]interface{}{
+		"title":         m.Title,
+		"alt_titles":    m.AltTitles,
+		"slug":          m.Slug,
+		"description":   m.Description,
+		"website_id":    m.WebsiteID,
+		"external_url":  m.ExternalURL,
+		"cover_url":     m.CoverURL,
+		"author":        m.Author,
+		"status":        m.Status,
+		"locked_fields": m.LockedFields,
+	}
+}
+
+func chapterAuditFields(c *models.Chapter) map[string]interface{} {
+	return map[string]interface{}{
+		"manga_id":     c.MangaID,
+		"number":       c.Number,
+		"title":        c.Title,
+		"release_date": c.ReleaseDate,
+		"url":          c.URL,
+	}
+}
+
+// lockedFields returns the set of fields a manga has locked against scrapes.
+func lockedFields(m *models.Manga) map[string]bool {
+	locked := make(map[string]bool)
+	for _, field := range strings.Split(m.LockedFields, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			locked[field] = true
+		}
+	}
+	return locked
+}
+
+func isLockableMangaField(field string) bool {
+	for _, f := range LockableMangaFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type recordingAuditRepo struct {
+	entries []models.AuditLog
+}
+
+func (r *recordingAuditRepo) Create(entry *models.AuditLog) error {
+	r.entries = append(r.entries, *entry)
+	return nil
+}
+
+func (r *recordingAuditRepo) Find(repositories.AuditFilter) ([]models.AuditLog, error) {
+	return r.entries, nil
+}
+
+func TestApplyMangaInput_LocksEditedFields(t *testing.T) {
+	s := &adminService{}
+	manga := &models.Manga{Title: "Old", LockedFields: "description"}
+	title := "  New Title "
+
+	err := s.applyMangaInput(manga, MangaInput{Title: &title, Lock: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "New Title", manga.Title)
+	assert.Equal(t, "description,title", manga.LockedFields)
+}
+
+func TestApplyMangaInput_ReplacesLockedFields(t *testing.T) {
+	s := &adminService{}
+	manga := &models.Manga{LockedFields: "description,title"}
+
+	assert.NoError(t, s.applyMangaInput(manga, MangaInput{LockedFields: &[]string{"cover_url"}}))
+	assert.Equal(t, "cover_url", manga.LockedFields)
+
+	err := s.applyMangaInput(manga, MangaInput{LockedFields: &[]string{"website_id"}})
+	assert.ErrorIs(t, err, ErrInvalidAdminInput)
+}
+
+func TestAudit_RecordsOnlyChangedFields(t *testing.T) {
+	auditRepo := &recordingAuditRepo{}
+	s := &adminService{auditRepo: auditRepo}
+	before := mangaAuditFields(&models.Manga{Title: "Old", Author: "Chugong"})
+	after := mangaAuditFields(&models.Manga{Title: "New", Author: "Chugong"})
+
+	assert.NoError(t, s.audit(7, AuditEntityManga, 3, models.AuditActionUpdate, before, after))
+
+	assert.Len(t, auditRepo.entries, 1)
+	entry := auditRepo.entries[0]
+	assert.Equal(t, uint(7), entry.ActorID)
+	assert.Equal(t, uint(3), entry.EntityID)
+	var changes map[string]fieldChange
+	assert.NoError(t, json.Unmarshal([]byte(entry.Changes), &changes))
+	assert.Equal(t, map[string]fieldChange{"title": {From: "Old", To: "New"}}, changes)
+}
+
+type deletedMangaRepo struct {
+	repositories.MangaRepository
+}
+
+func (deletedMangaRepo) FindByIDWithDeleted(id uint) (*models.Manga, error) {
+	manga := &models.Manga{}
+	manga.ID = id
+	manga.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	return manga, nil
+}
+
+type deletedChapterRepo struct {
+	repositories.ChapterRepository
+}
+
+func (deletedChapterRepo) FindByIDWithDeleted(id uint) (*models.Chapter, error) {
+	chapter := &models.Chapter{}
+	chapter.ID = id
+	chapter.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	return chapter, nil
+}
+
+func TestAdminService_RejectsEditsOfDeleted(t *testing.T) {
+	s := &adminService{mangaRepo: deletedMangaRepo{}, chapterRepo: deletedChapterRepo{}}
+	title := "New"
+	number := uint(5)
+
+	_, err := s.UpdateManga(1, 3, MangaInput{Title: &title})
+	assert.ErrorIs(t, err, ErrDeleted)
+	_, err = s.CreateChapter(1, 3, ChapterInput{Number: &number})
+	assert.ErrorIs(t, err, ErrDeleted)
+	_, err = s.UpdateChapter(1, 4, ChapterInput{Number: &number})
+	assert.ErrorIs(t, err, ErrDeleted)
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"github.com/sidler1/manga-backend/scraper"
+)
+
+const (
+	// sourceCheckInterval is how often each manga's source pages are checked.
+	sourceCheckInterval = 24 * time.Hour
+	// sourceCheckBatch caps the mangas checked per run.
+	sourceCheckBatch = 100
+	// removalThreshold is the number of consecutive checks that must find a
+	// manga gone before it is declared removed, so that a site hiccup or a
+	// page being moved briefly does not alarm anyone.
+	removalThreshold = 3
+	// maxAlternatives caps the alternative sources suggested for a manga.
+	maxAlternatives = 5
+)
+
+type AvailabilityService interface {
+	CheckDue() error
+	GetAlternatives(mangaID uint) ([]repositories.AlternativeSource, error)
+}
+
+type availabilityService struct {
+	mangaRepo           repositories.MangaRepository
+	chapterRepo         repositories.ChapterRepository
+	titleSearchRepo     repositories.TitleSearchRepository
+	notificationService NotificationService
+}
+
+func NewAvailabilityService(mangaRepo repositories.MangaRepository, chapterRepo repositories.ChapterRepository, titleSearchRepo repositories.TitleSearchRepository, notificationService NotificationService) AvailabilityService {
+	return &availabilityService{
+		mangaRepo:           mangaRepo,
+		chapterRepo:         chapterRepo,
+		titleSearchRepo:     titleSearchRepo,
+		notificationService: notificationService,
+	}
+}
+
+// CheckDue checks the source pages of the mangas checked longest ago. Errors
+// for a single manga are logged and do not stop the run.
+func (s *availabilityService) CheckDue() error {
+	now := time.Now()
+	mangas, err := s.mangaRepo.FindDueForSourceCheck(now.Add(-sourceCheckInterval), sourceCheckBatch)
+	if err != nil {
+		return err
+	}
+	for i := range mangas {
+		if err := s.check(&mangas[i], now); err != nil {
+			log.Printf("Error checking source of manga %d: %v", mangas[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// GetAlternatives lists other websites carrying the same series.
+func (s *availabilityService) GetAlternatives(mangaID uint) ([]repositories.AlternativeSource, error) {
+	manga, err := s.mangaRepo.FindByID(mangaID)
+	if err != nil {
+		return nil, err
+	}
+	return s.titleSearchRepo.FindAlternatives(manga, maxAlternatives)
+}
+
+// check fetches the manga page and latest chapter of manga and updates its
+// miss count. Only pages reported gone count as misses; an unreachable site
+// leaves the count alone. A manga found again is no longer marked removed.
+func (s *availabilityService) check(manga *models.Manga, now time.Time) error {
+	urls := []string{manga.ExternalURL}
+	if manga.ExternalURL == "" {
+		urls[0] = manga.Website.URL + "manga/" + manga.Slug + "/"
+	}
+	chapters, _, err := s.chapterRepo.FindPageByMangaID(manga.ID, repositories.PageRequest{Limit: 1})
+	if err != nil {
+		return err
+	}
+	// Chapter links stored before update scraping read them were built from
+	// the chapter label and never resolved.
+	if len(chapters) > 0 && chapters[0].URL != "" && !strings.ContainsAny(chapters[0].URL, " ") {
+		urls = append(urls, chapters[0].URL)
+	}
+
+	gone, reachable := false, true
+	for _, url := range urls {
+		switch err := scraper.CheckURL(url); {
+		case errors.Is(err, scraper.ErrNotFound):
+			gone = true
+		case err != nil:
+			reachable = false
+		}
+	}
+
+	fields := map[string]interface{}{"source_checked_at": now}
+	removed := false
+	switch {
+	case gone:
+		fields["source_misses"] = manga.SourceMisses + 1
+		if manga.SourceMisses+1 >= removalThreshold && manga.RemovedFromSourceAt == nil {
+			fields["removed_from_source_at"] = now
+			removed = true
+		}
+	case reachable:
+		fields["source_misses"] = 0
+		fields["removed_from_source_at"] = nil
+	}
+	if err := s.mangaRepo.UpdateFields(manga.ID, fields); err != nil {
+		return err
+	}
+	if !removed {
+		return nil
+	}
+
+	alternatives, err := s.titleSearchRepo.FindAlternatives(manga, maxAlternatives)
+	if err != nil {
+		log.Printf("Error finding alternatives for manga %d: %v", manga.ID, err)
+	}
+	return s.notificationService.SendRemovedNotification(manga, alternatives)
+}
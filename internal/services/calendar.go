@@ -0,0 +1,209 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+)
+
+const (
+	// The iCalendar feed covers a fixed window around the time it is fetched.
+	feedLookBack  = 30 * 24 * time.Hour
+	feedLookAhead = 60 * 24 * time.Hour
+)
+
+// CalendarEntry is a single actual or estimated release of a favorite manga.
+type CalendarEntry struct {
+	MangaID      uint      `json:"manga_id"`
+	MangaTitle   string    `json:"manga_title"`
+	ChapterID    uint      `json:"chapter_id,omitempty"`
+	Chapter      uint      `json:"chapter,omitempty"`
+	ChapterTitle string    `json:"chapter_title,omitempty"`
+	URL          string    `json:"url,omitempty"`
+	Date         time.Time `json:"date"`
+	Estimated    bool      `json:"estimated"`
+}
+
+type CalendarService interface {
+	GetCalendar(userID uint, from, to time.Time) ([]CalendarEntry, error)
+	RotateFeedToken(userID uint) (string, error)
+	RenderFeed(token string) (string, error)
+}
+
+type calendarService struct {
+	userRepo    repositories.UserRepository
+	chapterRepo repositories.ChapterRepository
+}
+
+func NewCalendarService(userRepo repositories.UserRepository, chapterRepo repositories.ChapterRepository) CalendarService {
+	return &calendarService{
+		userRepo:    userRepo,
+		chapterRepo: chapterRepo,
+	}
+}
+
+// GetCalendar lists released chapters and estimated next releases of the
+// user's favorites within [from, to), ordered by date.
+func (s *calendarService) GetCalendar(userID uint, from, to time.Time) ([]CalendarEntry, error) {
+	policy, err := loadContentPolicy(s.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	favorites, err := s.userRepo.FindFavorites(userID)
+	if err != nil {
+		return nil, err
+	}
+	favorites = policy.filterMangas(favorites)
+
+	titles := make(map[uint]string, len(favorites))
+	mangaIDs := make([]uint, 0, len(favorites))
+	for _, m := range favorites {
+		titles[m.ID] = m.Title
+		mangaIDs = append(mangaIDs, m.ID)
+	}
+
+	chapters, err := s.chapterRepo.FindReleasedBetween(mangaIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]CalendarEntry, 0, len(chapters)+len(favorites))
+	for _, c := range chapters {
+		entries = append(entries, CalendarEntry{
+			MangaID:      c.MangaID,
+			MangaTitle:   titles[c.MangaID],
+			ChapterID:    c.ID,
+			Chapter:      c.Number,
+			ChapterTitle: c.Title,
+			URL:          c.URL,
+			Date:         c.ReleaseDate,
+		})
+	}
+	for _, m := range favorites {
+		if !expectsReleases(m.ReleaseState) || m.EstimatedNext.IsZero() {
+			continue
+		}
+		if m.EstimatedNext.Before(from) || !m.EstimatedNext.Before(to) {
+			continue
+		}
+		entries = append(entries, CalendarEntry{
+			MangaID:    m.ID,
+			MangaTitle: m.Title,
+			URL:        m.ExternalURL,
+			Date:       m.EstimatedNext,
+			Estimated:  true,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Date.Before(entries[j].Date) })
+	return entries, nil
+}
+
+// RotateFeedToken issues a new secret feed token, invalidating the previous one.
+func (s *calendarService) RotateFeedToken(userID uint) (string, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return "", err
+	}
+	token, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	user.CalendarToken = token
+	if err := s.userRepo.Update(user); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// RenderFeed renders the iCalendar feed of the user owning token.
+func (s *calendarService) RenderFeed(token string) (string, error) {
+	user, err := s.userRepo.FindByCalendarToken(token)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	entries, err := s.GetCalendar(user.ID, now.Add(-feedLookBack), now.Add(feedLookAhead))
+	if err != nil {
+		return "", err
+	}
+	return renderICS(entries, now), nil
+}
+
+// expectsReleases reports whether a manga in the given release state should get
+// an estimated release on the calendar.
+func expectsReleases(state string) bool {
+	return state == "" || state == models.ReleaseStateOngoing
+}
+
+func randomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// renderICS renders entries as an RFC 5545 calendar. Actual releases become
+// timed events, estimates become all-day events.
+func renderICS(entries []CalendarEntry, now time.Time) string {
+	var b strings.Builder
+	writeICSLine(&b, "BEGIN:VCALENDAR")
+	writeICSLine(&b, "VERSION:2.0")
+	writeICSLine(&b, "PRODID:-//manga-backend//release calendar//EN")
+	writeICSLine(&b, "CALSCALE:GREGORIAN")
+	writeICSLine(&b, "METHOD:PUBLISH")
+	writeICSLine(&b, "X-WR-CALNAME:Manga releases")
+
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, e := range entries {
+		writeICSLine(&b, "BEGIN:VEVENT")
+		if e.Estimated {
+			day := e.Date.UTC()
+			writeICSLine(&b, fmt.Sprintf("UID:estimate-%d-%s@manga-backend", e.MangaID, day.Format("20060102")))
+			writeICSLine(&b, "DTSTART;VALUE=DATE:"+day.Format("20060102"))
+			writeICSLine(&b, "DTEND;VALUE=DATE:"+day.AddDate(0, 0, 1).Format("20060102"))
+			writeICSLine(&b, "SUMMARY:"+escapeICSText(e.MangaTitle+" (expected)"))
+			writeICSLine(&b, "TRANSP:TRANSPARENT")
+		} else {
+			writeICSLine(&b, fmt.Sprintf("UID:chapter-%d@manga-backend", e.ChapterID))
+			writeICSLine(&b, "DTSTART:"+e.Date.UTC().Format("20060102T150405Z"))
+			writeICSLine(&b, "SUMMARY:"+escapeICSText(fmt.Sprintf("%s – Chapter %d", e.MangaTitle, e.Chapter)))
+		}
+		writeICSLine(&b, "DTSTAMP:"+stamp)
+		if e.URL != "" {
+			writeICSLine(&b, "URL:"+e.URL)
+		}
+		writeICSLine(&b, "END:VEVENT")
+	}
+	writeICSLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+// writeICSLine writes a content line terminated by CRLF, folding it at 75
+// octets without splitting UTF-8 sequences.
+func writeICSLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74 // continuation lines start with a space
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func escapeICSText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderICS(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	entries := []CalendarEntry{
+		{MangaID: 1, MangaTitle: "Solo Leveling", ChapterID: 7, Chapter: 42, Date: now.Add(-time.Hour), URL: "https://example.com/ch42"},
+		{MangaID: 2, MangaTitle: "Frieren, Beyond Journey's End", Date: now.Add(48 * time.Hour), Estimated: true},
+	}
+
+	feed := renderICS(entries, now)
+
+	assert.True(t, strings.HasPrefix(feed, "BEGIN:VCALENDAR\r\n"))
+	assert.True(t, strings.HasSuffix(feed, "END:VCALENDAR\r\n"))
+	assert.Equal(t, 2, strings.Count(feed, "BEGIN:VEVENT"))
+	assert.Contains(t, feed, "UID:chapter-7@manga-backend\r\n")
+	assert.Contains(t, feed, "DTSTART:20250310T110000Z\r\n")
+	assert.Contains(t, feed, "DTSTART;VALUE=DATE:20250312\r\n")
+	assert.Contains(t, feed, `SUMMARY:Frieren\, Beyond Journey's End (expected)`)
+}
+
+func TestWriteICSLine_Folds(t *testing.T) {
+	var b strings.Builder
+	writeICSLine(&b, "SUMMARY:"+strings.Repeat("é", 100))
+
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+	unfolded := strings.ReplaceAll(b.String(), "\r\n ", "")
+	assert.Equal(t, "SUMMARY:"+strings.Repeat("é", 100)+"\r\n", unfolded)
+}
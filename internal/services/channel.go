@@ -0,0 +1,181 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"github.com/sidler1/manga-backend/notifier"
+	"gorm.io/gorm"
+)
+
+// ErrUnknownChannel is returned for channels no notifier is registered for.
+var ErrUnknownChannel = errors.New("unknown notification channel")
+
+// IntegrationUserID owns the admin webhooks: subscriptions receiving events
+// about every manga rather than a user's favorites and follows.
+const IntegrationUserID uint = 0
+
+// ChannelInput holds the fields of a channel subscription. Channel is only
+// read when subscribing; on updates nil fields are left as they are.
+type ChannelInput struct {
+	Channel string  `json:"channel"`
+	Target  *string `json:"target"`
+	Config  *string `json:"config"`
+	Enabled *bool   `json:"enabled"`
+}
+
+type ChannelService interface {
+	ListChannels() []string
+	GetSubscriptions(userID uint) ([]models.ChannelSubscription, error)
+	GetSubscription(userID, id uint) (*models.ChannelSubscription, error)
+	Subscribe(userID uint, input ChannelInput) (*models.ChannelSubscription, error)
+	UpdateSubscription(userID, id uint, input ChannelInput) (*models.ChannelSubscription, error)
+	Unsubscribe(userID, id uint) error
+	UnsubscribeByToken(token string) error
+	SendTest(userID, id uint) error
+}
+
+type channelService struct {
+	channelRepo repositories.ChannelRepository
+	userRepo    repositories.UserRepository
+	signer      *UnsubscribeSigner
+}
+
+func NewChannelService(channelRepo repositories.ChannelRepository, userRepo repositories.UserRepository, signer *UnsubscribeSigner) ChannelService {
+	return &channelService{channelRepo: channelRepo, userRepo: userRepo, signer: signer}
+}
+
+func (s *channelService) ListChannels() []string {
+	return NotifierChannels()
+}
+
+func (s *channelService) GetSubscriptions(userID uint) ([]models.ChannelSubscription, error) {
+	return s.channelRepo.FindByUserID(userID)
+}
+
+func (s *channelService) GetSubscription(userID, id uint) (*models.ChannelSubscription, error) {
+	return s.find(userID, id)
+}
+
+// Subscribe sets up delivery of the user's notifications over a channel. The
+// channel's notifier validates the destination first. Email subscriptions go
+// to the user's account address. Admin webhooks are subscribed with
+// IntegrationUserID and default to the webhook channel.
+func (s *channelService) Subscribe(userID uint, input ChannelInput) (*models.ChannelSubscription, error) {
+	if userID == IntegrationUserID && input.Channel == "" {
+		input.Channel = notifier.ChannelWebhook
+	}
+	subscription := &models.ChannelSubscription{UserID: userID, Channel: strings.TrimSpace(input.Channel), Enabled: true}
+	if err := s.apply(subscription, input); err != nil {
+		return nil, err
+	}
+	return subscription, s.channelRepo.Create(subscription)
+}
+
+func (s *channelService) UpdateSubscription(userID, id uint, input ChannelInput) (*models.ChannelSubscription, error) {
+	subscription, err := s.find(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(subscription, input); err != nil {
+		return nil, err
+	}
+	return subscription, s.channelRepo.Update(subscription)
+}
+
+func (s *channelService) Unsubscribe(userID, id uint) error {
+	if _, err := s.find(userID, id); err != nil {
+		return err
+	}
+	return s.channelRepo.Delete(id)
+}
+
+// UnsubscribeByToken follows a signed unsubscribe link: it mutes the manga
+// the token names on its subscription, or disables the whole subscription.
+func (s *channelService) UnsubscribeByToken(token string) error {
+	subscriptionID, mangaID, err := s.signer.Parse(token)
+	if err != nil {
+		return err
+	}
+	subscription, err := s.channelRepo.FindByID(subscriptionID)
+	if err != nil {
+		return err
+	}
+	if mangaID != 0 {
+		return s.channelRepo.Mute(subscription.ID, mangaID)
+	}
+	subscription.Enabled = false
+	return s.channelRepo.Update(subscription)
+}
+
+// SendTest delivers a test message over the subscription right away, so
+// users can check their setup. Delivery errors are returned as they are.
+func (s *channelService) SendTest(userID, id uint) error {
+	subscription, err := s.find(userID, id)
+	if err != nil {
+		return err
+	}
+	n, ok := GetNotifier(subscription.Channel)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownChannel, subscription.Channel)
+	}
+	return n.Send(notifier.Destination{Target: subscription.Target, Config: subscription.Config}, notifier.Message{
+		Type:   notifier.MessageTypeTest,
+		Title:  "Test notification",
+		Body:   "Notifications over this channel are working.",
+		SentAt: time.Now(),
+	})
+}
+
+// find returns a subscription of the user; other users' subscriptions are
+// reported as not found.
+func (s *channelService) find(userID, id uint) (*models.ChannelSubscription, error) {
+	subscription, err := s.channelRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if subscription.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return subscription, nil
+}
+
+func (s *channelService) apply(subscription *models.ChannelSubscription, input ChannelInput) error {
+	n, ok := GetNotifier(subscription.Channel)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownChannel, subscription.Channel)
+	}
+	if subscription.UserID == IntegrationUserID && subscription.Channel != notifier.ChannelWebhook {
+		return fmt.Errorf("%w: admin integrations are webhooks", ErrUnknownChannel)
+	}
+	if subscription.Channel == notifier.ChannelEmail {
+		// Only the account's own address is mailed, so that subscriptions
+		// cannot be used to send mail to others.
+		user, err := s.userRepo.FindByID(subscription.UserID)
+		if err != nil {
+			return err
+		}
+		if input.Target != nil && strings.TrimSpace(*input.Target) != "" && !strings.EqualFold(strings.TrimSpace(*input.Target), user.Email) {
+			return fmt.Errorf("%w: email notifications are sent to the account's email address", notifier.ErrInvalidDestination)
+		}
+		input.Target = &user.Email
+	}
+	if input.Target != nil {
+		target := strings.TrimSpace(*input.Target)
+		if subscription.Channel == notifier.ChannelTelegram && target != subscription.Target {
+			return fmt.Errorf("%w: Telegram chats are linked by sending the bot a link code", notifier.ErrInvalidDestination)
+		}
+		subscription.Target = target
+	}
+	if input.Config != nil {
+		subscription.Config = *input.Config
+	}
+	if input.Enabled != nil {
+		subscription.Enabled = *input.Enabled
+	}
+	return n.Validate(notifier.Destination{Target: subscription.Target, Config: subscription.Config})
+}
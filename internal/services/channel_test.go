@@ -0,0 +1,71 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"github.com/sidler1/manga-backend/notifier"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type channelUserRepo struct {
+	repositories.UserRepository
+}
+
+func (channelUserRepo) FindByID(id uint) (*models.User, error) {
+	return &models.User{Email: "reader@example.com"}, nil
+}
+
+type createChannelRepo struct {
+	repositories.ChannelRepository
+}
+
+func (createChannelRepo) Create(*models.ChannelSubscription) error {
+	return nil
+}
+
+func TestChannelService_Subscribe_EmailGoesToAccount(t *testing.T) {
+	RegisterNotifier(notifier.NewEmailNotifier(notifier.SMTPConfig{}))
+	s := NewChannelService(createChannelRepo{}, channelUserRepo{}, nil)
+	target := func(s string) *string { return &s }
+
+	tests := []struct {
+		name   string
+		target *string
+		err    bool
+	}{
+		{"no target", nil, false},
+		{"empty target", target(" "), false},
+		{"account address", target("Reader@Example.com"), false},
+		{"other address", target("victim@example.com"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := s.Subscribe(1, ChannelInput{Channel: notifier.ChannelEmail, Target: tt.target})
+			if tt.err {
+				assert.ErrorIs(t, err, notifier.ErrInvalidDestination)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "reader@example.com", sub.Target)
+		})
+	}
+}
+
+func TestChannelService_Subscribe_IntegrationsAreWebhooks(t *testing.T) {
+	RegisterNotifier(notifier.NewWebhookNotifier())
+	RegisterNotifier(notifier.NewEmailNotifier(notifier.SMTPConfig{}))
+	s := NewChannelService(createChannelRepo{}, channelUserRepo{}, nil)
+	target := "https://hooks.example.com/manga"
+	config := `{"format": "discord"}`
+
+	sub, err := s.Subscribe(IntegrationUserID, ChannelInput{Target: &target, Config: &config})
+	require.NoError(t, err)
+	assert.Equal(t, notifier.ChannelWebhook, sub.Channel)
+	assert.Equal(t, IntegrationUserID, sub.UserID)
+
+	_, err = s.Subscribe(IntegrationUserID, ChannelInput{Channel: notifier.ChannelEmail})
+	assert.ErrorIs(t, err, ErrUnknownChannel)
+}
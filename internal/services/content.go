@@ -0,0 +1,77 @@
+package services
+
+import (
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+)
+
+// contentPolicy is how a viewer wants mangas rated adult to be treated.
+type contentPolicy struct {
+	hide bool // Leave them out of results
+	blur bool // Return them flagged as blurred
+}
+
+// loadContentPolicy reads the adult content preference of a user. Unknown
+// users and unset preferences hide adult content.
+func loadContentPolicy(userRepo repositories.UserRepository, userID uint) (contentPolicy, error) {
+	if userID == 0 {
+		return contentPolicy{hide: true}, nil
+	}
+	user, err := userRepo.FindByID(userID)
+	if err != nil {
+		return contentPolicy{}, err
+	}
+	switch user.AdultContent {
+	case models.AdultContentShow:
+		return contentPolicy{}, nil
+	case models.AdultContentBlur:
+		return contentPolicy{blur: true}, nil
+	default:
+		return contentPolicy{hide: true}, nil
+	}
+}
+
+func (p contentPolicy) blurs(rating string) bool {
+	return p.blur && rating == models.ContentRatingAdult
+}
+
+func (p contentPolicy) applySummaries(summaries []repositories.MangaSummary) {
+	for i := range summaries {
+		summaries[i].Blurred = p.blurs(summaries[i].ContentRating)
+	}
+}
+
+func (p contentPolicy) applySearchResults(results []repositories.MangaSearchResult) {
+	for i := range results {
+		results[i].Blurred = p.blurs(results[i].Manga.ContentRating)
+	}
+}
+
+func (p contentPolicy) applySuggestions(suggestions []repositories.MangaSuggestion) {
+	for i := range suggestions {
+		suggestions[i].Blurred = p.blurs(suggestions[i].ContentRating)
+	}
+}
+
+// filterMangas drops mangas rated adult when the policy hides them.
+func (p contentPolicy) filterMangas(mangas []models.Manga) []models.Manga {
+	if !p.hide {
+		return mangas
+	}
+	visible := make([]models.Manga, 0, len(mangas))
+	for _, m := range mangas {
+		if m.ContentRating != models.ContentRatingAdult {
+			visible = append(visible, m)
+		}
+	}
+	return visible
+}
+
+// IsValidAdultContentMode reports whether mode is an adult content preference.
+func IsValidAdultContentMode(mode string) bool {
+	switch mode {
+	case models.AdultContentHide, models.AdultContentBlur, models.AdultContentShow:
+		return true
+	}
+	return false
+}
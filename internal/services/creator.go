@@ -0,0 +1,117 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+)
+
+// CreatorProfile is a creator with the works crediting them.
+type CreatorProfile struct {
+	models.Creator
+	Works     []repositories.CreatorWork `json:"works"`
+	Following bool                       `json:"following"`
+}
+
+type CreatorService interface {
+	GetCreator(viewerID, id uint) (*CreatorProfile, error)
+	Follow(userID, creatorID uint) error
+	Unfollow(userID, creatorID uint) error
+	GetFollowed(userID uint) ([]models.Creator, error)
+	CreditManga(mangaID uint, authors, artists []string) ([]models.Creator, error)
+}
+
+type creatorService struct {
+	creatorRepo repositories.CreatorRepository
+	userRepo    repositories.UserRepository
+}
+
+func NewCreatorService(creatorRepo repositories.CreatorRepository, userRepo repositories.UserRepository) CreatorService {
+	return &creatorService{
+		creatorRepo: creatorRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// GetCreator returns a creator and their works as seen by viewerID, whose
+// adult content preference is applied to the works.
+func (s *creatorService) GetCreator(viewerID, id uint) (*CreatorProfile, error) {
+	creator, err := s.creatorRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := loadContentPolicy(s.userRepo, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	works, err := s.creatorRepo.FindWorks(id, policy.hide)
+	if err != nil {
+		return nil, err
+	}
+	for i := range works {
+		works[i].Blurred = policy.blurs(works[i].ContentRating)
+	}
+
+	profile := &CreatorProfile{Creator: *creator, Works: works}
+	if viewerID != 0 {
+		followed, err := s.creatorRepo.FindFollowed(viewerID)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range followed {
+			if c.ID == id {
+				profile.Following = true
+				break
+			}
+		}
+	}
+	return profile, nil
+}
+
+func (s *creatorService) Follow(userID, creatorID uint) error {
+	if _, err := s.creatorRepo.FindByID(creatorID); err != nil {
+		return err
+	}
+	return s.creatorRepo.Follow(userID, creatorID)
+}
+
+func (s *creatorService) Unfollow(userID, creatorID uint) error {
+	return s.creatorRepo.Unfollow(userID, creatorID)
+}
+
+func (s *creatorService) GetFollowed(userID uint) ([]models.Creator, error) {
+	return s.creatorRepo.FindFollowed(userID)
+}
+
+// CreditManga credits the named authors and artists on a manga, creating
+// creators as needed, and returns each credited creator once.
+func (s *creatorService) CreditManga(mangaID uint, authors, artists []string) ([]models.Creator, error) {
+	var credited []models.Creator
+	seen := make(map[uint]bool)
+	var errs []error
+	credit := func(names []string, role string) {
+		for _, name := range names {
+			if strings.TrimSpace(name) == "" {
+				continue
+			}
+			creator, err := s.creatorRepo.FindOrCreate(name)
+			if err == nil {
+				err = s.creatorRepo.Credit(mangaID, creator.ID, role)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s %q: %w", role, name, err))
+				continue
+			}
+			if !seen[creator.ID] {
+				seen[creator.ID] = true
+				credited = append(credited, *creator)
+			}
+		}
+	}
+	credit(authors, models.CreditRoleAuthor)
+	credit(artists, models.CreditRoleArtist)
+	return credited, errors.Join(errs...)
+}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"github.com/sidler1/manga-backend/notifier"
+)
+
+const (
+	// maxDeliveryAttempts is the number of attempts before a delivery fails.
+	maxDeliveryAttempts = 10
+	// deliveryBatch caps the deliveries sent per dispatcher run.
+	deliveryBatch = 200
+	// deliveryBackoffBase and deliveryBackoffMax bound the exponential wait
+	// between attempts: 1m, 2m, 4m, ... up to 6h.
+	deliveryBackoffBase = time.Minute
+	deliveryBackoffMax  = 6 * time.Hour
+)
+
+type DeliveryService interface {
+	Enqueue(notification *models.Notification) error
+	Broadcast(notification *models.Notification) error
+	DispatchDue() error
+	ListDeliveries(filter repositories.DeliveryFilter) ([]models.NotificationDelivery, error)
+	RetryDelivery(id uint) (*models.NotificationDelivery, error)
+	GetSettings(userID uint) (*NotificationSettings, error)
+	UpdateSettings(userID uint, settings NotificationSettings) (*NotificationSettings, error)
+}
+
+type deliveryService struct {
+	deliveryRepo     repositories.DeliveryRepository
+	channelRepo      repositories.ChannelRepository
+	userRepo         repositories.UserRepository
+	mangaRepo        repositories.MangaRepository
+	notificationRepo repositories.NotificationRepository
+	signer           *UnsubscribeSigner
+	publicURL        string // Base of unsubscribe links; none are added when empty
+}
+
+func NewDeliveryService(deliveryRepo repositories.DeliveryRepository, channelRepo repositories.ChannelRepository, userRepo repositories.UserRepository, mangaRepo repositories.MangaRepository, notificationRepo repositories.NotificationRepository, signer *UnsubscribeSigner, publicURL string) DeliveryService {
+	return &deliveryService{
+		deliveryRepo:     deliveryRepo,
+		channelRepo:      channelRepo,
+		userRepo:         userRepo,
+		mangaRepo:        mangaRepo,
+		notificationRepo: notificationRepo,
+		signer:           signer,
+		publicURL:        publicURL,
+	}
+}
+
+// Enqueue schedules a notification for delivery over each enabled channel
+// subscription of its user whose event filter, if any, accepts it. Sending
+// happens in DispatchDue, right away or, for users receiving digests, with
+// the next digest, and after the user's quiet hours. While the user paused
+// notifications deliveries are held, and UpdateSettings schedules them when
+// notifications are resumed.
+func (s *deliveryService) Enqueue(notification *models.Notification) error {
+	user, err := s.userRepo.FindByID(notification.UserID)
+	if err != nil {
+		return err
+	}
+	status, nextAttemptAt := models.DeliveryStatusPending, notification.SentAt
+	switch {
+	case user.NotificationsPaused:
+		status = models.DeliveryStatusHeld
+	case user.DigestMode != "" && user.DigestMode != models.DigestModeInstant:
+		status, nextAttemptAt = models.DeliveryStatusHeld, nextDigestTime(user, notification.SentAt)
+	}
+	nextAttemptAt = deferUntil(user, nextAttemptAt)
+
+	subscriptions, err := s.acceptingSubscriptions(notification.UserID, notification)
+	if err != nil {
+		return err
+	}
+	return s.queue(notification, subscriptions, status, nextAttemptAt)
+}
+
+// Broadcast stores notification as an event owned by IntegrationUserID and
+// queues it for every admin webhook accepting it. Nothing is stored when no
+// webhook does. Events are stored read, so they are purged with read
+// notifications.
+func (s *deliveryService) Broadcast(notification *models.Notification) error {
+	subscriptions, err := s.acceptingSubscriptions(IntegrationUserID, notification)
+	if err != nil || len(subscriptions) == 0 {
+		return err
+	}
+	notification.UserID = IntegrationUserID
+	notification.Read = true
+	notification.ReadAt = &notification.SentAt
+	if err := s.notificationRepo.Create(notification); err != nil {
+		return err
+	}
+	return s.queue(notification, subscriptions, models.DeliveryStatusPending, notification.SentAt)
+}
+
+// acceptingSubscriptions returns the enabled subscriptions of userID that
+// have not muted the notification's manga and whose event filter, if any,
+// accepts its type.
+func (s *deliveryService) acceptingSubscriptions(userID uint, notification *models.Notification) ([]models.ChannelSubscription, error) {
+	subscriptions, err := s.channelRepo.FindEnabledForManga(userID, notification.MangaID)
+	if err != nil {
+		return nil, err
+	}
+	accepting := subscriptions[:0]
+	for _, sub := range subscriptions {
+		n, ok := GetNotifier(sub.Channel)
+		if !ok {
+			continue
+		}
+		if filter, ok := n.(notifier.EventFilter); ok && !filter.Accepts(notifier.Destination{Target: sub.Target, Config: sub.Config}, notification.Type) {
+			continue
+		}
+		accepting = append(accepting, sub)
+	}
+	return accepting, nil
+}
+
+// queue creates a delivery of notification over each subscription.
+func (s *deliveryService) queue(notification *models.Notification, subscriptions []models.ChannelSubscription, status string, nextAttemptAt time.Time) error {
+	deliveries := make([]models.NotificationDelivery, len(subscriptions))
+	for i, sub := range subscriptions {
+		deliveries[i] = models.NotificationDelivery{
+			NotificationID: notification.ID,
+			SubscriptionID: sub.ID,
+			Channel:        sub.Channel,
+			Status:         status,
+			NextAttemptAt:  nextAttemptAt,
+		}
+	}
+	return s.deliveryRepo.Create(deliveries)
+}
+
+// DispatchDue attempts every pending delivery that is due, then sends the
+// digests that are due. Failed attempts are retried with exponential backoff
+// until maxDeliveryAttempts or a permanent error. Subscriptions whose
+// destination is gone are removed. Deliveries of users in their quiet hours
+// or with paused notifications, including retries, are put off instead.
+func (s *deliveryService) DispatchDue() error {
+	now := time.Now()
+	deliveries, err := s.deliveryRepo.FindDue(now, deliveryBatch)
+	if err != nil {
+		return err
+	}
+	users := map[uint]*models.User{}
+	for i := range deliveries {
+		d := &deliveries[i]
+		if until := s.deferredUntil(users, d.Subscription.UserID, now); until.After(now) {
+			d.NextAttemptAt = until
+			if err := s.deliveryRepo.Update(d); err != nil {
+				log.Printf("Error saving delivery %d: %v", d.ID, err)
+			}
+			continue
+		}
+		sendErr := s.send(d)
+		recordAttempt(d, sendErr, now)
+		if err := s.deliveryRepo.Update(d); err != nil {
+			log.Printf("Error saving delivery %d: %v", d.ID, err)
+		}
+		if errors.Is(sendErr, notifier.ErrGone) {
+			s.pruneSubscription(d.SubscriptionID)
+		}
+	}
+	return s.dispatchDigests(now, users)
+}
+
+// deferredUntil returns when deliveries to userID may be sent, now unless
+// the user's settings put them off. users caches the users of a run.
+func (s *deliveryService) deferredUntil(users map[uint]*models.User, userID uint, now time.Time) time.Time {
+	if userID == IntegrationUserID {
+		return now // An admin webhook, or a removed subscription whose attempt fails permanently
+	}
+	user, ok := users[userID]
+	if !ok {
+		var err error
+		if user, err = s.userRepo.FindByID(userID); err != nil {
+			log.Printf("Error loading user %d: %v", userID, err)
+			return now
+		}
+		users[userID] = user
+	}
+	return deferUntil(user, now)
+}
+
+// pruneSubscription removes a subscription whose destination is gone, such
+// as the push subscription of an uninstalled browser.
+func (s *deliveryService) pruneSubscription(id uint) {
+	if err := s.channelRepo.Delete(id); err != nil {
+		log.Printf("Error removing subscription %d: %v", id, err)
+	}
+}
+
+func (s *deliveryService) ListDeliveries(filter repositories.DeliveryFilter) ([]models.NotificationDelivery, error) {
+	return s.deliveryRepo.Find(filter)
+}
+
+// RetryDelivery schedules a delivery for an immediate attempt with a fresh
+// attempt budget.
+func (s *deliveryService) RetryDelivery(id uint) (*models.NotificationDelivery, error) {
+	d, err := s.deliveryRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	d.Status = models.DeliveryStatusPending
+	d.Attempts = 0
+	d.NextAttemptAt = time.Now()
+	return d, s.deliveryRepo.Update(d)
+}
+
+func (s *deliveryService) send(d *models.NotificationDelivery) error {
+	if d.Subscription.ID == 0 || !d.Subscription.Enabled {
+		return fmt.Errorf("%w: subscription removed or disabled", notifier.ErrPermanent)
+	}
+	n, ok := GetNotifier(d.Channel)
+	if !ok {
+		// The channel may be configured again after a restart.
+		return fmt.Errorf("channel %q is not configured", d.Channel)
+	}
+	dest := notifier.Destination{Target: d.Subscription.Target, Config: d.Subscription.Config}
+	msg := renderMessage(&d.Notification)
+	msg.UnsubscribeURL = s.unsubscribeURL(d.SubscriptionID, 0)
+	if d.Notification.MangaID != 0 {
+		msg.MangaUnsubscribeURL = s.unsubscribeURL(d.SubscriptionID, d.Notification.MangaID)
+	}
+	return n.Send(dest, msg)
+}
+
+// unsubscribeURL returns the signed link stopping the subscription, or only
+// the manga on it. It is empty without a public URL to link to.
+func (s *deliveryService) unsubscribeURL(subscriptionID, mangaID uint) string {
+	if s.publicURL == "" {
+		return ""
+	}
+	return s.publicURL + "/api/v1/unsubscribe/" + s.signer.Token(subscriptionID, mangaID)
+}
+
+// recordAttempt updates a delivery with the outcome of an attempt at now.
+func recordAttempt(d *models.NotificationDelivery, err error, now time.Time) {
+	d.Attempts++
+	if err == nil {
+		d.Status = models.DeliveryStatusSent
+		d.SentAt = &now
+		d.LastError = ""
+		return
+	}
+	d.LastError = err.Error()
+	if errors.Is(err, notifier.ErrPermanent) || d.Attempts >= maxDeliveryAttempts {
+		d.Status = models.DeliveryStatusFailed
+		return
+	}
+	d.NextAttemptAt = now.Add(deliveryBackoff(d.Attempts))
+}
+
+// deliveryBackoff returns the wait after the given number of failed attempts.
+func deliveryBackoff(attempts int) time.Duration {
+	wait := deliveryBackoffBase
+	for i := 1; i < attempts && wait < deliveryBackoffMax; i++ {
+		wait *= 2
+	}
+	if wait > deliveryBackoffMax {
+		wait = deliveryBackoffMax
+	}
+	return wait
+}
+
+// renderMessage turns a notification into a channel message.
+func renderMessage(n *models.Notification) notifier.Message {
+	title := "Manga update"
+	switch n.Type {
+	case models.NotificationTypeChapter:
+		title = "New chapter"
+	case models.NotificationTypeNewWork:
+		title = "New work"
+	case models.NotificationTypeRemoved:
+		title = "Removed from source"
+	case models.NotificationTypeState:
+		title = "Release state changed"
+	}
+	chapter, _ := chapterNumber(n.Chapter)
+	return notifier.Message{Type: n.Type, Title: title, Body: n.Message, URL: n.URL, MangaID: n.MangaID, Chapter: chapter, Priority: n.Priority, SentAt: n.SentAt}
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"github.com/sidler1/manga-backend/notifier"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestDeliveryBackoff(t *testing.T) {
+	assert.Equal(t, time.Minute, deliveryBackoff(1))
+	assert.Equal(t, 2*time.Minute, deliveryBackoff(2))
+	assert.Equal(t, 8*time.Minute, deliveryBackoff(4))
+	assert.Equal(t, deliveryBackoffMax, deliveryBackoff(20))
+}
+
+func TestRecordAttempt(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	d := &models.NotificationDelivery{Status: models.DeliveryStatusPending}
+	recordAttempt(d, errors.New("timeout"), now)
+	assert.Equal(t, models.DeliveryStatusPending, d.Status)
+	assert.Equal(t, 1, d.Attempts)
+	assert.Equal(t, "timeout", d.LastError)
+	assert.Equal(t, now.Add(time.Minute), d.NextAttemptAt)
+
+	recordAttempt(d, nil, now)
+	assert.Equal(t, models.DeliveryStatusSent, d.Status)
+	assert.Equal(t, &now, d.SentAt)
+	assert.Empty(t, d.LastError)
+
+	d = &models.NotificationDelivery{Status: models.DeliveryStatusPending}
+	recordAttempt(d, fmt.Errorf("%w: gone", notifier.ErrPermanent), now)
+	assert.Equal(t, models.DeliveryStatusFailed, d.Status)
+
+	d = &models.NotificationDelivery{Status: models.DeliveryStatusPending, Attempts: maxDeliveryAttempts - 1}
+	recordAttempt(d, errors.New("timeout"), now)
+	assert.Equal(t, models.DeliveryStatusFailed, d.Status)
+}
+
+type dueDeliveryRepo struct {
+	repositories.DeliveryRepository
+	due, held   []models.NotificationDelivery
+	updated     []models.NotificationDelivery
+	rescheduled bool
+}
+
+func (r *dueDeliveryRepo) FindDue(now time.Time, limit int) ([]models.NotificationDelivery, error) {
+	return r.due, nil
+}
+
+func (r *dueDeliveryRepo) FindDueHeld(now time.Time, limit int) ([]models.NotificationDelivery, error) {
+	return r.held, nil
+}
+
+func (r *dueDeliveryRepo) Update(delivery *models.NotificationDelivery) error {
+	r.updated = append(r.updated, *delivery)
+	return nil
+}
+
+func (r *dueDeliveryRepo) RescheduleHeld(userID uint, status string, nextAttemptAt time.Time) error {
+	r.rescheduled = true
+	return nil
+}
+
+type settingsUserRepo struct {
+	repositories.UserRepository
+	user models.User
+}
+
+func (r *settingsUserRepo) FindByID(id uint) (*models.User, error) {
+	user := r.user
+	return &user, nil
+}
+
+func (r *settingsUserRepo) Update(user *models.User) error {
+	r.user = *user
+	return nil
+}
+
+func TestDispatchDue_DefersPausedAndQuietUsers(t *testing.T) {
+	now := time.Now().UTC()
+	quiet := models.User{QuietHoursStart: now.Add(-time.Hour).Format("15:04"), QuietHoursEnd: now.Add(time.Hour).Format("15:04")}
+	tests := []struct {
+		name  string
+		user  models.User
+		after time.Time
+	}{
+		{"paused", models.User{NotificationsPaused: true}, now.Add(pausedRecheck - time.Minute)},
+		{"quiet hours", quiet, now.Add(time.Hour - 2*time.Minute)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := models.ChannelSubscription{UserID: 7, Channel: "unconfigured", Enabled: true}
+			sub.ID = 1
+			delivery := models.NotificationDelivery{SubscriptionID: 1, Subscription: sub, Status: models.DeliveryStatusPending, Attempts: 2}
+			held := delivery
+			held.Status = models.DeliveryStatusHeld
+			repo := &dueDeliveryRepo{due: []models.NotificationDelivery{delivery}, held: []models.NotificationDelivery{held, held}}
+			s := &deliveryService{deliveryRepo: repo, userRepo: &settingsUserRepo{user: tt.user}}
+
+			require.NoError(t, s.DispatchDue())
+
+			require.Len(t, repo.updated, 3)
+			for _, d := range repo.updated {
+				assert.Equal(t, 2, d.Attempts, "no attempt is made")
+				assert.Empty(t, d.LastError)
+				assert.True(t, d.NextAttemptAt.After(tt.after), d.NextAttemptAt)
+			}
+			assert.Equal(t, models.DeliveryStatusPending, repo.updated[0].Status)
+			assert.Equal(t, models.DeliveryStatusHeld, repo.updated[1].Status)
+		})
+	}
+}
+
+func TestUpdateSettings_LeavesHeldDeliveriesWhilePaused(t *testing.T) {
+	repo := &dueDeliveryRepo{}
+	s := &deliveryService{deliveryRepo: repo, userRepo: &settingsUserRepo{}}
+	settings := NotificationSettings{Mode: models.DigestModeInstant, Time: "08:00", Timezone: "UTC", Paused: true}
+
+	_, err := s.UpdateSettings(7, settings)
+	require.NoError(t, err)
+	assert.False(t, repo.rescheduled)
+
+	settings.Paused = false
+	_, err = s.UpdateSettings(7, settings)
+	require.NoError(t, err)
+	assert.True(t, repo.rescheduled)
+}
+
+type integrationChannelRepo struct {
+	repositories.ChannelRepository
+	subscriptions []models.ChannelSubscription
+}
+
+func (r integrationChannelRepo) FindEnabledForManga(userID, mangaID uint) ([]models.ChannelSubscription, error) {
+	var found []models.ChannelSubscription
+	for _, sub := range r.subscriptions {
+		if sub.UserID == userID {
+			found = append(found, sub)
+		}
+	}
+	return found, nil
+}
+
+type createdNotificationRepo struct {
+	repositories.NotificationRepository
+	created []models.Notification
+}
+
+func (r *createdNotificationRepo) Create(notification *models.Notification) error {
+	notification.ID = uint(len(r.created) + 1)
+	r.created = append(r.created, *notification)
+	return nil
+}
+
+type createdDeliveryRepo struct {
+	repositories.DeliveryRepository
+	created []models.NotificationDelivery
+}
+
+func (r *createdDeliveryRepo) Create(deliveries []models.NotificationDelivery) error {
+	r.created = append(r.created, deliveries...)
+	return nil
+}
+
+func TestDeliveryService_Broadcast(t *testing.T) {
+	RegisterNotifier(notifier.NewWebhookNotifier())
+	channels := integrationChannelRepo{subscriptions: []models.ChannelSubscription{
+		{Model: gorm.Model{ID: 1}, UserID: IntegrationUserID, Channel: notifier.ChannelWebhook, Config: `{"format": "slack", "events": ["chapter"]}`},
+		{Model: gorm.Model{ID: 2}, UserID: IntegrationUserID, Channel: notifier.ChannelWebhook, Config: `{"format": "slack"}`},
+		{Model: gorm.Model{ID: 3}, UserID: 7, Channel: notifier.ChannelWebhook, Config: `{"format": "slack"}`},
+	}}
+	notifications, deliveries := &createdNotificationRepo{}, &createdDeliveryRepo{}
+	s := &deliveryService{channelRepo: channels, notificationRepo: notifications, deliveryRepo: deliveries}
+
+	sentAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, s.Broadcast(&models.Notification{MangaID: 5, Type: models.NotificationTypeState, SentAt: sentAt}))
+	require.Len(t, notifications.created, 1)
+	event := notifications.created[0]
+	assert.Equal(t, IntegrationUserID, event.UserID)
+	assert.True(t, event.Read, "events are purged like read notifications")
+	require.Len(t, deliveries.created, 1, "the chapter-only webhook and user webhooks are skipped")
+	assert.Equal(t, uint(2), deliveries.created[0].SubscriptionID)
+	assert.Equal(t, models.DeliveryStatusPending, deliveries.created[0].Status)
+	assert.Equal(t, sentAt, deliveries.created[0].NextAttemptAt)
+
+	s.channelRepo = integrationChannelRepo{}
+	require.NoError(t, s.Broadcast(&models.Notification{MangaID: 5, Type: models.NotificationTypeChapter, SentAt: sentAt}))
+	assert.Len(t, notifications.created, 1, "nothing is stored without admin webhooks")
+}
+
+func TestDeliveryService_Enqueue_HoldsWhilePaused(t *testing.T) {
+	RegisterNotifier(notifier.NewWebhookNotifier())
+	channels := integrationChannelRepo{subscriptions: []models.ChannelSubscription{
+		{Model: gorm.Model{ID: 1}, UserID: 7, Channel: notifier.ChannelWebhook, Config: `{"format": "slack"}`},
+	}}
+	deliveries := &createdDeliveryRepo{}
+	users := &settingsUserRepo{user: models.User{Model: gorm.Model{ID: 7}, NotificationsPaused: true}}
+	s := &deliveryService{channelRepo: channels, deliveryRepo: deliveries, userRepo: users}
+
+	sentAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, s.Enqueue(&models.Notification{UserID: 7, MangaID: 5, Type: models.NotificationTypeChapter, SentAt: sentAt}))
+	require.Len(t, deliveries.created, 1, "the notification is kept for later")
+	assert.Equal(t, models.DeliveryStatusHeld, deliveries.created[0].Status)
+	assert.Equal(t, sentAt.Add(pausedRecheck), deliveries.created[0].NextAttemptAt)
+
+	users.user.NotificationsPaused = false
+	require.NoError(t, s.Enqueue(&models.Notification{UserID: 7, MangaID: 5, Type: models.NotificationTypeChapter, SentAt: sentAt}))
+	require.Len(t, deliveries.created, 2)
+	assert.Equal(t, models.DeliveryStatusPending, deliveries.created[1].Status)
+	assert.Equal(t, sentAt, deliveries.created[1].NextAttemptAt)
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"github.com/sidler1/manga-backend/notifier"
+)
+
+const (
+	// digestBatch caps the digests, i.e. subscriptions with held deliveries,
+	// sent per dispatcher run.
+	digestBatch = 200
+	// maxDigestItems and maxDigestSize cap the entries of a digest and the
+	// bytes of their text and links, keeping digests within the 4096
+	// characters of a Telegram message and the 4096 bytes of a push message.
+	// Further entries are summed up as "…and N more".
+	maxDigestItems = 30
+	maxDigestSize  = 3000
+)
+
+// nextDigestTime returns when the user's next digest after now is sent:
+// at the top of the next hour, or at the next DigestTime in the user's
+// timezone, on DigestWeekday for weekly digests.
+func nextDigestTime(user *models.User, now time.Time) time.Time {
+	if user.DigestMode == models.DigestModeHourly {
+		return now.Truncate(time.Hour).Add(time.Hour)
+	}
+	loc := userLocation(user)
+	at, err := time.Parse("15:04", user.DigestTime)
+	if err != nil {
+		at = time.Date(0, 1, 1, 8, 0, 0, 0, time.UTC)
+	}
+
+	local := now.In(loc)
+	next := time.Date(local.Year(), local.Month(), local.Day(), at.Hour(), at.Minute(), 0, 0, loc)
+	if user.DigestMode == models.DigestModeWeekly {
+		days := (user.DigestWeekday - int(next.Weekday()) + 7) % 7
+		next = next.AddDate(0, 0, days)
+		if !next.After(now) {
+			next = next.AddDate(0, 0, 7)
+		}
+		return next
+	}
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// dispatchDigests sends the held deliveries that are due as one digest per
+// subscription. The deliveries of a digest share its outcome, or are put off
+// together like in DispatchDue.
+func (s *deliveryService) dispatchDigests(now time.Time, users map[uint]*models.User) error {
+	deliveries, err := s.deliveryRepo.FindDueHeld(now, digestBatch)
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(deliveries); {
+		end := start + 1
+		for end < len(deliveries) && deliveries[end].SubscriptionID == deliveries[start].SubscriptionID {
+			end++
+		}
+		group := deliveries[start:end]
+		start = end
+
+		if until := s.deferredUntil(users, group[0].Subscription.UserID, now); until.After(now) {
+			for i := range group {
+				group[i].NextAttemptAt = until
+				if err := s.deliveryRepo.Update(&group[i]); err != nil {
+					log.Printf("Error saving delivery %d: %v", group[i].ID, err)
+				}
+			}
+			continue
+		}
+		sendErr := s.sendDigest(group)
+		for i := range group {
+			recordAttempt(&group[i], sendErr, now)
+			if err := s.deliveryRepo.Update(&group[i]); err != nil {
+				log.Printf("Error saving delivery %d: %v", group[i].ID, err)
+			}
+		}
+		if errors.Is(sendErr, notifier.ErrGone) {
+			s.pruneSubscription(group[0].SubscriptionID)
+		}
+	}
+	return nil
+}
+
+// sendDigest sends the deliveries of one subscription together. A single
+// delivery is sent as a regular message.
+func (s *deliveryService) sendDigest(group []models.NotificationDelivery) error {
+	if len(group) == 1 {
+		return s.send(&group[0])
+	}
+	sub := group[0].Subscription
+	if sub.ID == 0 || !sub.Enabled {
+		return fmt.Errorf("%w: subscription removed or disabled", notifier.ErrPermanent)
+	}
+	n, ok := GetNotifier(group[0].Channel)
+	if !ok {
+		return fmt.Errorf("channel %q is not configured", group[0].Channel)
+	}
+	msg, err := s.renderDigest(group)
+	if err != nil {
+		return err
+	}
+	return n.Send(notifier.Destination{Target: sub.Target, Config: sub.Config}, msg)
+}
+
+// renderDigest builds the digest of a subscription's notifications. New
+// chapters of one manga are collapsed into one entry, e.g. "Solo Leveling:
+// Ch. 101–104"; other notifications keep their own entry.
+func (s *deliveryService) renderDigest(group []models.NotificationDelivery) (notifier.Message, error) {
+	var mangaIDs []uint
+	chapters := map[uint][]*models.Notification{}
+	for i := range group {
+		n := &group[i].Notification
+		if n.Type != models.NotificationTypeChapter || n.MangaID == 0 {
+			continue
+		}
+		if _, ok := chapters[n.MangaID]; !ok {
+			mangaIDs = append(mangaIDs, n.MangaID)
+		}
+		chapters[n.MangaID] = append(chapters[n.MangaID], n)
+	}
+	titles := map[uint]string{}
+	if len(mangaIDs) > 0 {
+		mangas, err := s.mangaRepo.FindByIDs(mangaIDs, repositories.MangaIncludes{})
+		if err != nil {
+			return notifier.Message{}, err
+		}
+		for _, m := range mangas {
+			titles[m.ID] = m.Title
+		}
+	}
+
+	subscriptionID := group[0].SubscriptionID
+	msg := notifier.Message{
+		Type:           notifier.MessageTypeDigest,
+		Title:          digestTitle(s.digestMode(group[0].Subscription.UserID)),
+		SentAt:         group[len(group)-1].Notification.SentAt,
+		UnsubscribeURL: s.unsubscribeURL(subscriptionID, 0),
+	}
+	for i := range group {
+		n := &group[i].Notification
+		item := renderMessage(n)
+		if notes, ok := chapters[n.MangaID]; ok && n.Type == models.NotificationTypeChapter {
+			if notes[0] != n {
+				continue // Collapsed into the manga's first entry
+			}
+			labels := make([]string, len(notes))
+			for j, note := range notes {
+				labels[j] = note.Chapter
+			}
+			title, ok := titles[n.MangaID]
+			if !ok {
+				title = "Manga"
+			}
+			item.Body = title + ": " + chapterRanges(labels)
+			item.URL = notes[len(notes)-1].URL
+			for _, note := range notes {
+				item.Priority = max(item.Priority, note.Priority)
+			}
+		}
+		if n.MangaID != 0 {
+			item.MangaUnsubscribeURL = s.unsubscribeURL(subscriptionID, n.MangaID)
+		}
+		msg.Priority = max(msg.Priority, item.Priority)
+		msg.Items = append(msg.Items, item)
+	}
+	msg.Items = capDigestItems(msg.Items)
+	bodies := make([]string, len(msg.Items))
+	for i, item := range msg.Items {
+		bodies[i] = item.Body
+	}
+	msg.Body = strings.Join(bodies, "\n")
+	return msg, nil
+}
+
+// capDigestItems keeps the first entries of a digest within maxDigestItems
+// and maxDigestSize, replacing the rest with an entry counting them.
+func capDigestItems(items []notifier.Message) []notifier.Message {
+	size := 0
+	for i, item := range items {
+		size += len(item.Body) + len(item.URL) + 1
+		if i == maxDigestItems || size > maxDigestSize {
+			return append(items[:i:i], notifier.Message{Body: fmt.Sprintf("…and %d more", len(items)-i)})
+		}
+	}
+	return items
+}
+
+// digestMode returns the user's digest mode for the digest title. Failing
+// to load the user only makes the title less specific.
+func (s *deliveryService) digestMode(userID uint) string {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return ""
+	}
+	return user.DigestMode
+}
+
+func digestTitle(mode string) string {
+	switch mode {
+	case models.DigestModeHourly, models.DigestModeDaily, models.DigestModeWeekly:
+		return "Your " + mode + " manga digest"
+	}
+	return "Your manga digest"
+}
+
+// chapterRanges collapses chapter labels into ranges of consecutive numbers,
+// e.g. "Chapter 101" to "Chapter 104" and "Chapter 106" into
+// "Ch. 101–104, 106". Labels without a whole chapter number are counted.
+func chapterRanges(labels []string) string {
+	numbers := make([]uint, 0, len(labels))
+	for _, label := range labels {
+		n, ok := chapterNumber(label)
+		if !ok {
+			if len(labels) == 1 {
+				return label
+			}
+			return strconv.Itoa(len(labels)) + " new chapters"
+		}
+		numbers = append(numbers, n)
+	}
+	slices.Sort(numbers)
+	numbers = slices.Compact(numbers)
+
+	var ranges []string
+	for i := 0; i < len(numbers); {
+		j := i
+		for j+1 < len(numbers) && numbers[j+1] == numbers[j]+1 {
+			j++
+		}
+		r := strconv.FormatUint(uint64(numbers[i]), 10)
+		if j > i {
+			r += "–" + strconv.FormatUint(uint64(numbers[j]), 10)
+		}
+		ranges = append(ranges, r)
+		i = j + 1
+	}
+	return "Ch. " + strings.Join(ranges, ", ")
+}
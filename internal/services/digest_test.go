@@ -0,0 +1,113 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"github.com/sidler1/manga-backend/notifier"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestNextDigestTime(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+	now := time.Date(2025, 3, 5, 10, 30, 0, 0, time.UTC) // Wednesday, 11:30 in Berlin
+
+	hourly := &models.User{DigestMode: models.DigestModeHourly}
+	assert.Equal(t, time.Date(2025, 3, 5, 11, 0, 0, 0, time.UTC), nextDigestTime(hourly, now))
+
+	daily := &models.User{DigestMode: models.DigestModeDaily, DigestTime: "18:00", Timezone: "Europe/Berlin"}
+	assert.True(t, time.Date(2025, 3, 5, 18, 0, 0, 0, berlin).Equal(nextDigestTime(daily, now)))
+	daily.DigestTime = "08:00" // Already passed today
+	assert.True(t, time.Date(2025, 3, 6, 8, 0, 0, 0, berlin).Equal(nextDigestTime(daily, now)))
+
+	weekly := &models.User{DigestMode: models.DigestModeWeekly, DigestTime: "09:00", DigestWeekday: 1, Timezone: "Europe/Berlin"}
+	assert.True(t, time.Date(2025, 3, 10, 9, 0, 0, 0, berlin).Equal(nextDigestTime(weekly, now)))
+	weekly.DigestWeekday, weekly.DigestTime = 3, "12:00" // Later today
+	assert.True(t, time.Date(2025, 3, 5, 12, 0, 0, 0, berlin).Equal(nextDigestTime(weekly, now)))
+
+	invalid := &models.User{DigestMode: models.DigestModeDaily, DigestTime: "late", Timezone: "Nowhere/City"}
+	assert.Equal(t, time.Date(2025, 3, 6, 8, 0, 0, 0, time.UTC), nextDigestTime(invalid, now))
+}
+
+func TestChapterRanges(t *testing.T) {
+	assert.Equal(t, "Ch. 101–104", chapterRanges([]string{"Chapter 103", "Chapter 101", "Chapter 104", "Chapter 102"}))
+	assert.Equal(t, "Ch. 5, 7–8", chapterRanges([]string{"Chapter 5", "Chapter 7", "Chapter 8", "Chapter 8"}))
+	assert.Equal(t, "Ch. 12", chapterRanges([]string{"Chapter 12"}))
+	assert.Equal(t, "Chapter 12.5", chapterRanges([]string{"Chapter 12.5"}))
+	assert.Equal(t, "2 new chapters", chapterRanges([]string{"Chapter 12", "Extra"}))
+}
+
+type digestMangaRepo struct {
+	repositories.MangaRepository
+}
+
+func (r *digestMangaRepo) FindByIDs(ids []uint, include repositories.MangaIncludes) ([]models.Manga, error) {
+	return []models.Manga{{Model: gorm.Model{ID: 3}, Title: "Solo Leveling"}}, nil
+}
+
+type digestUserRepo struct {
+	repositories.UserRepository
+}
+
+func (r *digestUserRepo) FindByID(id uint) (*models.User, error) {
+	return &models.User{DigestMode: models.DigestModeDaily}, nil
+}
+
+func TestRenderDigest(t *testing.T) {
+	s := &deliveryService{mangaRepo: &digestMangaRepo{}, userRepo: &digestUserRepo{}}
+	delivery := func(n models.Notification) models.NotificationDelivery {
+		return models.NotificationDelivery{SubscriptionID: 1, Notification: n}
+	}
+	group := []models.NotificationDelivery{
+		delivery(models.Notification{MangaID: 3, Type: models.NotificationTypeChapter, Chapter: "Chapter 101", Priority: notifier.PriorityDefault}),
+		delivery(models.Notification{MangaID: 4, Type: models.NotificationTypeNewWork, Message: "New work by Chugong: Tower", Priority: notifier.PriorityLow}),
+		delivery(models.Notification{MangaID: 3, Type: models.NotificationTypeChapter, Chapter: "Chapter 102", URL: "https://example.com/solo", Priority: notifier.PriorityHigh}),
+	}
+
+	msg, err := s.renderDigest(group)
+	require.NoError(t, err)
+
+	assert.Equal(t, notifier.MessageTypeDigest, msg.Type)
+	assert.Equal(t, "Your daily manga digest", msg.Title)
+	assert.Equal(t, notifier.PriorityHigh, msg.Priority)
+	require.Len(t, msg.Items, 2)
+	assert.Equal(t, "Solo Leveling: Ch. 101–102", msg.Items[0].Body)
+	assert.Equal(t, "https://example.com/solo", msg.Items[0].URL)
+	assert.Equal(t, "New work by Chugong: Tower", msg.Items[1].Body)
+	assert.Equal(t, "Solo Leveling: Ch. 101–102\nNew work by Chugong: Tower", msg.Body)
+}
+
+func TestRenderDigest_Capped(t *testing.T) {
+	s := &deliveryService{mangaRepo: &digestMangaRepo{}, userRepo: &digestUserRepo{}}
+	group := make([]models.NotificationDelivery, 300)
+	for i := range group {
+		group[i] = models.NotificationDelivery{SubscriptionID: 1, Notification: models.Notification{
+			MangaID: uint(100 + i),
+			Type:    models.NotificationTypeNewWork,
+			Message: fmt.Sprintf("New work by Chugong: The Tower of Many Floors, volume %d", i),
+			URL:     fmt.Sprintf("https://example.com/manga/the-tower-of-many-floors-%d", i),
+		}}
+	}
+
+	msg, err := s.renderDigest(group)
+	require.NoError(t, err)
+
+	require.LessOrEqual(t, len(msg.Items), maxDigestItems+1)
+	last := msg.Items[len(msg.Items)-1]
+	assert.Equal(t, fmt.Sprintf("…and %d more", 300-(len(msg.Items)-1)), last.Body)
+	assert.Empty(t, last.URL)
+	size := len(msg.Title)
+	for _, item := range msg.Items {
+		size += len(item.Body) + len(item.URL) + 1
+	}
+	assert.Less(t, size, 4096)
+	assert.Less(t, len(msg.Body), 4096)
+}
@@ -9,23 +9,28 @@ import (
 )
 
 type MangaService interface {
-	GetAll(page int, limit int, filters map[string]string, sort string) ([]models.Manga, int, error)
+	GetAll(viewerID uint, page int, limit int, filter repositories.MangaFilter, sort repositories.MangaSort) ([]repositories.MangaSummary, int, error)
+	GetPage(viewerID uint, filter repositories.MangaFilter, sort repositories.MangaSort, page repositories.PageRequest) ([]repositories.MangaSummary, repositories.PageInfo, error)
 	GetByID(id uint) (*models.Manga, error)
+	ExpandMangas(ids []uint, include repositories.MangaIncludes) ([]models.Manga, error)
 	SearchByTags(tags []string) ([]models.Manga, error)
 	FavoriteManga(userID uint, mangaID uint) error
 	GetUserFavorites(userID uint) ([]models.Manga, error)
+	GetUserFavoritesByReleaseState(userID uint, state string) ([]models.Manga, error)
+	GetUserFavoritesPage(userID uint, state string, page repositories.PageRequest) ([]repositories.MangaSummary, repositories.PageInfo, error)
 	SetBookmark(userID uint, mangaID uint, chapter uint) error
 	GetBookmark(userID uint, mangaID uint) (uint, error)
-	AddWebsite(url string, name string) error
 	GetMangaChapters(mangaID uint) ([]models.Chapter, error)
+	GetMangaChaptersPage(mangaID uint, page repositories.PageRequest) ([]models.Chapter, repositories.PageInfo, error)
 	UnfavoriteManga(userID uint, mangaID uint) error
-	GetFavoriteUpdates(userID uint, since time.Time) ([]models.Manga, error)
-	GetWebsites() ([]models.Website, error)
-	SearchMangas(query string) ([]models.Manga, error)
+	GetFavoriteUpdates(userID uint, since time.Time) ([]repositories.MangaSummary, error)
+	SearchMangas(viewerID uint, params repositories.MangaSearchParams) ([]repositories.MangaSearchResult, int, error)
+	SuggestMangas(viewerID uint, query string, limit int) ([]repositories.MangaSuggestion, error)
 }
 
 type mangaService struct {
 	mangaRepo           repositories.MangaRepository
+	titleSearchRepo     repositories.TitleSearchRepository
 	userRepo            repositories.UserRepository
 	bookmarkRepo        repositories.BookmarkRepository
 	chapterRepo         repositories.ChapterRepository
@@ -34,9 +39,10 @@ type mangaService struct {
 	notificationService NotificationService
 }
 
-func NewMangaService(mangaRepo repositories.MangaRepository, userRepo repositories.UserRepository, bookmarkRepo repositories.BookmarkRepository, chapterRepo repositories.ChapterRepository, tagRepo repositories.TagRepository, scraperService ScraperService, notificationService NotificationService) *mangaService {
+func NewMangaService(mangaRepo repositories.MangaRepository, titleSearchRepo repositories.TitleSearchRepository, userRepo repositories.UserRepository, bookmarkRepo repositories.BookmarkRepository, chapterRepo repositories.ChapterRepository, tagRepo repositories.TagRepository, scraperService ScraperService, notificationService NotificationService) *mangaService {
 	return &mangaService{
 		mangaRepo:           mangaRepo,
+		titleSearchRepo:     titleSearchRepo,
 		userRepo:            userRepo,
 		bookmarkRepo:        bookmarkRepo,
 		chapterRepo:         chapterRepo,
@@ -46,14 +52,39 @@ func NewMangaService(mangaRepo repositories.MangaRepository, userRepo repositori
 	}
 }
 
-func (s *mangaService) GetAll(page int, limit int, filters map[string]string, sort string) ([]models.Manga, int, error) {
-	return s.mangaRepo.FindAllWithPagination(page, limit, filters, sort)
+// GetAll lists one offset page of mangas as seen by viewerID, whose adult
+// content preference is applied on top of filter.
+func (s *mangaService) GetAll(viewerID uint, page int, limit int, filter repositories.MangaFilter, sort repositories.MangaSort) ([]repositories.MangaSummary, int, error) {
+	policy, err := loadContentPolicy(s.userRepo, viewerID)
+	if err != nil {
+		return nil, 0, err
+	}
+	filter.HideAdult = filter.HideAdult || policy.hide
+	summaries, total, err := s.mangaRepo.FindAllWithPagination(page, limit, filter, sort)
+	policy.applySummaries(summaries)
+	return summaries, total, err
+}
+
+// GetPage is the keyset-paginated counterpart of GetAll.
+func (s *mangaService) GetPage(viewerID uint, filter repositories.MangaFilter, sort repositories.MangaSort, page repositories.PageRequest) ([]repositories.MangaSummary, repositories.PageInfo, error) {
+	policy, err := loadContentPolicy(s.userRepo, viewerID)
+	if err != nil {
+		return nil, repositories.PageInfo{}, err
+	}
+	filter.HideAdult = filter.HideAdult || policy.hide
+	summaries, info, err := s.mangaRepo.FindPage(filter, sort, page)
+	policy.applySummaries(summaries)
+	return summaries, info, err
 }
 
 func (s *mangaService) GetByID(id uint) (*models.Manga, error) {
 	return s.mangaRepo.FindByID(id)
 }
 
+func (s *mangaService) ExpandMangas(ids []uint, include repositories.MangaIncludes) ([]models.Manga, error) {
+	return s.mangaRepo.FindByIDs(ids, include)
+}
+
 func (s *mangaService) SearchByTags(tags []string) ([]models.Manga, error) {
 	return s.mangaRepo.SearchByTags(tags)
 }
@@ -78,11 +109,33 @@ func (s *mangaService) FavoriteManga(userID uint, mangaID uint) error {
 }
 
 func (s *mangaService) GetUserFavorites(userID uint) ([]models.Manga, error) {
-	user, err := s.userRepo.FindByID(userID)
+	policy, err := loadContentPolicy(s.userRepo, userID)
 	if err != nil {
 		return nil, err
 	}
-	return user.Favorites, nil
+	favorites, err := s.userRepo.FindFavorites(userID)
+	if err != nil {
+		return nil, err
+	}
+	return policy.filterMangas(favorites), nil
+}
+
+func (s *mangaService) GetUserFavoritesByReleaseState(userID uint, state string) ([]models.Manga, error) {
+	policy, err := loadContentPolicy(s.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.mangaRepo.FindFavoritesByReleaseState(userID, state, policy.hide)
+}
+
+func (s *mangaService) GetUserFavoritesPage(userID uint, state string, page repositories.PageRequest) ([]repositories.MangaSummary, repositories.PageInfo, error) {
+	policy, err := loadContentPolicy(s.userRepo, userID)
+	if err != nil {
+		return nil, repositories.PageInfo{}, err
+	}
+	summaries, info, err := s.mangaRepo.FindFavoritesPage(userID, state, policy.hide, page)
+	policy.applySummaries(summaries)
+	return summaries, info, err
 }
 
 func (s *mangaService) SetBookmark(userID uint, mangaID uint, chapter uint) error {
@@ -102,18 +155,14 @@ func (s *mangaService) GetBookmark(userID uint, mangaID uint) (uint, error) {
 	return bookmark.Chapter, nil
 }
 
-func (s *mangaService) AddWebsite(url string, name string) error {
-	website := &models.Website{
-		URL:  url,
-		Name: name,
-	}
-	return s.scraperService.AddWebsite(website)
-}
-
 func (s *mangaService) GetMangaChapters(mangaID uint) ([]models.Chapter, error) {
 	return s.mangaRepo.FindChaptersByMangaID(mangaID)
 }
 
+func (s *mangaService) GetMangaChaptersPage(mangaID uint, page repositories.PageRequest) ([]models.Chapter, repositories.PageInfo, error) {
+	return s.chapterRepo.FindPageByMangaID(mangaID, page)
+}
+
 func (s *mangaService) UnfavoriteManga(userID uint, mangaID uint) error {
 	user, err := s.userRepo.FindByID(userID)
 	if err != nil {
@@ -128,14 +177,38 @@ func (s *mangaService) UnfavoriteManga(userID uint, mangaID uint) error {
 	return s.userRepo.Update(user)
 }
 
-func (s *mangaService) GetFavoriteUpdates(userID uint, since time.Time) ([]models.Manga, error) {
-	return s.mangaRepo.FindFavoritesWithUpdates(userID, since)
+func (s *mangaService) GetFavoriteUpdates(userID uint, since time.Time) ([]repositories.MangaSummary, error) {
+	policy, err := loadContentPolicy(s.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := s.mangaRepo.FindFavoritesWithUpdates(userID, since, policy.hide)
+	policy.applySummaries(summaries)
+	return summaries, err
 }
 
-func (s *mangaService) GetWebsites() ([]models.Website, error) {
-	return s.scraperService.GetAllWebsites()
+// SearchMangas runs a full-text search. When it finds nothing and no filters
+// are set, it falls back to typo-tolerant title matching.
+func (s *mangaService) SearchMangas(viewerID uint, params repositories.MangaSearchParams) ([]repositories.MangaSearchResult, int, error) {
+	policy, err := loadContentPolicy(s.userRepo, viewerID)
+	if err != nil {
+		return nil, 0, err
+	}
+	params.HideAdult = params.HideAdult || policy.hide
+	results, total, err := s.mangaRepo.Search(params)
+	if err == nil && total == 0 && params.Tag == "" && params.WebsiteID == 0 && params.Status == "" {
+		results, total, err = s.titleSearchRepo.FuzzySearch(params)
+	}
+	policy.applySearchResults(results)
+	return results, total, err
 }
 
-func (s *mangaService) SearchMangas(query string) ([]models.Manga, error) {
-	return []models.Manga{}, nil
+func (s *mangaService) SuggestMangas(viewerID uint, query string, limit int) ([]repositories.MangaSuggestion, error) {
+	policy, err := loadContentPolicy(s.userRepo, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	suggestions, err := s.titleSearchRepo.Suggest(query, limit, policy.hide)
+	policy.applySuggestions(suggestions)
+	return suggestions, err
 }
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"github.com/sidler1/manga-backend/scraper"
+)
+
+const (
+	// metadataRefreshInterval is how long a manga's metadata is trusted
+	// before it is scraped again.
+	metadataRefreshInterval = 7 * 24 * time.Hour
+	// metadataRefreshBatch caps the mangas refreshed per run, spreading the
+	// load on the sources over the interval.
+	metadataRefreshBatch = 50
+)
+
+type MetadataService interface {
+	RefreshDue() error
+	RefreshManga(mangaID uint) ([]models.MangaMetadataChange, error)
+	GetHistory(mangaID uint) ([]models.MangaMetadataChange, error)
+}
+
+type metadataService struct {
+	mangaRepo      repositories.MangaRepository
+	metadataRepo   repositories.MetadataRepository
+	tagRepo        repositories.TagRepository
+	tagService     TagService
+	creatorService CreatorService
+}
+
+func NewMetadataService(mangaRepo repositories.MangaRepository, metadataRepo repositories.MetadataRepository, tagRepo repositories.TagRepository, tagService TagService, creatorService CreatorService) MetadataService {
+	return &metadataService{
+		mangaRepo:      mangaRepo,
+		metadataRepo:   metadataRepo,
+		tagRepo:        tagRepo,
+		tagService:     tagService,
+		creatorService: creatorService,
+	}
+}
+
+// RefreshDue re-scrapes the metadata of the mangas refreshed longest ago.
+// Errors for a single manga are logged and do not stop the run; the manga is
+// still marked refreshed so a broken page does not block the rotation.
+func (s *metadataService) RefreshDue() error {
+	now := time.Now()
+	mangas, err := s.mangaRepo.FindDueForMetadataRefresh(now.Add(-metadataRefreshInterval), metadataRefreshBatch)
+	if err != nil {
+		return err
+	}
+	for i := range mangas {
+		if _, err := s.refresh(&mangas[i], now); err != nil {
+			log.Printf("Error refreshing metadata of manga %d: %v", mangas[i].ID, err)
+			if err := s.mangaRepo.UpdateFields(mangas[i].ID, map[string]interface{}{"metadata_refreshed_at": now}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RefreshManga re-scrapes one manga right away and returns the changes found.
+func (s *metadataService) RefreshManga(mangaID uint) ([]models.MangaMetadataChange, error) {
+	manga, err := s.mangaRepo.FindByID(mangaID)
+	if err != nil {
+		return nil, err
+	}
+	return s.refresh(manga, time.Now())
+}
+
+func (s *metadataService) GetHistory(mangaID uint) ([]models.MangaMetadataChange, error) {
+	return s.metadataRepo.FindChangesByMangaID(mangaID)
+}
+
+// refresh scrapes the source page of manga, applies the differences to fields
+// that are not locked and records every difference in the metadata history.
+func (s *metadataService) refresh(manga *models.Manga, now time.Time) ([]models.MangaMetadataChange, error) {
+	if manga.Slug == "" {
+		return nil, fmt.Errorf("%w: manga %d has no source slug", ErrInvalidAdminInput, manga.ID)
+	}
+	sc, ok := GetScraperForWebsite(manga.Website.URL)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoScraper, manga.Website.URL)
+	}
+	details, err := sc.GetMangaDetails(manga.Slug)
+	if err != nil {
+		return nil, err
+	}
+
+	locked := lockedFields(manga)
+	changes := diffMetadata(manga, details, locked, now)
+
+	tags, err := s.resolveTags(details.Tags)
+	if err != nil {
+		return nil, err
+	}
+	var tagIDs []uint
+	if len(tags) > 0 {
+		oldTags, newTags := tagList(manga.Tags), tagList(tags)
+		if oldTags != newTags {
+			changes = append(changes, models.MangaMetadataChange{
+				MangaID: manga.ID, Field: "tags", OldValue: oldTags, NewValue: newTags,
+				Applied: !locked["tags"], ChangedAt: now,
+			})
+			if !locked["tags"] {
+				for _, tag := range tags {
+					tagIDs = append(tagIDs, tag.ID)
+				}
+			}
+		}
+	}
+
+	var errs []error
+	if tagIDs != nil {
+		if err := s.tagRepo.SetMangaTags(manga.ID, tagIDs); err != nil {
+			errs = append(errs, fmt.Errorf("tags: %w", err))
+		}
+	}
+	// The update also re-derives the content rating from the new tags.
+	fields := map[string]interface{}{"metadata_refreshed_at": now}
+	for _, change := range changes {
+		if change.Applied && change.Field != "tags" {
+			fields[change.Field] = change.NewValue
+		}
+	}
+	if err := s.mangaRepo.UpdateFields(manga.ID, fields); err != nil {
+		return nil, err
+	}
+	if applied(changes, "author") {
+		authors := details.Authors
+		if len(authors) == 0 {
+			authors = scraper.SplitAuthors(details.Author)
+		}
+		if _, err := s.creatorService.CreditManga(manga.ID, authors, details.Artists); err != nil {
+			errs = append(errs, fmt.Errorf("credits: %w", err))
+		}
+	}
+	if err := s.metadataRepo.CreateChanges(changes); err != nil {
+		errs = append(errs, err)
+	}
+	return changes, errors.Join(errs...)
+}
+
+// resolveTags maps scraped genre texts onto their canonical tags, once each.
+func (s *metadataService) resolveTags(names []string) ([]models.Tag, error) {
+	var tags []models.Tag
+	seen := make(map[uint]bool)
+	for _, name := range names {
+		tag, err := s.tagService.Resolve(name)
+		if errors.Is(err, ErrInvalidTag) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !seen[tag.ID] {
+			seen[tag.ID] = true
+			tags = append(tags, *tag)
+		}
+	}
+	return tags, nil
+}
+
+// diffMetadata compares the scraped details with the stored metadata. Empty
+// scraped values are ignored, as they usually mean the page layout changed
+// rather than that the source removed the data.
+func diffMetadata(manga *models.Manga, details scraper.Manga, locked map[string]bool, now time.Time) []models.MangaMetadataChange {
+	var changes []models.MangaMetadataChange
+	for _, field := range []struct {
+		name     string
+		old, new string
+	}{
+		{"title", manga.Title, details.Title},
+		{"alt_titles", manga.AltTitles, strings.Join(details.AltTitles, "; ")},
+		{"description", manga.Description, details.Description},
+		{"cover_url", manga.CoverURL, details.CoverURL},
+		{"author", manga.Author, details.Author},
+		{"status", manga.Status, details.Status},
+	} {
+		value := strings.TrimSpace(field.new)
+		if value == "" || value == field.old {
+			continue
+		}
+		changes = append(changes, models.MangaMetadataChange{
+			MangaID:   manga.ID,
+			Field:     field.name,
+			OldValue:  field.old,
+			NewValue:  value,
+			Applied:   !locked[field.name],
+			ChangedAt: now,
+		})
+	}
+	return changes
+}
+
+// tagList returns the sorted tag names joined with ", ".
+func tagList(tags []models.Tag) string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func applied(changes []models.MangaMetadataChange, field string) bool {
+	for _, change := range changes {
+		if change.Field == field && change.Applied {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/scraper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffMetadata_RespectsLocksAndSkipsEmptyValues(t *testing.T) {
+	now := time.Now()
+	manga := &models.Manga{Title: "Solo Leveling", Description: "old", Status: "OnGoing", CoverURL: "old.jpg", LockedFields: "description"}
+	details := scraper.Manga{Title: "Solo Leveling", Description: "new", Status: "Completed", CoverURL: ""}
+
+	changes := diffMetadata(manga, details, lockedFields(manga), now)
+
+	assert.Len(t, changes, 2)
+	assert.Equal(t, "description", changes[0].Field)
+	assert.False(t, changes[0].Applied)
+	assert.Equal(t, "status", changes[1].Field)
+	assert.Equal(t, "OnGoing", changes[1].OldValue)
+	assert.Equal(t, "Completed", changes[1].NewValue)
+	assert.True(t, changes[1].Applied)
+}
+
+func TestTagList_IsOrderIndependent(t *testing.T) {
+	a := []models.Tag{{Name: "Action"}, {Name: "Fantasy"}}
+	b := []models.Tag{{Name: "Fantasy"}, {Name: "Action"}}
+	assert.Equal(t, tagList(a), tagList(b))
+}
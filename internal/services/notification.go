@@ -3,58 +3,234 @@ package services
 import (
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/sidler1/manga-backend/internal/models"
 	"github.com/sidler1/manga-backend/internal/repositories"
+	"github.com/sidler1/manga-backend/notifier"
 )
 
 type NotificationService interface {
-	SendUpdateNotification(manga *models.Manga) error
-	GetNotifications(userID uint) ([]models.Notification, error)
+	SendUpdateNotification(manga *models.Manga, chapter *models.Chapter) error
+	SendNewWorkNotification(manga *models.Manga, creators []models.Creator) error
+	SendRemovedNotification(manga *models.Manga, alternatives []repositories.AlternativeSource) error
+	SendStateNotification(manga *models.Manga, from, to string) error
+	GetNotifications(userID uint, filter repositories.NotificationFilter) ([]models.Notification, error)
+	GetNotificationsPage(userID uint, filter repositories.NotificationFilter, page repositories.PageRequest) ([]models.Notification, repositories.PageInfo, error)
+	CountUnread(userID uint) (int64, error)
+	MarkRead(userID uint, ids []uint, read bool) (int64, error)
+	MarkReadBefore(userID uint, before time.Time, read bool) (int64, error)
+	ArchiveNotification(userID, id uint, archived bool) error
+	DeleteNotification(userID, id uint) error
+	PurgeReadNotifications(retention time.Duration) (int64, error)
+	GetPreferences(userID uint) ([]models.FavoritePreference, error)
+	SetPreference(userID, mangaID uint, input PreferenceInput) (*models.FavoritePreference, error)
+	ResetPreference(userID, mangaID uint) error
 }
 
 type notificationService struct {
 	userRepo         repositories.UserRepository
 	notificationRepo repositories.NotificationRepository
 	mangaRepo        repositories.MangaRepository
-	// Add email sender or push service
+	preferenceRepo   repositories.PreferenceRepository
+	deliveryService  DeliveryService
 }
 
-func NewNotificationService(userRepo repositories.UserRepository, notificationRepo repositories.NotificationRepository, mangaRepo repositories.MangaRepository) NotificationService {
+func NewNotificationService(userRepo repositories.UserRepository, notificationRepo repositories.NotificationRepository, mangaRepo repositories.MangaRepository, preferenceRepo repositories.PreferenceRepository, deliveryService DeliveryService) NotificationService {
 	return &notificationService{
 		userRepo:         userRepo,
 		notificationRepo: notificationRepo,
 		mangaRepo:        mangaRepo,
+		preferenceRepo:   preferenceRepo,
+		deliveryService:  deliveryService,
 	}
 }
 
-func (s *notificationService) SendUpdateNotification(manga *models.Manga) error {
+// SendUpdateNotification tells the users who favorited manga about its new
+// chapter, linking to the chapter when its URL is known.
+func (s *notificationService) SendUpdateNotification(manga *models.Manga, chapter *models.Chapter) error {
 	// Find users who favorited this manga
 	users, err := s.findUsersFavoritedManga(manga.ID)
 	if err != nil {
 		return err
 	}
 
+	message := fmt.Sprintf("New chapter available for %s: %s", manga.Title, manga.LastChapter)
+	url := manga.ExternalURL
+	if chapter != nil && chapter.URL != "" {
+		url = chapter.URL
+	}
+	s.notify(users, manga, models.NotificationTypeChapter, message, url)
+	return nil
+}
+
+// SendNewWorkNotification tells the followers of any of creators about a newly
+// discovered manga crediting them. Users following several of them are
+// notified once.
+func (s *notificationService) SendNewWorkNotification(manga *models.Manga, creators []models.Creator) error {
+	if len(creators) == 0 {
+		return nil
+	}
+	creatorIDs := make([]uint, len(creators))
+	names := make([]string, len(creators))
+	for i, c := range creators {
+		creatorIDs[i] = c.ID
+		names[i] = c.Name
+	}
+	users, err := s.userRepo.FindUsersFollowingCreators(creatorIDs)
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("New work by %s: %s", strings.Join(names, ", "), manga.Title)
+	s.notify(users, manga, models.NotificationTypeNewWork, message, manga.ExternalURL)
+	return nil
+}
+
+// SendRemovedNotification tells the users who favorited manga that it was
+// taken down from its source, naming other websites that carry it.
+func (s *notificationService) SendRemovedNotification(manga *models.Manga, alternatives []repositories.AlternativeSource) error {
+	users, err := s.findUsersFavoritedManga(manga.ID)
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("%s was removed from %s", manga.Title, manga.Website.Name)
+	if len(alternatives) > 0 {
+		sites := make([]string, len(alternatives))
+		for i, a := range alternatives {
+			sites[i] = a.WebsiteName
+		}
+		message += ". Also available on: " + strings.Join(sites, ", ")
+	}
+	s.notify(users, manga, models.NotificationTypeRemoved, message, "") // The source page is gone
+	return nil
+}
+
+// SendStateNotification tells users who favorited manga that its release
+// state changed, e.g. from ongoing to completed.
+func (s *notificationService) SendStateNotification(manga *models.Manga, from, to string) error {
+	users, err := s.findUsersFavoritedManga(manga.ID)
+	if err != nil {
+		return err
+	}
+	message := fmt.Sprintf("%s is now %s (was %s)", manga.Title, to, from)
+	s.notify(users, manga, models.NotificationTypeState, message, manga.ExternalURL)
+	return nil
+}
+
+func (s *notificationService) GetNotifications(userID uint, filter repositories.NotificationFilter) ([]models.Notification, error) {
+	return s.notificationRepo.FindByUserID(userID, filter)
+}
+
+func (s *notificationService) GetNotificationsPage(userID uint, filter repositories.NotificationFilter, page repositories.PageRequest) ([]models.Notification, repositories.PageInfo, error) {
+	return s.notificationRepo.FindPageByUserID(userID, filter, page)
+}
+
+func (s *notificationService) CountUnread(userID uint) (int64, error) {
+	return s.notificationRepo.CountUnread(userID)
+}
+
+// MarkRead marks the user's notifications with the given IDs read or unread
+// and returns how many of them were found.
+func (s *notificationService) MarkRead(userID uint, ids []uint, read bool) (int64, error) {
+	return s.notificationRepo.SetRead(userID, ids, read, time.Now())
+}
+
+// MarkReadBefore marks all of the user's notifications sent at or before
+// before read or unread and returns how many changed.
+func (s *notificationService) MarkReadBefore(userID uint, before time.Time, read bool) (int64, error) {
+	return s.notificationRepo.SetReadBefore(userID, before, read, time.Now())
+}
+
+func (s *notificationService) ArchiveNotification(userID, id uint, archived bool) error {
+	return s.notificationRepo.SetArchived(userID, id, archived)
+}
+
+func (s *notificationService) DeleteNotification(userID, id uint) error {
+	return s.notificationRepo.Delete(userID, id)
+}
+
+// PurgeReadNotifications permanently removes notifications read, or
+// deleted, longer than retention ago.
+func (s *notificationService) PurgeReadNotifications(retention time.Duration) (int64, error) {
+	return s.notificationRepo.PurgeRead(time.Now().Add(-retention))
+}
+
+// notify stores a notification linking to url for each user whose
+// preferences about manga want it and queues it for delivery over the user's
+// channels. The admin webhooks get the event too. Errors are logged so one
+// user does not block the rest.
+func (s *notificationService) notify(users []models.User, manga *models.Manga, notificationType, message, url string) {
+	var chapter string
+	if notificationType == models.NotificationTypeChapter {
+		chapter = manga.LastChapter
+	}
+	event := &models.Notification{
+		MangaID:  manga.ID,
+		Type:     notificationType,
+		Message:  message,
+		URL:      url,
+		Chapter:  chapter,
+		Priority: notificationPriority(notificationType, manga),
+		SentAt:   time.Now(),
+	}
+	if err := s.deliveryService.Broadcast(event); err != nil {
+		log.Printf("Error queueing %s event of manga %d for admin webhooks: %v", notificationType, manga.ID, err)
+	}
+
+	preferences, err := s.preferenceRepo.FindByMangaID(manga.ID)
+	if err != nil {
+		log.Printf("Error loading notification preferences of manga %d: %v", manga.ID, err)
+	}
+	byUser := make(map[uint]*models.FavoritePreference, len(preferences))
+	for i := range preferences {
+		byUser[preferences[i].UserID] = &preferences[i]
+	}
 	for _, user := range users {
-		message := fmt.Sprintf("New chapter available for %s: %s", manga.Title, manga.LastChapter)
+		if preference, ok := byUser[user.ID]; ok && !s.wants(preference, manga, notificationType) {
+			continue
+		}
 		notification := &models.Notification{
-			UserID:  user.ID,
-			MangaID: manga.ID,
-			Message: message,
-			SentAt:  time.Now(),
+			UserID:   user.ID,
+			MangaID:  manga.ID,
+			Type:     notificationType,
+			Message:  message,
+			URL:      url,
+			Chapter:  chapter,
+			Priority: event.Priority,
+			SentAt:   time.Now(),
 		}
 		if err := s.notificationRepo.Create(notification); err != nil {
 			log.Printf("Error creating notification: %v", err)
 			continue
 		}
-		// Send via email or push; placeholder
+		if err := s.deliveryService.Enqueue(notification); err != nil {
+			log.Printf("Error queueing notification %d: %v", notification.ID, err)
+		}
 	}
-	return nil
 }
 
-func (s *notificationService) GetNotifications(userID uint) ([]models.Notification, error) {
-	return s.notificationRepo.FindByUserID(userID)
+// notificationPriority rates a notification by what it means for the manga:
+// a chapter after a hiatus, a completed series or a lost source matter more
+// than regular releases, and new works of followed creators less.
+func notificationPriority(notificationType string, manga *models.Manga) int {
+	switch notificationType {
+	case models.NotificationTypeRemoved:
+		return notifier.PriorityHigh
+	case models.NotificationTypeNewWork:
+		return notifier.PriorityLow
+	case models.NotificationTypeChapter:
+		if manga.ReleaseState == models.ReleaseStateHiatus || manga.ReleaseState == models.ReleaseStateDropped {
+			return notifier.PriorityHigh
+		}
+	case models.NotificationTypeState:
+		if manga.ReleaseState == models.ReleaseStateCompleted {
+			return notifier.PriorityHigh
+		}
+	}
+	return notifier.PriorityDefault
 }
 
 func (s *notificationService) findUsersFavoritedManga(mangaID uint) ([]models.User, error) {
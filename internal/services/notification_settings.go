@@ -0,0 +1,173 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+)
+
+var ErrInvalidNotificationSettings = errors.New("invalid notification settings")
+
+// NotificationSettings is when a user's notifications are delivered.
+type NotificationSettings struct {
+	Mode            string `json:"mode"`              // instant, hourly, daily or weekly
+	Time            string `json:"time"`              // HH:MM of daily and weekly digests
+	Weekday         int    `json:"weekday"`           // Day of weekly digests, 0 is Sunday
+	Timezone        string `json:"timezone"`          // IANA name times are in
+	Paused          bool   `json:"paused"`            // Deliver nothing over channels
+	QuietHoursStart string `json:"quiet_hours_start"` // HH:MM, empty for no quiet hours
+	QuietHoursEnd   string `json:"quiet_hours_end"`   // HH:MM, may be before the start
+}
+
+func (s *deliveryService) GetSettings(userID uint) (*NotificationSettings, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return notificationSettings(user), nil
+}
+
+// UpdateSettings saves the user's notification settings and moves the
+// deliveries held so far to the new schedule, sending them right away when
+// switching to instant delivery. While paused they are left as they are and
+// moved when notifications are resumed.
+func (s *deliveryService) UpdateSettings(userID uint, settings NotificationSettings) (*NotificationSettings, error) {
+	if err := validateNotificationSettings(settings); err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	user.DigestMode = settings.Mode
+	user.DigestTime = settings.Time
+	user.DigestWeekday = settings.Weekday
+	user.Timezone = settings.Timezone
+	user.NotificationsPaused = settings.Paused
+	user.QuietHoursStart = settings.QuietHoursStart
+	user.QuietHoursEnd = settings.QuietHoursEnd
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	if user.NotificationsPaused {
+		return notificationSettings(user), nil
+	}
+	now := time.Now()
+	status, nextAttemptAt := models.DeliveryStatusPending, now
+	if user.DigestMode != models.DigestModeInstant {
+		status, nextAttemptAt = models.DeliveryStatusHeld, nextDigestTime(user, now)
+	}
+	if err := s.deliveryRepo.RescheduleHeld(userID, status, afterQuietHours(user, nextAttemptAt)); err != nil {
+		return nil, err
+	}
+	return notificationSettings(user), nil
+}
+
+func notificationSettings(user *models.User) *NotificationSettings {
+	mode := user.DigestMode
+	if mode == "" {
+		mode = models.DigestModeInstant
+	}
+	return &NotificationSettings{
+		Mode:            mode,
+		Time:            user.DigestTime,
+		Weekday:         user.DigestWeekday,
+		Timezone:        user.Timezone,
+		Paused:          user.NotificationsPaused,
+		QuietHoursStart: user.QuietHoursStart,
+		QuietHoursEnd:   user.QuietHoursEnd,
+	}
+}
+
+// validateNotificationSettings checks the fields the settings use: the time
+// of daily and weekly digests, the weekday of weekly ones, and the timezone
+// these and quiet hours are in. An unknown timezone is refused in any case.
+func validateNotificationSettings(settings NotificationSettings) error {
+	switch settings.Mode {
+	case models.DigestModeInstant, models.DigestModeHourly, models.DigestModeDaily, models.DigestModeWeekly:
+	default:
+		return fmt.Errorf("%w: mode must be instant, hourly, daily or weekly", ErrInvalidNotificationSettings)
+	}
+	scheduled := settings.Mode == models.DigestModeDaily || settings.Mode == models.DigestModeWeekly
+	if scheduled {
+		if _, err := time.Parse("15:04", settings.Time); err != nil {
+			return fmt.Errorf("%w: time must be HH:MM", ErrInvalidNotificationSettings)
+		}
+	}
+	if settings.Mode == models.DigestModeWeekly && (settings.Weekday < 0 || settings.Weekday > 6) {
+		return fmt.Errorf("%w: weekday must be 0 (Sunday) to 6", ErrInvalidNotificationSettings)
+	}
+	quietHours := settings.QuietHoursStart != "" || settings.QuietHoursEnd != ""
+	if settings.Timezone == "" && (scheduled || quietHours) {
+		return fmt.Errorf("%w: timezone is required", ErrInvalidNotificationSettings)
+	}
+	if _, err := time.LoadLocation(settings.Timezone); err != nil {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalidNotificationSettings, settings.Timezone)
+	}
+	if !quietHours {
+		return nil
+	}
+	start, err1 := time.Parse("15:04", settings.QuietHoursStart)
+	end, err2 := time.Parse("15:04", settings.QuietHoursEnd)
+	if err1 != nil || err2 != nil {
+		return fmt.Errorf("%w: quiet hours must both be HH:MM", ErrInvalidNotificationSettings)
+	}
+	if start.Equal(end) {
+		return fmt.Errorf("%w: quiet hours must not start and end at the same time", ErrInvalidNotificationSettings)
+	}
+	return nil
+}
+
+// pausedRecheck is how long due deliveries of users who paused
+// notifications wait before they are looked at again.
+const pausedRecheck = time.Hour
+
+// deferUntil returns when a delivery to user due at now may be sent: now,
+// the end of the user's quiet hours, or pausedRecheck later while the user
+// paused notifications.
+func deferUntil(user *models.User, now time.Time) time.Time {
+	if user.NotificationsPaused {
+		return now.Add(pausedRecheck)
+	}
+	return afterQuietHours(user, now)
+}
+
+// userLocation returns the user's timezone, UTC if unset or unknown.
+func userLocation(user *models.User) *time.Location {
+	if user.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// afterQuietHours returns t, or the end of the user's quiet hours when t
+// falls within them.
+func afterQuietHours(user *models.User, t time.Time) time.Time {
+	start, err1 := time.Parse("15:04", user.QuietHoursStart)
+	end, err2 := time.Parse("15:04", user.QuietHoursEnd)
+	if err1 != nil || err2 != nil || start.Equal(end) {
+		return t
+	}
+	local := t.In(userLocation(user))
+	minute := local.Hour()*60 + local.Minute()
+	from, to := start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+	quiet := minute >= from && minute < to
+	if from > to { // Spans midnight, e.g. 22:00 to 07:00
+		quiet = minute >= from || minute < to
+	}
+	if !quiet {
+		return t
+	}
+	ends := time.Date(local.Year(), local.Month(), local.Day(), end.Hour(), end.Minute(), 0, 0, local.Location())
+	if !ends.After(t) {
+		ends = ends.AddDate(0, 0, 1)
+	}
+	return ends
+}
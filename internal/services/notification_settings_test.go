@@ -0,0 +1,57 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateNotificationSettings(t *testing.T) {
+	valid := NotificationSettings{Mode: models.DigestModeDaily, Time: "08:00", Weekday: 1, Timezone: "UTC", QuietHoursStart: "22:00", QuietHoursEnd: "07:00"}
+	assert.NoError(t, validateNotificationSettings(valid))
+	for _, settings := range []NotificationSettings{
+		{Mode: models.DigestModeInstant},
+		{Mode: models.DigestModeHourly, Weekday: 9},
+		{Mode: models.DigestModeDaily, Time: "08:00", Weekday: 9, Timezone: "UTC"},
+	} {
+		assert.NoError(t, validateNotificationSettings(settings), settings)
+	}
+
+	for _, settings := range []NotificationSettings{
+		{Mode: "monthly", Time: "08:00", Timezone: "UTC"},
+		{Mode: models.DigestModeDaily, Time: "8am", Timezone: "UTC"},
+		{Mode: models.DigestModeWeekly, Time: "08:00", Weekday: 7, Timezone: "UTC"},
+		{Mode: models.DigestModeDaily, Time: "08:00", Timezone: "Nowhere/City"},
+		{Mode: models.DigestModeInstant, Time: "08:00", Timezone: "UTC", QuietHoursStart: "22:00"},
+		{Mode: models.DigestModeInstant, Time: "08:00", Timezone: "UTC", QuietHoursStart: "22:00", QuietHoursEnd: "22:00"},
+		{Mode: models.DigestModeDaily, Time: "08:00"},
+		{Mode: models.DigestModeInstant, QuietHoursStart: "22:00", QuietHoursEnd: "07:00"},
+		{Mode: models.DigestModeInstant, Timezone: "Nowhere/City"},
+	} {
+		assert.ErrorIs(t, validateNotificationSettings(settings), ErrInvalidNotificationSettings, settings)
+	}
+}
+
+func TestAfterQuietHours(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+	user := &models.User{Timezone: "Asia/Tokyo", QuietHoursStart: "22:00", QuietHoursEnd: "07:00"}
+
+	evening := time.Date(2025, 3, 5, 23, 30, 0, 0, tokyo)
+	assert.True(t, time.Date(2025, 3, 6, 7, 0, 0, 0, tokyo).Equal(afterQuietHours(user, evening)))
+	night := time.Date(2025, 3, 6, 3, 0, 0, 0, tokyo)
+	assert.True(t, time.Date(2025, 3, 6, 7, 0, 0, 0, tokyo).Equal(afterQuietHours(user, night)))
+	day := time.Date(2025, 3, 6, 7, 0, 0, 0, tokyo)
+	assert.Equal(t, day, afterQuietHours(user, day))
+
+	user.QuietHoursStart, user.QuietHoursEnd = "12:00", "14:00"
+	lunch := time.Date(2025, 3, 6, 12, 15, 0, 0, tokyo)
+	assert.True(t, time.Date(2025, 3, 6, 14, 0, 0, 0, tokyo).Equal(afterQuietHours(user, lunch)))
+
+	user.QuietHoursStart, user.QuietHoursEnd = "", ""
+	assert.Equal(t, night, afterQuietHours(user, night))
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeNotificationRepo struct {
+	repositories.NotificationRepository
+	readBefore time.Time
+	created    []models.Notification
+}
+
+func (r *fakeNotificationRepo) Create(notification *models.Notification) error {
+	r.created = append(r.created, *notification)
+	return nil
+}
+
+func (r *fakeNotificationRepo) PurgeRead(readBefore time.Time) (int64, error) {
+	r.readBefore = readBefore
+	return 3, nil
+}
+
+func TestNotificationService_PurgeReadNotifications(t *testing.T) {
+	repo := &fakeNotificationRepo{}
+	s := &notificationService{notificationRepo: repo}
+
+	purged, err := s.PurgeReadNotifications(30 * 24 * time.Hour)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(3), purged)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, -30), repo.readBefore, time.Minute)
+}
+
+type favoritingUserRepo struct {
+	repositories.UserRepository
+}
+
+func (favoritingUserRepo) FindUsersByFavoriteManga(mangaID uint) ([]models.User, error) {
+	user := models.User{}
+	user.ID = 7
+	return []models.User{user}, nil
+}
+
+type noPreferenceRepo struct {
+	repositories.PreferenceRepository
+}
+
+func (noPreferenceRepo) FindByMangaID(mangaID uint) ([]models.FavoritePreference, error) {
+	return nil, nil
+}
+
+type enqueueDeliveryService struct {
+	DeliveryService
+}
+
+func (enqueueDeliveryService) Enqueue(*models.Notification) error {
+	return nil
+}
+
+func (enqueueDeliveryService) Broadcast(*models.Notification) error {
+	return nil
+}
+
+func TestNotificationService_SendUpdateNotification_LinksChapter(t *testing.T) {
+	manga := &models.Manga{Title: "Solo Leveling", LastChapter: "Chapter 12", ExternalURL: "https://example.com/manga/solo/"}
+	tests := []struct {
+		name    string
+		chapter *models.Chapter
+		url     string
+	}{
+		{"chapter URL", &models.Chapter{URL: "https://example.com/manga/solo/chapter-12/"}, "https://example.com/manga/solo/chapter-12/"},
+		{"no chapter URL", &models.Chapter{}, "https://example.com/manga/solo/"},
+		{"no chapter", nil, "https://example.com/manga/solo/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeNotificationRepo{}
+			s := &notificationService{userRepo: favoritingUserRepo{}, notificationRepo: repo, preferenceRepo: noPreferenceRepo{}, deliveryService: enqueueDeliveryService{}}
+
+			require.NoError(t, s.SendUpdateNotification(manga, tt.chapter))
+
+			require.Len(t, repo.created, 1)
+			assert.Equal(t, tt.url, repo.created[0].URL)
+			assert.Equal(t, "Chapter 12", repo.created[0].Chapter)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"sort"
+
+	"github.com/sidler1/manga-backend/notifier"
+)
+
+// notifiers maps channel names to their delivery implementations. Channels
+// are registered at startup from the configuration.
+var notifiers = map[string]notifier.Notifier{}
+
+func RegisterNotifier(n notifier.Notifier) {
+	notifiers[n.Channel()] = n
+}
+
+func GetNotifier(channel string) (notifier.Notifier, bool) {
+	n, ok := notifiers[channel]
+	return n, ok
+}
+
+// NotifierChannels returns the names of the registered channels, sorted.
+func NotifierChannels() []string {
+	channels := make([]string, 0, len(notifiers))
+	for channel := range notifiers {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	return channels
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/sidler1/manga-backend/internal/models"
+)
+
+var (
+	ErrNotFavorite       = errors.New("manga is not a favorite")
+	ErrInvalidPreference = errors.New("invalid notification preference")
+)
+
+// PreferenceInput is how a user wants to be notified about one favorite.
+type PreferenceInput struct {
+	Muted          bool `json:"muted"`
+	EveryChapters  int  `json:"every_chapters"`  // Notify about every Nth new chapter only
+	CompletionOnly bool `json:"completion_only"` // Notify only when the series is completed
+}
+
+func (s *notificationService) GetPreferences(userID uint) ([]models.FavoritePreference, error) {
+	return s.preferenceRepo.FindByUserID(userID)
+}
+
+// SetPreference replaces the user's notification preference about one of
+// their favorites, restarting the count of skipped chapters.
+func (s *notificationService) SetPreference(userID, mangaID uint, input PreferenceInput) (*models.FavoritePreference, error) {
+	if input.EveryChapters < 0 {
+		return nil, fmt.Errorf("%w: every_chapters must not be negative", ErrInvalidPreference)
+	}
+	favorites, err := s.userRepo.FindFavorites(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.ContainsFunc(favorites, func(m models.Manga) bool { return m.ID == mangaID }) {
+		return nil, ErrNotFavorite
+	}
+	preference := &models.FavoritePreference{
+		UserID:         userID,
+		MangaID:        mangaID,
+		Muted:          input.Muted,
+		EveryChapters:  input.EveryChapters,
+		CompletionOnly: input.CompletionOnly,
+	}
+	return preference, s.preferenceRepo.Save(preference)
+}
+
+// ResetPreference goes back to notifying about everything of the manga.
+func (s *notificationService) ResetPreference(userID, mangaID uint) error {
+	return s.preferenceRepo.Delete(userID, mangaID)
+}
+
+// wants reports whether a user with preference about manga is notified of
+// notificationType. Chapters skipped for EveryChapters are counted.
+func (s *notificationService) wants(preference *models.FavoritePreference, manga *models.Manga, notificationType string) bool {
+	switch {
+	case preference.Muted:
+		return false
+	case preference.CompletionOnly:
+		return notificationType == models.NotificationTypeState && manga.ReleaseState == models.ReleaseStateCompleted
+	case notificationType != models.NotificationTypeChapter || preference.EveryChapters <= 1:
+		return true
+	}
+	preference.SkippedChapters++
+	notify := preference.SkippedChapters >= preference.EveryChapters
+	if notify {
+		preference.SkippedChapters = 0
+	}
+	if err := s.preferenceRepo.Save(preference); err != nil {
+		log.Printf("Error saving notification preference %d: %v", preference.ID, err)
+	}
+	return notify
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePreferenceRepo struct {
+	repositories.PreferenceRepository
+	saved int
+}
+
+func (r *fakePreferenceRepo) Save(preference *models.FavoritePreference) error {
+	r.saved++
+	return nil
+}
+
+func TestNotificationService_Wants(t *testing.T) {
+	repo := &fakePreferenceRepo{}
+	s := &notificationService{preferenceRepo: repo}
+	ongoing := &models.Manga{ReleaseState: models.ReleaseStateOngoing}
+	completed := &models.Manga{ReleaseState: models.ReleaseStateCompleted}
+
+	muted := &models.FavoritePreference{Muted: true}
+	assert.False(t, s.wants(muted, ongoing, models.NotificationTypeChapter))
+	assert.False(t, s.wants(muted, completed, models.NotificationTypeState))
+
+	completion := &models.FavoritePreference{CompletionOnly: true}
+	assert.False(t, s.wants(completion, ongoing, models.NotificationTypeChapter))
+	assert.False(t, s.wants(completion, ongoing, models.NotificationTypeState))
+	assert.True(t, s.wants(completion, completed, models.NotificationTypeState))
+
+	everyThird := &models.FavoritePreference{EveryChapters: 3}
+	var notified []bool
+	for range 6 {
+		notified = append(notified, s.wants(everyThird, ongoing, models.NotificationTypeChapter))
+	}
+	assert.Equal(t, []bool{false, false, true, false, false, true}, notified)
+	assert.Equal(t, 6, repo.saved)
+	assert.True(t, s.wants(everyThird, ongoing, models.NotificationTypeRemoved))
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"github.com/sidler1/manga-backend/notifier"
+	"gorm.io/gorm"
+)
+
+// vapidKeyName is the name the generated VAPID key is stored under.
+const vapidKeyName = "vapid"
+
+// ErrPushNotConfigured is returned when Web Push is not set up on the server.
+var ErrPushNotConfigured = errors.New("web push is not configured")
+
+// PushSubscriptionInput is a browser's PushSubscription as serialized by its
+// toJSON method.
+type PushSubscriptionInput struct {
+	Endpoint string               `json:"endpoint"`
+	Keys     notifier.WebPushKeys `json:"keys"`
+}
+
+type PushService interface {
+	PublicKey() (string, error)
+	Register(userID uint, input PushSubscriptionInput) (*models.ChannelSubscription, error)
+	Unregister(userID uint, endpoint string) error
+}
+
+type pushService struct {
+	channelRepo repositories.ChannelRepository
+	key         *ecdsa.PrivateKey // Nil when Web Push is not configured
+}
+
+func NewPushService(channelRepo repositories.ChannelRepository, key *ecdsa.PrivateKey) PushService {
+	return &pushService{channelRepo: channelRepo, key: key}
+}
+
+// LoadVAPIDKey returns the configured VAPID key or, without one, the key
+// generated on first start and kept in the database.
+func LoadVAPIDKey(keyRepo repositories.ServerKeyRepository, configured string) (*ecdsa.PrivateKey, error) {
+	if configured != "" {
+		return notifier.ParseVAPIDKey(configured)
+	}
+	key, err := notifier.GenerateVAPIDKey()
+	if err != nil {
+		return nil, err
+	}
+	stored, err := keyRepo.FindOrCreate(&models.ServerKey{Name: vapidKeyName, Value: notifier.EncodeVAPIDKey(key)})
+	if err != nil {
+		return nil, err
+	}
+	return notifier.ParseVAPIDKey(stored.Value)
+}
+
+// PublicKey returns the applicationServerKey browsers subscribe with.
+func (s *pushService) PublicKey() (string, error) {
+	if s.key == nil {
+		return "", ErrPushNotConfigured
+	}
+	return notifier.VAPIDPublicKey(s.key), nil
+}
+
+// Register saves a device's push subscription as a webpush channel
+// subscription. Registering an endpoint again updates its keys.
+func (s *pushService) Register(userID uint, input PushSubscriptionInput) (*models.ChannelSubscription, error) {
+	n, ok := GetNotifier(notifier.ChannelWebPush)
+	if !ok {
+		return nil, ErrPushNotConfigured
+	}
+	keys, err := json.Marshal(input.Keys)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := strings.TrimSpace(input.Endpoint)
+	if err := n.Validate(notifier.Destination{Target: endpoint, Config: string(keys)}); err != nil {
+		return nil, err
+	}
+
+	subscription, err := s.channelRepo.FindByTarget(userID, notifier.ChannelWebPush, endpoint)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		subscription = &models.ChannelSubscription{UserID: userID, Channel: notifier.ChannelWebPush, Target: endpoint, Config: string(keys), Enabled: true}
+		return subscription, s.channelRepo.Create(subscription)
+	}
+	if err != nil {
+		return nil, err
+	}
+	subscription.Config = string(keys)
+	subscription.Enabled = true
+	return subscription, s.channelRepo.Update(subscription)
+}
+
+// Unregister removes the push subscription of a device, e.g. on logout.
+func (s *pushService) Unregister(userID uint, endpoint string) error {
+	subscription, err := s.channelRepo.FindByTarget(userID, notifier.ChannelWebPush, strings.TrimSpace(endpoint))
+	if err != nil {
+		return fmt.Errorf("push subscription: %w", err)
+	}
+	return s.channelRepo.Delete(subscription.ID)
+}
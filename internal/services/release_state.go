@@ -0,0 +1,203 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+)
+
+const (
+	// minHiatusGap and minDroppedGap keep fast-releasing series from being
+	// flagged after a single skipped week.
+	minHiatusGap  = 30 * 24 * time.Hour
+	minDroppedGap = 180 * 24 * time.Hour
+
+	hiatusCadenceFactor  = 3
+	droppedCadenceFactor = 8
+)
+
+type ReleaseStateService interface {
+	AnalyzeAll() error
+	Analyze(manga *models.Manga) error
+	GetTransitions(mangaID uint) ([]models.MangaStateTransition, error)
+}
+
+type releaseStateService struct {
+	mangaRepo           repositories.MangaRepository
+	chapterRepo         repositories.ChapterRepository
+	releaseStateRepo    repositories.ReleaseStateRepository
+	notificationService NotificationService
+}
+
+func NewReleaseStateService(mangaRepo repositories.MangaRepository, chapterRepo repositories.ChapterRepository, releaseStateRepo repositories.ReleaseStateRepository, notificationService NotificationService) ReleaseStateService {
+	return &releaseStateService{
+		mangaRepo:           mangaRepo,
+		chapterRepo:         chapterRepo,
+		releaseStateRepo:    releaseStateRepo,
+		notificationService: notificationService,
+	}
+}
+
+// AnalyzeAll re-classifies the release state of every manga. Errors for a
+// single manga are logged and do not stop the run.
+func (s *releaseStateService) AnalyzeAll() error {
+	mangas, err := s.mangaRepo.FindAll()
+	if err != nil {
+		return err
+	}
+	for i := range mangas {
+		if err := s.Analyze(&mangas[i]); err != nil {
+			log.Printf("Error analyzing release state of manga %d: %v", mangas[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// Analyze classifies a single manga from its scraped status and chapter history
+// and records a transition if the state changed, notifying its followers. The
+// first classification of a manga is recorded without a transition.
+func (s *releaseStateService) Analyze(manga *models.Manga) error {
+	chapters, err := s.chapterRepo.FindByMangaID(manga.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	state, reason := classifyReleaseState(manga.Status, chapters, now)
+	previous := manga.ReleaseState
+	if state == previous && !manga.StateSince.IsZero() {
+		return nil
+	}
+
+	manga.ReleaseState = state
+	manga.StateSince = now
+	if err := s.mangaRepo.Update(manga); err != nil {
+		return err
+	}
+	// A manga without a state yet only gets its initial one; that is not a
+	// change followers need to hear about.
+	if previous == "" || state == previous {
+		return nil
+	}
+	err = s.releaseStateRepo.CreateTransition(&models.MangaStateTransition{
+		MangaID:        manga.ID,
+		FromState:      previous,
+		ToState:        state,
+		Reason:         reason,
+		TransitionedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+	return s.notificationService.SendStateNotification(manga, previous, state)
+}
+
+func (s *releaseStateService) GetTransitions(mangaID uint) ([]models.MangaStateTransition, error) {
+	return s.releaseStateRepo.FindTransitionsByMangaID(mangaID)
+}
+
+// classifyReleaseState derives a release state and a human-readable reason.
+// An explicit status from the source wins; otherwise the time since the last
+// chapter is compared against the series' median release interval.
+func classifyReleaseState(scrapedStatus string, chapters []models.Chapter, now time.Time) (string, string) {
+	if state := normalizeScrapedStatus(scrapedStatus); state != "" && state != models.ReleaseStateOngoing {
+		return state, fmt.Sprintf("source reports status %q", scrapedStatus)
+	}
+
+	latest, ok := latestReleaseDate(chapters)
+	if !ok {
+		return models.ReleaseStateOngoing, "no release history"
+	}
+	gap := now.Sub(latest)
+
+	hiatusAfter, droppedAfter := minHiatusGap, minDroppedGap
+	if cadence, ok := releaseCadence(chapters); ok {
+		hiatusAfter = maxDuration(hiatusAfter, hiatusCadenceFactor*cadence)
+		droppedAfter = maxDuration(droppedAfter, droppedCadenceFactor*cadence)
+	}
+
+	switch {
+	case gap >= droppedAfter:
+		return models.ReleaseStateDropped, fmt.Sprintf("no release for %d days", int(gap.Hours()/24))
+	case gap >= hiatusAfter:
+		return models.ReleaseStateHiatus, fmt.Sprintf("no release for %d days", int(gap.Hours()/24))
+	default:
+		return models.ReleaseStateOngoing, "releasing on schedule"
+	}
+}
+
+// normalizeScrapedStatus maps the free-text status of a source onto a release
+// state, returning "" when the text is unknown.
+func normalizeScrapedStatus(status string) string {
+	key := strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(status)))
+	switch key {
+	case "ongoing", "publishing", "releasing":
+		return models.ReleaseStateOngoing
+	case "completed", "complete", "finished", "end", "ended":
+		return models.ReleaseStateCompleted
+	case "onhold", "hiatus", "paused":
+		return models.ReleaseStateHiatus
+	case "canceled", "cancelled", "dropped", "discontinued":
+		return models.ReleaseStateDropped
+	default:
+		return ""
+	}
+}
+
+// releaseCadence returns the median interval between consecutive chapter
+// releases. At least two dated chapters are required.
+func releaseCadence(chapters []models.Chapter) (time.Duration, bool) {
+	var dates []time.Time
+	for _, c := range chapters {
+		if !c.ReleaseDate.IsZero() {
+			dates = append(dates, c.ReleaseDate)
+		}
+	}
+	if len(dates) < 2 {
+		return 0, false
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	gaps := make([]time.Duration, 0, len(dates)-1)
+	for i := 1; i < len(dates); i++ {
+		if gap := dates[i].Sub(dates[i-1]); gap > 0 {
+			gaps = append(gaps, gap)
+		}
+	}
+	if len(gaps) == 0 {
+		return 0, false
+	}
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i] < gaps[j] })
+	return gaps[len(gaps)/2], true
+}
+
+func latestReleaseDate(chapters []models.Chapter) (time.Time, bool) {
+	var latest time.Time
+	for _, c := range chapters {
+		if c.ReleaseDate.After(latest) {
+			latest = c.ReleaseDate
+		}
+	}
+	return latest, !latest.IsZero()
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// IsValidReleaseState reports whether state is one of the known release states.
+func IsValidReleaseState(state string) bool {
+	switch state {
+	case models.ReleaseStateOngoing, models.ReleaseStateHiatus, models.ReleaseStateCompleted, models.ReleaseStateDropped:
+		return true
+	}
+	return false
+}
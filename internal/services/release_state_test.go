@@ -0,0 +1,128 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func weeklyChapters(count int, last time.Time) []models.Chapter {
+	chapters := make([]models.Chapter, count)
+	for i := range chapters {
+		chapters[i] = models.Chapter{
+			Number:      uint(i + 1),
+			ReleaseDate: last.Add(-time.Duration(count-1-i) * 7 * 24 * time.Hour),
+		}
+	}
+	return chapters
+}
+
+func TestClassifyReleaseState_ScrapedStatusWins(t *testing.T) {
+	now := time.Now()
+	state, _ := classifyReleaseState("Completed", weeklyChapters(10, now), now)
+	assert.Equal(t, models.ReleaseStateCompleted, state)
+
+	state, _ = classifyReleaseState("On Hold", weeklyChapters(10, now), now)
+	assert.Equal(t, models.ReleaseStateHiatus, state)
+
+	state, _ = classifyReleaseState("Canceled", nil, now)
+	assert.Equal(t, models.ReleaseStateDropped, state)
+}
+
+func TestClassifyReleaseState_Ongoing(t *testing.T) {
+	now := time.Now()
+	state, _ := classifyReleaseState("OnGoing", weeklyChapters(10, now.Add(-3*24*time.Hour)), now)
+	assert.Equal(t, models.ReleaseStateOngoing, state)
+}
+
+func TestClassifyReleaseState_NoHistory(t *testing.T) {
+	state, _ := classifyReleaseState("", nil, time.Now())
+	assert.Equal(t, models.ReleaseStateOngoing, state)
+}
+
+func TestClassifyReleaseState_Hiatus(t *testing.T) {
+	now := time.Now()
+	state, _ := classifyReleaseState("OnGoing", weeklyChapters(10, now.Add(-40*24*time.Hour)), now)
+	assert.Equal(t, models.ReleaseStateHiatus, state)
+}
+
+func TestClassifyReleaseState_Dropped(t *testing.T) {
+	now := time.Now()
+	state, _ := classifyReleaseState("", weeklyChapters(10, now.Add(-200*24*time.Hour)), now)
+	assert.Equal(t, models.ReleaseStateDropped, state)
+}
+
+func TestClassifyReleaseState_SlowCadenceIsNotHiatus(t *testing.T) {
+	now := time.Now()
+	chapters := []models.Chapter{
+		{ReleaseDate: now.Add(-185 * 24 * time.Hour)},
+		{ReleaseDate: now.Add(-125 * 24 * time.Hour)},
+		{ReleaseDate: now.Add(-65 * 24 * time.Hour)},
+		{ReleaseDate: now.Add(-5 * 24 * time.Hour)},
+	}
+	state, _ := classifyReleaseState("", chapters, now.Add(60*24*time.Hour))
+	assert.Equal(t, models.ReleaseStateOngoing, state)
+}
+
+func TestReleaseCadence_Median(t *testing.T) {
+	now := time.Now()
+	cadence, ok := releaseCadence(weeklyChapters(5, now))
+	assert.True(t, ok)
+	assert.Equal(t, 7*24*time.Hour, cadence)
+
+	_, ok = releaseCadence(weeklyChapters(1, now))
+	assert.False(t, ok)
+}
+
+type releaseMangaRepo struct {
+	repositories.MangaRepository
+}
+
+func (releaseMangaRepo) Update(*models.Manga) error { return nil }
+
+type releaseChapterRepo struct {
+	repositories.ChapterRepository
+}
+
+func (releaseChapterRepo) FindByMangaID(uint) ([]models.Chapter, error) { return nil, nil }
+
+type releaseTransitionRepo struct {
+	repositories.ReleaseStateRepository
+	transitions []models.MangaStateTransition
+}
+
+func (r *releaseTransitionRepo) CreateTransition(transition *models.MangaStateTransition) error {
+	r.transitions = append(r.transitions, *transition)
+	return nil
+}
+
+type stateNotifications struct {
+	NotificationService
+	sent []string
+}
+
+func (n *stateNotifications) SendStateNotification(manga *models.Manga, from, to string) error {
+	n.sent = append(n.sent, from+"->"+to)
+	return nil
+}
+
+func TestReleaseStateService_Analyze_FirstStateIsNotATransition(t *testing.T) {
+	transitions, notifications := &releaseTransitionRepo{}, &stateNotifications{}
+	s := NewReleaseStateService(releaseMangaRepo{}, releaseChapterRepo{}, transitions, notifications)
+
+	manga := &models.Manga{Status: "Completed"}
+	require.NoError(t, s.Analyze(manga))
+	assert.Equal(t, models.ReleaseStateCompleted, manga.ReleaseState)
+	assert.False(t, manga.StateSince.IsZero())
+	assert.Empty(t, transitions.transitions)
+	assert.Empty(t, notifications.sent)
+
+	manga.Status = "Ongoing"
+	require.NoError(t, s.Analyze(manga))
+	require.Len(t, transitions.transitions, 1)
+	assert.Equal(t, []string{"completed->ongoing"}, notifications.sent)
+}
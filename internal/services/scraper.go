@@ -1,10 +1,11 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"log"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/sidler1/manga-backend/internal/models"
@@ -12,17 +13,43 @@ import (
 	"github.com/sidler1/manga-backend/scraper"
 )
 
-var scrapers = map[string]scraper.Scraper{}
+// scrapers maps website base URLs to their scrapers. It is re-keyed at run
+// time when a website moves, hence the lock.
+var (
+	scrapers   = map[string]scraper.Scraper{}
+	scrapersMu sync.RWMutex
+)
+
+// defaultCadence is assumed for series without enough release history.
+const defaultCadence = 7 * 24 * time.Hour
 
 func RegisterScrapers() {
+	scrapersMu.Lock()
+	defer scrapersMu.Unlock()
 	scrapers["https://www.mangaread.org/"] = scraper.NewMangaReadScraper()
 }
 
 func GetScraperForWebsite(url string) (scraper.Scraper, bool) {
+	scrapersMu.RLock()
+	defer scrapersMu.RUnlock()
 	s, ok := scrapers[url]
 	return s, ok
 }
 
+// MoveScraper re-keys the scraper registered for from to to, pointing it at
+// the new base URL. It reports whether from had a scraper.
+func MoveScraper(from, to string) bool {
+	scrapersMu.Lock()
+	defer scrapersMu.Unlock()
+	s, ok := scrapers[from]
+	if !ok {
+		return false
+	}
+	delete(scrapers, from)
+	scrapers[to] = s.WithBaseURL(to)
+	return true
+}
+
 type MangaUpdate struct {
 	MangaID     uint
 	NewChapter  string
@@ -32,34 +59,73 @@ type MangaUpdate struct {
 	URL         string
 }
 
+// ErrScrapeInProgress is returned when a website is already being scraped.
+var ErrScrapeInProgress = errors.New("a scrape of this website is already running")
+
+// ScrapeReport summarizes one check of a website.
+type ScrapeReport struct {
+	WebsiteID    uint      `json:"website_id"`
+	StartedAt    time.Time `json:"started_at"`
+	Duration     string    `json:"duration"`
+	UpdatesFound int       `json:"updates_found"`
+	NewMangas    int       `json:"new_mangas"`
+	NewChapters  int       `json:"new_chapters"`
+	MovedTo      string    `json:"moved_to,omitempty"`     // New base URL, if the website was moved there
+	RedirectsTo  string    `json:"redirects_to,omitempty"` // New base URL not yet seen on enough checks to move
+	Errors       []string  `json:"errors"`
+}
+
+func (r *ScrapeReport) addError(format string, args ...interface{}) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+}
+
 type ScraperService interface {
 	CheckForUpdates() error
+	ScrapeNow(websiteID uint) (*ScrapeReport, error)
 	ScrapeWebsite(website *models.Website) ([]MangaUpdate, error)
-	AddWebsite(website *models.Website) error
-	GetAllWebsites() ([]models.Website, error)
+	TestScraper(url, method, slug string) (*ScraperTestResult, error)
 }
 
 type scraperService struct {
 	websiteRepo         repositories.WebsiteRepository
 	mangaRepo           repositories.MangaRepository
 	chapterRepo         repositories.ChapterRepository
-	tagRepo             repositories.TagRepository
+	tagService          TagService
+	creatorService      CreatorService
 	notificationService NotificationService
+
+	mu        sync.Mutex
+	running   map[uint]bool     // Websites being scraped
+	redirects map[uint]seenMove // Domain moves seen on consecutive checks
+}
+
+// moveConfirmations is how many consecutive checks must find a website
+// permanently redirected to the same base URL before it is moved there.
+const moveConfirmations = 3
+
+// seenMove is a new base URL a website redirected to and on how many
+// consecutive checks.
+type seenMove struct {
+	url    string
+	checks int
 }
 
-func NewScraperService(websiteRepo repositories.WebsiteRepository, mangaRepo repositories.MangaRepository, chapterRepo repositories.ChapterRepository, tagRepo repositories.TagRepository, notificationService NotificationService) ScraperService {
+func NewScraperService(websiteRepo repositories.WebsiteRepository, mangaRepo repositories.MangaRepository, chapterRepo repositories.ChapterRepository, tagService TagService, creatorService CreatorService, notificationService NotificationService) ScraperService {
 	return &scraperService{
 		websiteRepo:         websiteRepo,
 		mangaRepo:           mangaRepo,
 		chapterRepo:         chapterRepo,
-		tagRepo:             tagRepo,
+		tagService:          tagService,
+		creatorService:      creatorService,
 		notificationService: notificationService,
+		running:             make(map[uint]bool),
+		redirects:           make(map[uint]seenMove),
 	}
 }
 
 // CheckForUpdates performs a check for updates on all registered websites.
-// It iterates through each website, scrapes for new manga updates if the last check was more than an hour ago,
-// and processes any new chapters found. For each new chapter, it updates the manga information,
+// It iterates through each website, scrapes for new manga updates if the website is due (enabled, inside its
+// active hours and past its check interval), and processes any new chapters found. For each new chapter, it updates the manga information,
 // creates a new chapter entry, recalculates the estimated next release, and sends a notification.
 //
 // This function handles various potential errors during the update process, logging them when encountered,
@@ -75,55 +141,144 @@ func (s *scraperService) CheckForUpdates() error {
 	}
 
 	for _, w := range websites {
-		if time.Since(w.LastChecked) < time.Hour {
+		if !websiteDue(&w, time.Now()) {
 			continue
 		}
+		report, err := s.checkWebsite(&w)
+		if errors.Is(err, ErrScrapeInProgress) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for _, e := range report.Errors {
+			log.Printf("Error scraping %s: %s", w.URL, e)
+		}
+	}
+	return nil
+}
+
+// ScrapeNow checks a website immediately, regardless of its schedule, and
+// reports the run.
+func (s *scraperService) ScrapeNow(websiteID uint) (*ScrapeReport, error) {
+	website, err := s.websiteRepo.FindByID(websiteID)
+	if err != nil {
+		return nil, err
+	}
+	return s.checkWebsite(website)
+}
+
+// checkWebsite scrapes a website, stores new chapters, notifies users and
+// records the check. Problems with single updates are collected in the report;
+// the returned error is reserved for a run that could not start or be recorded.
+func (s *scraperService) checkWebsite(w *models.Website) (*ScrapeReport, error) {
+	if !s.startRun(w.ID) {
+		return nil, ErrScrapeInProgress
+	}
+	defer s.finishRun(w.ID)
+
+	report := &ScrapeReport{WebsiteID: w.ID, StartedAt: time.Now(), Errors: []string{}}
+	defer func() { report.Duration = time.Since(report.StartedAt).String() }()
+
+	updates, err := s.scrapeWebsite(w, report)
+	var moved *scraper.MovedError
+	if errors.As(err, &moved) {
+		// A single redirect may be a maintenance page; the website is moved
+		// once consecutive checks agree, and the next check scrapes the new
+		// domain.
+		if s.seeMove(w.ID, moved.URL) < moveConfirmations {
+			report.RedirectsTo = moved.URL
+		} else if _, err := moveWebsite(s.websiteRepo, w, moved.URL); err != nil {
+			report.addError("moving website to %s: %v", moved.URL, err)
+		} else {
+			s.seeMove(w.ID, "")
+			report.MovedTo = moved.URL
+			log.Printf("Website %d moved to %s", w.ID, moved.URL)
+		}
+	} else {
+		s.seeMove(w.ID, "")
+		if err != nil {
+			report.addError("scrape failed: %v", err)
+		}
+	}
 
-		updates, err := s.ScrapeWebsite(&w)
+	for _, update := range updates {
+		manga, err := s.mangaRepo.FindByID(update.MangaID)
 		if err != nil {
-			log.Printf("Error scraping %s: %v", w.URL, err)
+			report.addError("manga not found: %d", update.MangaID)
 			continue
 		}
+		if update.NewChapter > manga.LastChapter {
+			manga.LastChapter = update.NewChapter
+			manga.UpdateTime = time.Now()
+			// A new chapter means the series is back on its source.
+			manga.SourceMisses = 0
+			manga.RemovedFromSourceAt = nil
 
-		for _, update := range updates {
-			manga, err := s.mangaRepo.FindByID(update.MangaID)
-			if err != nil {
-				log.Printf("Manga not found: %d", update.MangaID)
-				continue
+			newChapter := &models.Chapter{
+				MangaID:     manga.ID,
+				Number:      update.ChapterNum,
+				Title:       update.Title,
+				ReleaseDate: update.ReleaseDate,
+				URL:         update.URL,
 			}
-			if update.NewChapter > manga.LastChapter {
-				manga.LastChapter = update.NewChapter
-				manga.UpdateTime = time.Now()
-
-				newChapter := &models.Chapter{
-					MangaID:     manga.ID,
-					Number:      update.ChapterNum,
-					Title:       update.Title,
-					ReleaseDate: update.ReleaseDate,
-					URL:         update.URL,
-				}
-				if err := s.chapterRepo.Create(newChapter); err != nil {
-					log.Printf("Error creating chapter: %v", err)
-				}
-
-				chapters, _ := s.chapterRepo.FindByMangaID(manga.ID)
-				manga.EstimatedNext = calculateEstimatedNext(chapters)
-
-				if err := s.mangaRepo.Update(manga); err != nil {
-					log.Printf("Error updating manga: %v", err)
-				}
-
-				_ = s.notificationService.SendUpdateNotification(manga)
+			if err := s.chapterRepo.Create(newChapter); err != nil {
+				report.addError("creating chapter %s of %s: %v", update.NewChapter, manga.Title, err)
+			} else {
+				report.NewChapters++
 			}
-		}
 
-		w.LastChecked = time.Now()
-		err = s.websiteRepo.Update(&w)
-		if err != nil {
-			return err
+			chapters, _ := s.chapterRepo.FindByMangaID(manga.ID)
+			manga.EstimatedNext = calculateEstimatedNext(chapters)
+
+			if err := s.mangaRepo.Update(manga); err != nil {
+				report.addError("updating manga %s: %v", manga.Title, err)
+			}
+
+			_ = s.notificationService.SendUpdateNotification(manga, newChapter)
 		}
 	}
-	return nil
+
+	// Failed runs wait for the next interval too.
+	if err := s.websiteRepo.UpdateLastChecked(w.ID, time.Now()); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+func (s *scraperService) startRun(websiteID uint) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running[websiteID] {
+		return false
+	}
+	s.running[websiteID] = true
+	return true
+}
+
+// seeMove records that a check found the website redirected to url, or not
+// redirected when url is empty, and returns on how many consecutive checks
+// it was seen.
+func (s *scraperService) seeMove(websiteID uint, url string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if url == "" {
+		delete(s.redirects, websiteID)
+		return 0
+	}
+	seen := s.redirects[websiteID]
+	if seen.url != url {
+		seen = seenMove{url: url}
+	}
+	seen.checks++
+	s.redirects[websiteID] = seen
+	return seen.checks
+}
+
+func (s *scraperService) finishRun(websiteID uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.running, websiteID)
 }
 
 // ScrapeWebsite scrapes a given website for manga updates and returns a list of MangaUpdates.
@@ -137,6 +292,10 @@ func (s *scraperService) CheckForUpdates() error {
 //   - []MangaUpdate: A slice of MangaUpdate structs containing information about the latest manga updates.
 //   - error: An error if any occurred during the scraping process, or nil if successful.
 func (s *scraperService) ScrapeWebsite(website *models.Website) ([]MangaUpdate, error) {
+	return s.scrapeWebsite(website, &ScrapeReport{})
+}
+
+func (s *scraperService) scrapeWebsite(website *models.Website, report *ScrapeReport) ([]MangaUpdate, error) {
 	scraperForWebsite, ok := GetScraperForWebsite(website.URL)
 	if !ok {
 		return nil, fmt.Errorf("no scraperForWebsite found for website: %s", website.URL)
@@ -146,6 +305,7 @@ func (s *scraperService) ScrapeWebsite(website *models.Website) ([]MangaUpdate,
 	if err != nil {
 		return nil, err
 	}
+	report.UpdatesFound = len(updates)
 
 	var mangaUpdates []MangaUpdate
 	for _, update := range updates {
@@ -154,52 +314,77 @@ func (s *scraperService) ScrapeWebsite(website *models.Website) ([]MangaUpdate,
 			println("Manga not found: %s ... Try to add.", update.MangaSlug)
 			mangaDetails, err := scraperForWebsite.GetMangaDetails(update.MangaSlug)
 			if err != nil {
-				log.Printf("Error fetching manga details for %s: %v", update.MangaSlug, err)
+				report.addError("fetching manga details for %s: %v", update.MangaSlug, err)
 				continue
 			}
 			manga = &models.Manga{
-				Title:       mangaDetails.Title,
-				Description: mangaDetails.Description,
-				Author:      mangaDetails.Author,
-				WebsiteID:   website.ID,
+				Title:               mangaDetails.Title,
+				AltTitles:           strings.Join(mangaDetails.AltTitles, "; "),
+				Description:         mangaDetails.Description,
+				Author:              mangaDetails.Author,
+				Status:              mangaDetails.Status,
+				CoverURL:            mangaDetails.CoverURL,
+				Slug:                update.MangaSlug,
+				ExternalURL:         website.URL + "manga/" + update.MangaSlug + "/",
+				WebsiteID:           website.ID,
+				MetadataRefreshedAt: time.Now(),
 			}
 			err = s.mangaRepo.Create(manga)
 			if err != nil {
-				log.Printf("Error creating manga %s: %v", mangaDetails.Title, err)
+				report.addError("creating manga %s: %v", mangaDetails.Title, err)
 				continue
 			}
-			for _, tag := range mangaDetails.Tags {
-				err = s.tagRepo.AddTagToManga(manga.ID, tag)
-				if err != nil {
-					log.Printf("Error adding tag %s to manga %s: %v", tag, manga.Title, err)
-				}
+			report.NewMangas++
+			if err := s.tagService.TagManga(manga.ID, mangaDetails.Tags); err != nil {
+				report.addError("tagging manga %s: %v", manga.Title, err)
+			}
+			authors := mangaDetails.Authors
+			if len(authors) == 0 {
+				authors = scraper.SplitAuthors(mangaDetails.Author)
+			}
+			creators, err := s.creatorService.CreditManga(manga.ID, authors, mangaDetails.Artists)
+			if err != nil {
+				report.addError("crediting manga %s: %v", manga.Title, err)
+			}
+			if err := s.notificationService.SendNewWorkNotification(manga, creators); err != nil {
+				report.addError("notifying followers about %s: %v", manga.Title, err)
 			}
 		}
 
-		chapterNum, _ := strconv.ParseUint(strings.TrimPrefix(update.ChapterNumber, "Chapter "), 10, 32)
+		chapterNum, _ := chapterNumber(update.ChapterNumber)
+		chapterURL := update.ChapterURL
+		if chapterURL == "" {
+			chapterURL = website.URL + "manga/" + update.MangaSlug + "/" + update.ChapterNumber
+		}
 		mangaUpdates = append(mangaUpdates, MangaUpdate{
 			MangaID:     manga.ID,
 			NewChapter:  update.ChapterNumber,
-			ChapterNum:  uint(chapterNum),
+			ChapterNum:  chapterNum,
 			Title:       update.MangaTitle,
 			ReleaseDate: time.Now(),
-			URL:         website.URL + "manga/" + update.MangaSlug + "/" + update.ChapterNumber,
+			URL:         chapterURL,
 		})
 	}
 
 	return mangaUpdates, nil
 }
 
-func (s *scraperService) AddWebsite(website *models.Website) error {
-	return s.websiteRepo.Create(website)
-}
-
-func (s *scraperService) GetAllWebsites() ([]models.Website, error) {
-	return s.websiteRepo.FindAll()
-}
-
+// calculateEstimatedNext projects the next release from the latest chapter and
+// the median release interval, defaulting to a weekly cadence when the history
+// is too short. Estimates that have already passed are rolled forward.
 func calculateEstimatedNext(chapters []models.Chapter) time.Time {
-	// Implement logic to estimate next chapter release
-	// This is a placeholder implementation
-	return time.Now().Add(24 * time.Hour)
+	now := time.Now()
+	latest, ok := latestReleaseDate(chapters)
+	if !ok {
+		return now.Add(defaultCadence)
+	}
+	cadence, ok := releaseCadence(chapters)
+	if !ok {
+		cadence = defaultCadence
+	}
+	next := latest.Add(cadence)
+	for next.Before(now) {
+		next = next.Add(cadence)
+	}
+	return next
 }
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sidler1/manga-backend/scraper"
+)
+
+// ErrInvalidScraperTest is returned for dry runs with an unknown method or a
+// missing slug.
+var ErrInvalidScraperTest = errors.New("invalid scraper test")
+
+// Scraper methods available to dry runs.
+const (
+	ScraperMethodLatest   = "latest"
+	ScraperMethodDetails  = "details"
+	ScraperMethodChapters = "chapters"
+)
+
+// ScraperTestResult is the outcome of a dry run. Result holds the parsed
+// scraper output; Warnings lists data the scraper is likely misreading.
+type ScraperTestResult struct {
+	Scraper  string      `json:"scraper"`
+	Method   string      `json:"method"`
+	Slug     string      `json:"slug,omitempty"`
+	Result   interface{} `json:"result"`
+	Warnings []string    `json:"warnings"`
+	Duration string      `json:"duration"`
+}
+
+// TestScraper runs a scraper method against url without touching the
+// database. The scraper is chosen by the url's base; details and chapters take
+// the slug from a "<base>manga/<slug>/" url unless one is given.
+func (s *scraperService) TestScraper(url, method, slug string) (*ScraperTestResult, error) {
+	base, sc, ok := scraperForURL(url)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoScraper, url)
+	}
+	result := &ScraperTestResult{Scraper: base, Method: method, Warnings: []string{}}
+	if method != ScraperMethodLatest {
+		if slug == "" {
+			slug = slugFromURL(base, url)
+		}
+		if slug == "" {
+			return nil, fmt.Errorf("%w: %s needs a manga url or slug", ErrInvalidScraperTest, method)
+		}
+		result.Slug = slug
+	}
+
+	start := time.Now()
+	var err error
+	switch method {
+	case ScraperMethodLatest:
+		var updates []scraper.Update
+		updates, err = sc.GetLatestUpdates()
+		result.Result, result.Warnings = updates, updateWarnings(updates)
+	case ScraperMethodDetails:
+		var manga scraper.Manga
+		manga, err = sc.GetMangaDetails(slug)
+		result.Result, result.Warnings = manga, mangaWarnings(manga)
+	case ScraperMethodChapters:
+		var chapters []scraper.Chapter
+		chapters, err = sc.GetChapterList(slug)
+		result.Result, result.Warnings = chapters, chapterWarnings(chapters)
+	default:
+		return nil, fmt.Errorf("%w: method must be %s, %s or %s", ErrInvalidScraperTest, ScraperMethodLatest, ScraperMethodDetails, ScraperMethodChapters)
+	}
+	result.Duration = time.Since(start).String()
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// scraperForURL finds the registered scraper whose base url prefixes url.
+func scraperForURL(url string) (string, scraper.Scraper, bool) {
+	url = normalizeWebsiteURL(url)
+	scrapersMu.RLock()
+	defer scrapersMu.RUnlock()
+	for base, sc := range scrapers {
+		if strings.HasPrefix(url, base) {
+			return base, sc, true
+		}
+	}
+	return "", nil, false
+}
+
+// slugFromURL extracts the manga slug from a "<base>manga/<slug>/..." url.
+func slugFromURL(base, url string) string {
+	path := strings.TrimPrefix(strings.TrimSpace(url), base)
+	if !strings.HasPrefix(path, "manga/") {
+		return ""
+	}
+	slug, _, _ := strings.Cut(strings.TrimPrefix(path, "manga/"), "/")
+	return slug
+}
+
+// chapterNumber parses a scraped chapter label the way update checks do.
+func chapterNumber(label string) (uint, bool) {
+	n, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimSpace(label), "Chapter "), 10, 32)
+	return uint(n), err == nil
+}
+
+func updateWarnings(updates []scraper.Update) []string {
+	warnings := []string{}
+	if len(updates) == 0 {
+		return append(warnings, "no updates found")
+	}
+	for i, u := range updates {
+		if _, ok := chapterNumber(u.ChapterNumber); !ok {
+			warnings = append(warnings, fmt.Sprintf("update %d (%s): unparsable chapter number %q", i, u.MangaSlug, u.ChapterNumber))
+		}
+		if u.UpdateDate == "" {
+			warnings = append(warnings, fmt.Sprintf("update %d (%s): missing date", i, u.MangaSlug))
+		}
+	}
+	return warnings
+}
+
+func mangaWarnings(m scraper.Manga) []string {
+	warnings := []string{}
+	for _, field := range []struct {
+		name  string
+		empty bool
+	}{
+		{"title", m.Title == ""},
+		{"description", m.Description == ""},
+		{"status", m.Status == ""},
+		{"cover_url", m.CoverURL == ""},
+		{"tags", len(m.Tags) == 0},
+		{"authors", len(m.Authors) == 0},
+	} {
+		if field.empty {
+			warnings = append(warnings, "missing "+field.name)
+		}
+	}
+	return warnings
+}
+
+func chapterWarnings(chapters []scraper.Chapter) []string {
+	warnings := []string{}
+	if len(chapters) == 0 {
+		return append(warnings, "no chapters found")
+	}
+	seen := make(map[uint]bool)
+	for i, ch := range chapters {
+		n, ok := chapterNumber(ch.Number)
+		switch {
+		case !ok:
+			warnings = append(warnings, fmt.Sprintf("chapter %d: unparsable number %q", i, ch.Number))
+		case seen[n]:
+			warnings = append(warnings, fmt.Sprintf("chapter %d: duplicate number %d", i, n))
+		}
+		seen[n] = true
+		if ch.Date == "" {
+			warnings = append(warnings, fmt.Sprintf("chapter %d: missing date", i))
+		}
+		if ch.URL == "" {
+			warnings = append(warnings, fmt.Sprintf("chapter %d: missing url", i))
+		}
+	}
+	return warnings
+}
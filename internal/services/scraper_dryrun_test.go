@@ -0,0 +1,35 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/sidler1/manga-backend/scraper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlugFromURL(t *testing.T) {
+	base := "https://www.mangaread.org/"
+	assert.Equal(t, "solo-leveling", slugFromURL(base, "https://www.mangaread.org/manga/solo-leveling/"))
+	assert.Equal(t, "solo-leveling", slugFromURL(base, "https://www.mangaread.org/manga/solo-leveling/chapter-1/"))
+	assert.Equal(t, "", slugFromURL(base, "https://www.mangaread.org/"))
+}
+
+func TestChapterWarnings(t *testing.T) {
+	warnings := chapterWarnings([]scraper.Chapter{
+		{Number: "Chapter 2", Date: "May 1, 2025", URL: "u2"},
+		{Number: "Chapter 2", Date: "May 1, 2025", URL: "u2b"},
+		{Number: "Extra", URL: "u3"},
+	})
+	assert.Equal(t, []string{
+		"chapter 1: duplicate number 2",
+		`chapter 2: unparsable number "Extra"`,
+		"chapter 2: missing date",
+	}, warnings)
+
+	assert.Equal(t, []string{"no chapters found"}, chapterWarnings(nil))
+}
+
+func TestMangaWarnings(t *testing.T) {
+	warnings := mangaWarnings(scraper.Manga{Title: "Solo Leveling", Description: "d", Status: "Ongoing", Tags: []string{"Action"}})
+	assert.Equal(t, []string{"missing cover_url", "missing authors"}, warnings)
+}
@@ -0,0 +1,19 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScraperService_SeeMove(t *testing.T) {
+	s := &scraperService{redirects: make(map[uint]seenMove)}
+
+	assert.Equal(t, 1, s.seeMove(1, "https://new.example/"))
+	assert.Equal(t, 2, s.seeMove(1, "https://new.example/"))
+	assert.Equal(t, 1, s.seeMove(2, "https://new.example/"))
+	assert.Equal(t, 1, s.seeMove(1, "https://other.example/"), "another target starts over")
+
+	assert.Equal(t, 0, s.seeMove(1, ""))
+	assert.Equal(t, 1, s.seeMove(1, "https://other.example/"), "a check without redirect starts over")
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrTagConflict is returned when a name or alias already identifies another tag.
+	ErrTagConflict = errors.New("tag name or alias already in use")
+	// ErrInvalidTag is returned for names without any letter or digit.
+	ErrInvalidTag = errors.New("tag name must contain a letter or digit")
+	// ErrInvalidMerge is returned for merges without sources or into a source.
+	ErrInvalidMerge = errors.New("invalid tag merge")
+	// ErrInvalidTagClass is returned for unknown categories or content ratings.
+	ErrInvalidTagClass = errors.New("invalid tag category or content rating")
+)
+
+// TagUpdate holds the fields of a tag to change; nil fields are left as they are.
+type TagUpdate struct {
+	Name          *string
+	Category      *string
+	ContentRating *string // An empty rating clears it
+}
+
+type TagService interface {
+	ListTags(category string) ([]repositories.TagUsage, error)
+	CreateTag(name, category, contentRating string) (*models.Tag, error)
+	UpdateTag(id uint, update TagUpdate) (*models.Tag, error)
+	DeleteTag(id uint) error
+	GetAliases(tagID uint) ([]models.TagAlias, error)
+	AddAlias(tagID uint, alias string) (*models.TagAlias, error)
+	RemoveAlias(id uint) error
+	MergeTags(targetID uint, sourceIDs []uint) error
+	Resolve(text string) (*models.Tag, error)
+	TagManga(mangaID uint, names []string) error
+}
+
+type tagService struct {
+	tagRepo repositories.TagRepository
+}
+
+func NewTagService(tagRepo repositories.TagRepository) TagService {
+	return &tagService{tagRepo: tagRepo}
+}
+
+func (s *tagService) ListTags(category string) ([]repositories.TagUsage, error) {
+	if category != "" && !IsValidTagCategory(category) {
+		return nil, ErrInvalidTagClass
+	}
+	return s.tagRepo.FindAllWithUsage(category)
+}
+
+// CreateTag creates a tag. An empty category uses the tag's default class from
+// repositories.DefaultTagTaxonomy, including its content rating.
+func (s *tagService) CreateTag(name, category, contentRating string) (*models.Tag, error) {
+	name = cleanTagName(name)
+	key := repositories.TagKey(name)
+	if key == "" {
+		return nil, ErrInvalidTag
+	}
+	if category == "" {
+		class := repositories.ClassifyTag(key)
+		category = class.Category
+		if contentRating == "" {
+			contentRating = class.ContentRating
+		}
+	}
+	if !IsValidTagCategory(category) || !isValidTagRating(contentRating) {
+		return nil, ErrInvalidTagClass
+	}
+	if err := s.ensureKeyFree(key, 0); err != nil {
+		return nil, err
+	}
+	tag := &models.Tag{Name: name, Key: key, Category: category, ContentRating: contentRating}
+	return tag, s.tagRepo.Create(tag)
+}
+
+func (s *tagService) UpdateTag(id uint, update TagUpdate) (*models.Tag, error) {
+	tag, err := s.tagRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if update.Name != nil {
+		name := cleanTagName(*update.Name)
+		key := repositories.TagKey(name)
+		if key == "" {
+			return nil, ErrInvalidTag
+		}
+		if err := s.ensureKeyFree(key, id); err != nil {
+			return nil, err
+		}
+		tag.Name = name
+		tag.Key = key
+	}
+	if update.Category != nil {
+		if !IsValidTagCategory(*update.Category) {
+			return nil, ErrInvalidTagClass
+		}
+		tag.Category = *update.Category
+	}
+	if update.ContentRating != nil {
+		if !isValidTagRating(*update.ContentRating) {
+			return nil, ErrInvalidTagClass
+		}
+		tag.ContentRating = *update.ContentRating
+	}
+	return tag, s.tagRepo.Update(tag)
+}
+
+func (s *tagService) DeleteTag(id uint) error {
+	if _, err := s.tagRepo.FindByID(id); err != nil {
+		return err
+	}
+	return s.tagRepo.Delete(id)
+}
+
+func (s *tagService) GetAliases(tagID uint) ([]models.TagAlias, error) {
+	if _, err := s.tagRepo.FindByID(tagID); err != nil {
+		return nil, err
+	}
+	return s.tagRepo.FindAliases(tagID)
+}
+
+func (s *tagService) AddAlias(tagID uint, text string) (*models.TagAlias, error) {
+	if _, err := s.tagRepo.FindByID(tagID); err != nil {
+		return nil, err
+	}
+	text = cleanTagName(text)
+	key := repositories.TagKey(text)
+	if key == "" {
+		return nil, ErrInvalidTag
+	}
+	if err := s.ensureKeyFree(key, tagID); err != nil {
+		return nil, err
+	}
+	// An alias the tag already owns is returned as is; creating it again
+	// would trip the unique key.
+	if existing, err := s.tagRepo.FindAliasByKey(key); err == nil {
+		return existing, nil
+	}
+	alias := &models.TagAlias{TagID: tagID, Alias: text, Key: key}
+	return alias, s.tagRepo.CreateAlias(alias)
+}
+
+func (s *tagService) RemoveAlias(id uint) error {
+	if _, err := s.tagRepo.FindAliasByID(id); err != nil {
+		return err
+	}
+	return s.tagRepo.DeleteAlias(id)
+}
+
+func (s *tagService) MergeTags(targetID uint, sourceIDs []uint) error {
+	if _, err := s.tagRepo.FindByID(targetID); err != nil {
+		return err
+	}
+	seen := make(map[uint]bool, len(sourceIDs))
+	unique := make([]uint, 0, len(sourceIDs))
+	for _, id := range sourceIDs {
+		if id == targetID {
+			return fmt.Errorf("%w: cannot merge tag %d into itself", ErrInvalidMerge, id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) == 0 {
+		return fmt.Errorf("%w: no source tags given", ErrInvalidMerge)
+	}
+	return s.tagRepo.Merge(targetID, unique)
+}
+
+// Resolve maps scraped genre text onto its canonical tag: an alias wins, then
+// a tag with the same normalized name; otherwise a new tag is created.
+func (s *tagService) Resolve(text string) (*models.Tag, error) {
+	name := cleanTagName(text)
+	key := repositories.TagKey(name)
+	if key == "" {
+		return nil, ErrInvalidTag
+	}
+	alias, err := s.tagRepo.FindAliasByKey(key)
+	if err == nil {
+		return s.tagRepo.FindByID(alias.TagID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	tag, err := s.tagRepo.FindByKey(key)
+	if err == nil {
+		return tag, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return s.tagRepo.FindOrCreate(name)
+}
+
+// TagManga attaches the canonical tags of the given genre texts to a manga.
+func (s *tagService) TagManga(mangaID uint, names []string) error {
+	var errs []error
+	for _, name := range names {
+		tag, err := s.Resolve(name)
+		if errors.Is(err, ErrInvalidTag) {
+			continue
+		}
+		if err == nil {
+			err = s.tagRepo.AttachToManga(mangaID, tag.ID)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tag %q: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ensureKeyFree checks that key does not already identify a tag other than
+// ownerID, either by name or by alias.
+func (s *tagService) ensureKeyFree(key string, ownerID uint) error {
+	tag, err := s.tagRepo.FindByKey(key)
+	if err == nil && tag.ID != ownerID {
+		return ErrTagConflict
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	alias, err := s.tagRepo.FindAliasByKey(key)
+	if err == nil && alias.TagID != ownerID {
+		return ErrTagConflict
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// IsValidTagCategory reports whether category is one of the tag categories.
+func IsValidTagCategory(category string) bool {
+	switch category {
+	case models.TagCategoryGenre, models.TagCategoryTheme, models.TagCategoryDemographic,
+		models.TagCategoryFormat, models.TagCategoryContentWarning:
+		return true
+	}
+	return false
+}
+
+// isValidTagRating reports whether a tag may imply rating. Safe is the absence
+// of a rating, so tags carry either nothing or a restricting rating.
+func isValidTagRating(rating string) bool {
+	switch rating {
+	case "", models.ContentRatingSuggestive, models.ContentRatingAdult:
+		return true
+	}
+	return false
+}
+
+// cleanTagName trims a display name and collapses inner whitespace.
+func cleanTagName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
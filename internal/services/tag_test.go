@@ -0,0 +1,154 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeTagRepo keeps tags and aliases in memory, matched by key.
+type fakeTagRepo struct {
+	repositories.TagRepository
+	tags    []models.Tag
+	aliases []models.TagAlias
+	created []string
+	merged  []uint
+}
+
+func (r *fakeTagRepo) FindByID(id uint) (*models.Tag, error) {
+	for i := range r.tags {
+		if r.tags[i].ID == id {
+			return &r.tags[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeTagRepo) FindByKey(key string) (*models.Tag, error) {
+	for i := range r.tags {
+		if r.tags[i].Key == key {
+			return &r.tags[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeTagRepo) FindAliasByKey(key string) (*models.TagAlias, error) {
+	for i := range r.aliases {
+		if r.aliases[i].Key == key {
+			return &r.aliases[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeTagRepo) FindOrCreate(name string) (*models.Tag, error) {
+	r.created = append(r.created, name)
+	tag := models.Tag{Model: gorm.Model{ID: uint(100 + len(r.created))}, Name: name, Key: repositories.TagKey(name)}
+	r.tags = append(r.tags, tag)
+	return &tag, nil
+}
+
+// CreateAlias enforces the unique alias key like the database does.
+func (r *fakeTagRepo) CreateAlias(alias *models.TagAlias) error {
+	if _, err := r.FindAliasByKey(alias.Key); err == nil {
+		return gorm.ErrDuplicatedKey
+	}
+	r.aliases = append(r.aliases, *alias)
+	return nil
+}
+
+func (r *fakeTagRepo) Merge(targetID uint, sourceIDs []uint) error {
+	r.merged = sourceIDs
+	return nil
+}
+
+func newFakeTagRepo() *fakeTagRepo {
+	return &fakeTagRepo{
+		tags: []models.Tag{
+			{Model: gorm.Model{ID: 1}, Name: "Sci-Fi", Key: "scifi"},
+			{Model: gorm.Model{ID: 2}, Name: "Romance", Key: "romance"},
+			{Model: gorm.Model{ID: 3}, Name: "Love", Key: "love"},
+		},
+		aliases: []models.TagAlias{{Model: gorm.Model{ID: 1}, TagID: 1, Alias: "Science Fiction", Key: "sciencefiction"}},
+	}
+}
+
+func TestTagService_Resolve(t *testing.T) {
+	tests := []struct {
+		text  string
+		tagID uint
+	}{
+		{"Science  fiction", 1}, // Alias
+		{"sci fi", 1},           // Same key as the tag's name
+		{"ROMANCE", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			repo := newFakeTagRepo()
+			tag, err := NewTagService(repo).Resolve(tt.text)
+			require.NoError(t, err)
+			assert.Equal(t, tt.tagID, tag.ID)
+			assert.Empty(t, repo.created)
+		})
+	}
+
+	repo := newFakeTagRepo()
+	tag, err := NewTagService(repo).Resolve("  Slice   of Life ")
+	require.NoError(t, err)
+	assert.Equal(t, "Slice of Life", tag.Name)
+	assert.Equal(t, []string{"Slice of Life"}, repo.created)
+
+	_, err = NewTagService(repo).Resolve("???")
+	assert.ErrorIs(t, err, ErrInvalidTag)
+}
+
+func TestTagService_AddAlias(t *testing.T) {
+	repo := newFakeTagRepo()
+	s := NewTagService(repo)
+
+	alias, err := s.AddAlias(2, " Love  Story ")
+	require.NoError(t, err)
+	assert.Equal(t, models.TagAlias{TagID: 2, Alias: "Love Story", Key: "lovestory"}, *alias)
+
+	_, err = s.AddAlias(2, "love") // Name of tag 3
+	assert.ErrorIs(t, err, ErrTagConflict)
+	_, err = s.AddAlias(2, "Science Fiction") // Alias of tag 1
+	assert.ErrorIs(t, err, ErrTagConflict)
+	alias, err = s.AddAlias(1, "science-fiction") // Already its own alias
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), alias.ID)
+	assert.Len(t, repo.aliases, 2)
+}
+
+func TestTagService_MergeTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  uint
+		sources []uint
+		merged  []uint
+		err     error
+	}{
+		{"duplicates are dropped", 2, []uint{3, 3, 1}, []uint{3, 1}, nil},
+		{"into itself", 2, []uint{3, 2}, nil, ErrInvalidMerge},
+		{"no sources", 2, nil, nil, ErrInvalidMerge},
+		{"unknown target", 9, []uint{3}, nil, gorm.ErrRecordNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeTagRepo()
+			err := NewTagService(repo).MergeTags(tt.target, tt.sources)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				assert.Nil(t, repo.merged)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.merged, repo.merged)
+		})
+	}
+}
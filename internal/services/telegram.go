@@ -0,0 +1,428 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"github.com/sidler1/manga-backend/notifier"
+	"gorm.io/gorm"
+)
+
+const (
+	// telegramLinkCodeTTL is how long a link code can be sent to the bot.
+	telegramLinkCodeTTL = 15 * time.Minute
+	// telegramListLimit caps the entries of /list, /next and search replies.
+	telegramListLimit = 10
+)
+
+const telegramHelp = `<b>Manga Tracker</b>
+/follow &lt;title or ID&gt; – follow a manga
+/unfollow &lt;title or ID&gt; – stop following a manga
+/list – mangas you follow
+/next – chapters to read next
+/unlink – stop updates in this chat`
+
+const telegramPrivateOnly = "Manage your account in a private chat with the bot."
+
+// Callback data prefixes of inline buttons, followed by a manga ID. Mark
+// read buttons use notifier.TelegramCallbackMarkRead and add the chapter.
+const (
+	telegramCallbackFollow   = "follow:"
+	telegramCallbackUnfollow = "unfollow:"
+)
+
+// TelegramLink is a one-time code linking a Telegram chat to an account.
+type TelegramLink struct {
+	Code      string    `json:"code"`
+	URL       string    `json:"url"` // Opens the bot and sends the code
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type TelegramService interface {
+	CreateLink(userID uint) (*TelegramLink, error)
+	HandleUpdate(update notifier.TelegramUpdate)
+	Poll(ctx context.Context)
+}
+
+type telegramService struct {
+	client       *notifier.TelegramClient
+	botUsername  string
+	telegramRepo repositories.TelegramRepository
+	channelRepo  repositories.ChannelRepository
+	mangaService MangaService
+}
+
+func NewTelegramService(client *notifier.TelegramClient, botUsername string, telegramRepo repositories.TelegramRepository, channelRepo repositories.ChannelRepository, mangaService MangaService) TelegramService {
+	return &telegramService{
+		client:       client,
+		botUsername:  botUsername,
+		telegramRepo: telegramRepo,
+		channelRepo:  channelRepo,
+		mangaService: mangaService,
+	}
+}
+
+// CreateLink issues a code the user sends to the bot, as "/start <code>",
+// from the chat to link.
+func (s *telegramService) CreateLink(userID uint) (*TelegramLink, error) {
+	code, err := randomToken(8)
+	if err != nil {
+		return nil, err
+	}
+	linkCode := &models.TelegramLinkCode{Code: code, UserID: userID, ExpiresAt: time.Now().Add(telegramLinkCodeTTL)}
+	if err := s.telegramRepo.CreateLinkCode(linkCode); err != nil {
+		return nil, err
+	}
+	return &TelegramLink{
+		Code:      code,
+		URL:       "https://t.me/" + s.botUsername + "?start=" + code,
+		ExpiresAt: linkCode.ExpiresAt,
+	}, nil
+}
+
+// Poll receives updates by long polling until ctx is done. Used when no
+// webhook is configured.
+func (s *telegramService) Poll(ctx context.Context) {
+	var offset int64
+	for ctx.Err() == nil {
+		updates, err := s.client.GetUpdates(offset)
+		if err != nil {
+			log.Printf("Error polling Telegram updates: %v", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(5 * time.Second):
+			}
+			continue
+		}
+		for _, update := range updates {
+			offset = update.UpdateID + 1
+			s.HandleUpdate(update)
+		}
+	}
+}
+
+// HandleUpdate answers a bot command or an inline button press.
+func (s *telegramService) HandleUpdate(update notifier.TelegramUpdate) {
+	switch {
+	case update.Message != nil:
+		s.handleCommand(update.Message)
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		s.handleCallback(update.CallbackQuery)
+	}
+}
+
+func (s *telegramService) handleCommand(msg *notifier.TelegramMessage) {
+	command, arg, _ := strings.Cut(strings.TrimSpace(msg.Text), " ")
+	command, _, _ = strings.Cut(command, "@") // Commands in groups carry the bot name
+	arg = strings.TrimSpace(arg)
+	chatID := msg.Chat.ID
+
+	if command == "/start" || command == "/link" {
+		if arg == "" {
+			s.reply(chatID, telegramHelp+"\n\nTo get updates here, request a link code in the app and send /start &lt;code&gt;.", nil)
+			return
+		}
+		s.reply(chatID, s.link(chatID, arg), nil)
+		return
+	}
+
+	// Everyone in a group linked to an account could otherwise change it.
+	if msg.Chat.Type != notifier.TelegramChatPrivate {
+		if strings.HasPrefix(command, "/") {
+			s.reply(chatID, telegramPrivateOnly, nil)
+		}
+		return
+	}
+	subscription, err := s.channelRepo.FindByChannelTarget(notifier.ChannelTelegram, chatTarget(chatID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		s.reply(chatID, "This chat is not linked to an account yet. Request a link code in the app and send /start &lt;code&gt;.", nil)
+		return
+	}
+	if err != nil {
+		log.Printf("Error finding Telegram chat %d: %v", chatID, err)
+		return
+	}
+	userID := subscription.UserID
+
+	switch command {
+	case "/follow":
+		s.reply(s.follow(chatID, userID, arg))
+	case "/unfollow":
+		s.reply(s.unfollow(chatID, userID, arg))
+	case "/list":
+		s.reply(chatID, s.list(userID), nil)
+	case "/next":
+		s.reply(chatID, s.next(userID), nil)
+	case "/unlink":
+		if err := s.channelRepo.Delete(subscription.ID); err != nil {
+			log.Printf("Error unlinking Telegram chat %d: %v", chatID, err)
+			s.reply(chatID, "Something went wrong, please try again.", nil)
+			return
+		}
+		s.reply(chatID, "This chat no longer gets updates.", nil)
+	default:
+		s.reply(chatID, telegramHelp, nil)
+	}
+}
+
+func (s *telegramService) handleCallback(query *notifier.TelegramCallbackQuery) {
+	chatID := query.Message.Chat.ID
+	var answer string
+	subscription, err := s.channelRepo.FindByChannelTarget(notifier.ChannelTelegram, chatTarget(chatID))
+	switch {
+	case query.Message.Chat.Type != notifier.TelegramChatPrivate:
+		answer = telegramPrivateOnly
+	case err != nil:
+		answer = "This chat is not linked to an account"
+	default:
+		answer = s.callback(subscription.UserID, query.Data)
+	}
+	if err := s.client.AnswerCallbackQuery(query.ID, answer); err != nil {
+		log.Printf("Error answering Telegram callback: %v", err)
+	}
+}
+
+// callback runs the action of a button and returns the text to show.
+func (s *telegramService) callback(userID uint, data string) string {
+	action, args, ok := strings.Cut(data, ":")
+	id, chapterArg, hasChapter := strings.Cut(args, ":")
+	mangaID, err := strconv.ParseUint(id, 10, 32)
+	if !ok || err != nil {
+		return "Unknown action"
+	}
+	switch action + ":" {
+	case notifier.TelegramCallbackMarkRead:
+		chapter, err := strconv.ParseUint(chapterArg, 10, 32)
+		if hasChapter && err != nil {
+			return "Unknown action"
+		}
+		if !hasChapter {
+			// Buttons sent before they carried the chapter mark the latest.
+			latest, err := s.latestChapter(uint(mangaID))
+			if err != nil {
+				return "No chapters found"
+			}
+			chapter = uint64(latest)
+		}
+		if err := s.mangaService.SetBookmark(userID, uint(mangaID), uint(chapter)); err != nil {
+			return "Something went wrong"
+		}
+		return fmt.Sprintf("Marked read up to chapter %d", chapter)
+	case telegramCallbackFollow:
+		if err := s.mangaService.FavoriteManga(userID, uint(mangaID)); err != nil {
+			return "Could not follow: " + err.Error()
+		}
+		return "Following"
+	case telegramCallbackUnfollow:
+		if err := s.mangaService.UnfavoriteManga(userID, uint(mangaID)); err != nil {
+			return "Could not unfollow: " + err.Error()
+		}
+		return "Unfollowed"
+	}
+	return "Unknown action"
+}
+
+// latestChapter returns the highest chapter number of a manga.
+func (s *telegramService) latestChapter(mangaID uint) (uint, error) {
+	chapters, err := s.mangaService.GetMangaChapters(mangaID)
+	if err != nil {
+		return 0, err
+	}
+	if len(chapters) == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	latest := chapters[0].Number
+	for _, chapter := range chapters {
+		latest = max(latest, chapter.Number)
+	}
+	return latest, nil
+}
+
+// link consumes a link code and sets up delivery to the chat for its user.
+// A chat linked before is moved to the new account.
+func (s *telegramService) link(chatID int64, code string) string {
+	linkCode, err := s.telegramRepo.ConsumeLinkCode(code, time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "This link code is invalid or expired. Request a new one in the app."
+	}
+	if err != nil {
+		log.Printf("Error consuming Telegram link code: %v", err)
+		return "Something went wrong, please try again."
+	}
+
+	subscription, err := s.channelRepo.FindByChannelTarget(notifier.ChannelTelegram, chatTarget(chatID))
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		err = s.channelRepo.Create(&models.ChannelSubscription{
+			UserID:  linkCode.UserID,
+			Channel: notifier.ChannelTelegram,
+			Target:  chatTarget(chatID),
+			Enabled: true,
+		})
+	case err == nil:
+		subscription.UserID = linkCode.UserID
+		subscription.Enabled = true
+		err = s.channelRepo.Update(subscription)
+	}
+	if err != nil {
+		log.Printf("Error linking Telegram chat %d: %v", chatID, err)
+		return "Something went wrong, please try again."
+	}
+	return "Linked! New chapters of the mangas you follow will show up here.\n\n" + telegramHelp
+}
+
+// follow adds a favorite by ID or title. Ambiguous titles get a button per
+// candidate instead.
+func (s *telegramService) follow(chatID int64, userID uint, arg string) (int64, string, *notifier.TelegramKeyboard) {
+	if arg == "" {
+		return chatID, "Usage: /follow &lt;title or ID&gt;", nil
+	}
+	if id, err := strconv.ParseUint(arg, 10, 32); err == nil {
+		manga, err := s.mangaService.GetByID(uint(id))
+		if err != nil {
+			return chatID, "No manga with ID " + arg + ".", nil
+		}
+		return chatID, s.followManga(userID, manga.ID, manga.Title), nil
+	}
+
+	suggestions, err := s.mangaService.SuggestMangas(userID, arg, telegramListLimit)
+	if err != nil {
+		return chatID, "Something went wrong, please try again.", nil
+	}
+	if len(suggestions) == 0 {
+		return chatID, "No manga found for " + html.EscapeString(arg) + ".", nil
+	}
+	if len(suggestions) == 1 || strings.EqualFold(suggestions[0].Title, arg) {
+		return chatID, s.followManga(userID, suggestions[0].ID, suggestions[0].Title), nil
+	}
+	keyboard := &notifier.TelegramKeyboard{}
+	for _, suggestion := range suggestions {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []notifier.TelegramButton{{
+			Text:         suggestion.Title,
+			CallbackData: telegramCallbackFollow + strconv.FormatUint(uint64(suggestion.ID), 10),
+		}})
+	}
+	return chatID, "Which one?", keyboard
+}
+
+func (s *telegramService) followManga(userID, mangaID uint, title string) string {
+	if err := s.mangaService.FavoriteManga(userID, mangaID); err != nil {
+		return "Could not follow " + html.EscapeString(title) + ": " + html.EscapeString(err.Error())
+	}
+	return "Following <b>" + html.EscapeString(title) + "</b>."
+}
+
+// unfollow removes a favorite by ID or part of its title.
+func (s *telegramService) unfollow(chatID int64, userID uint, arg string) (int64, string, *notifier.TelegramKeyboard) {
+	if arg == "" {
+		return chatID, "Usage: /unfollow &lt;title or ID&gt;", nil
+	}
+	favorites, err := s.mangaService.GetUserFavorites(userID)
+	if err != nil {
+		return chatID, "Something went wrong, please try again.", nil
+	}
+	var matches []models.Manga
+	for _, manga := range favorites {
+		if strconv.FormatUint(uint64(manga.ID), 10) == arg || strings.Contains(strings.ToLower(manga.Title), strings.ToLower(arg)) {
+			matches = append(matches, manga)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return chatID, "You do not follow " + html.EscapeString(arg) + ".", nil
+	case 1:
+		if err := s.mangaService.UnfavoriteManga(userID, matches[0].ID); err != nil {
+			return chatID, "Something went wrong, please try again.", nil
+		}
+		return chatID, "Stopped following <b>" + html.EscapeString(matches[0].Title) + "</b>.", nil
+	}
+	keyboard := &notifier.TelegramKeyboard{}
+	for _, manga := range matches[:min(len(matches), telegramListLimit)] {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []notifier.TelegramButton{{
+			Text:         manga.Title,
+			CallbackData: telegramCallbackUnfollow + strconv.FormatUint(uint64(manga.ID), 10),
+		}})
+	}
+	return chatID, "Which one?", keyboard
+}
+
+func (s *telegramService) list(userID uint) string {
+	favorites, err := s.mangaService.GetUserFavorites(userID)
+	if err != nil {
+		return "Something went wrong, please try again."
+	}
+	if len(favorites) == 0 {
+		return "You do not follow any manga yet. Try /follow &lt;title&gt;."
+	}
+	var b strings.Builder
+	b.WriteString("<b>You follow</b>")
+	for _, manga := range favorites {
+		fmt.Fprintf(&b, "\n• %s (%d)", html.EscapeString(manga.Title), manga.ID)
+		if manga.LastChapter != "" {
+			b.WriteString(" – " + html.EscapeString(manga.LastChapter))
+		}
+	}
+	return b.String()
+}
+
+// next lists, per favorite, the first chapter after the user's bookmark, or
+// the first chapter of favorites without one.
+func (s *telegramService) next(userID uint) string {
+	favorites, err := s.mangaService.GetUserFavorites(userID)
+	if err != nil {
+		return "Something went wrong, please try again."
+	}
+	var b strings.Builder
+	found := 0
+	for _, manga := range favorites {
+		if found == telegramListLimit {
+			break
+		}
+		bookmark, err := s.mangaService.GetBookmark(userID, manga.ID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			bookmark = 0 // Nothing read yet; start from the first chapter
+		} else if err != nil {
+			continue
+		}
+		chapters, err := s.mangaService.GetMangaChapters(manga.ID)
+		if err != nil {
+			continue
+		}
+		var next *models.Chapter
+		for i := range chapters {
+			if chapters[i].Number > bookmark && (next == nil || chapters[i].Number < next.Number) {
+				next = &chapters[i]
+			}
+		}
+		if next == nil {
+			continue
+		}
+		found++
+		fmt.Fprintf(&b, "\n• %s – chapter %d", html.EscapeString(manga.Title), next.Number)
+		if next.URL != "" {
+			b.WriteString(` <a href="` + html.EscapeString(next.URL) + `">read</a>`)
+		}
+	}
+	if found == 0 {
+		return "You are all caught up."
+	}
+	return "<b>Read next</b>" + b.String()
+}
+
+func (s *telegramService) reply(chatID int64, text string, keyboard *notifier.TelegramKeyboard) {
+	if err := s.client.SendMessage(chatID, text, keyboard); err != nil {
+		log.Printf("Error replying to Telegram chat %d: %v", chatID, err)
+	}
+}
+
+func chatTarget(chatID int64) string {
+	return strconv.FormatInt(chatID, 10)
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"github.com/sidler1/manga-backend/notifier"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeTelegramAPI records the text of sent messages and callback answers.
+func fakeTelegramAPI(t *testing.T) (*notifier.TelegramClient, *[]string) {
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params map[string]any
+		json.NewDecoder(r.Body).Decode(&params)
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/sendMessage"):
+			sent = append(sent, params["text"].(string))
+		case strings.HasSuffix(r.URL.Path, "/answerCallbackQuery"):
+			sent = append(sent, params["text"].(string))
+		}
+		w.Write([]byte(`{"ok": true, "result": {}}`))
+	}))
+	t.Cleanup(server.Close)
+	return notifier.NewTelegramClient(server.URL, "token"), &sent
+}
+
+type telegramChannelRepo struct {
+	repositories.ChannelRepository
+	subscriptions []models.ChannelSubscription
+}
+
+func (r *telegramChannelRepo) FindByChannelTarget(channel, target string) (*models.ChannelSubscription, error) {
+	for i, sub := range r.subscriptions {
+		if sub.Channel == channel && sub.Target == target {
+			return &r.subscriptions[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *telegramChannelRepo) Create(subscription *models.ChannelSubscription) error {
+	r.subscriptions = append(r.subscriptions, *subscription)
+	return nil
+}
+
+type telegramLinkRepo struct {
+	codes map[string]models.TelegramLinkCode
+}
+
+func (r *telegramLinkRepo) CreateLinkCode(code *models.TelegramLinkCode) error {
+	r.codes[code.Code] = *code
+	return nil
+}
+
+func (r *telegramLinkRepo) ConsumeLinkCode(code string, now time.Time) (*models.TelegramLinkCode, error) {
+	linkCode, ok := r.codes[code]
+	if !ok || !linkCode.ExpiresAt.After(now) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	delete(r.codes, code)
+	return &linkCode, nil
+}
+
+type telegramMangaService struct {
+	MangaService
+	favorited []uint
+	bookmarks map[uint]uint
+}
+
+func (s *telegramMangaService) SuggestMangas(viewerID uint, query string, limit int) ([]repositories.MangaSuggestion, error) {
+	return []repositories.MangaSuggestion{{ID: 3, Title: "Solo Leveling"}}, nil
+}
+
+func (s *telegramMangaService) FavoriteManga(userID, mangaID uint) error {
+	s.favorited = append(s.favorited, mangaID)
+	return nil
+}
+
+func (s *telegramMangaService) GetMangaChapters(mangaID uint) ([]models.Chapter, error) {
+	return []models.Chapter{{Number: 11}, {Number: 12}, {Number: 10}}, nil
+}
+
+func (s *telegramMangaService) GetUserFavorites(userID uint) ([]models.Manga, error) {
+	return []models.Manga{{Model: gorm.Model{ID: 3}, Title: "Solo Leveling"}}, nil
+}
+
+func (s *telegramMangaService) GetBookmark(userID, mangaID uint) (uint, error) {
+	chapter, ok := s.bookmarks[mangaID]
+	if !ok {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return chapter, nil
+}
+
+func (s *telegramMangaService) SetBookmark(userID, mangaID, chapter uint) error {
+	s.bookmarks[mangaID] = chapter
+	return nil
+}
+
+func command(chatID int64, text string) notifier.TelegramUpdate {
+	return notifier.TelegramUpdate{Message: &notifier.TelegramMessage{Chat: privateChat(chatID), Text: text}}
+}
+
+func privateChat(chatID int64) notifier.TelegramChat {
+	return notifier.TelegramChat{ID: chatID, Type: notifier.TelegramChatPrivate}
+}
+
+func markRead(chat notifier.TelegramChat, data string) notifier.TelegramUpdate {
+	return notifier.TelegramUpdate{CallbackQuery: &notifier.TelegramCallbackQuery{
+		ID:      "q1",
+		Data:    notifier.TelegramCallbackMarkRead + data,
+		Message: &notifier.TelegramMessage{Chat: chat},
+	}}
+}
+
+func TestTelegramService_LinkAndFollow(t *testing.T) {
+	client, sent := fakeTelegramAPI(t)
+	channelRepo := &telegramChannelRepo{}
+	mangaService := &telegramMangaService{}
+	s := NewTelegramService(client, "manga_bot", &telegramLinkRepo{codes: map[string]models.TelegramLinkCode{}}, channelRepo, mangaService)
+
+	s.HandleUpdate(command(4242, "/follow solo"))
+	assert.Contains(t, (*sent)[0], "not linked")
+	assert.Empty(t, mangaService.favorited)
+
+	link, err := s.CreateLink(7)
+	require.NoError(t, err)
+	assert.Equal(t, "https://t.me/manga_bot?start="+link.Code, link.URL)
+	s.HandleUpdate(command(4242, "/start "+link.Code))
+	require.Len(t, channelRepo.subscriptions, 1)
+	assert.Equal(t, uint(7), channelRepo.subscriptions[0].UserID)
+	assert.Equal(t, "4242", channelRepo.subscriptions[0].Target)
+
+	s.HandleUpdate(command(4242, "/follow@manga_bot solo"))
+	assert.Equal(t, []uint{3}, mangaService.favorited)
+	assert.Equal(t, "Following <b>Solo Leveling</b>.", (*sent)[len(*sent)-1])
+
+	s.HandleUpdate(command(5555, "/start "+link.Code)) // Codes are single use
+	assert.Contains(t, (*sent)[len(*sent)-1], "invalid or expired")
+}
+
+func TestTelegramService_MarkRead(t *testing.T) {
+	tests := []struct {
+		data    string
+		chapter uint
+	}{
+		{"3:11", 11}, // The chapter of the notification
+		{"3", 12},    // Older buttons mark the latest chapter
+	}
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			client, sent := fakeTelegramAPI(t)
+			channelRepo := &telegramChannelRepo{subscriptions: []models.ChannelSubscription{{UserID: 7, Channel: notifier.ChannelTelegram, Target: "4242"}}}
+			mangaService := &telegramMangaService{bookmarks: map[uint]uint{}}
+			s := NewTelegramService(client, "manga_bot", nil, channelRepo, mangaService)
+
+			s.HandleUpdate(markRead(privateChat(4242), tt.data))
+
+			assert.Equal(t, map[uint]uint{3: tt.chapter}, mangaService.bookmarks)
+			assert.Equal(t, []string{fmt.Sprintf("Marked read up to chapter %d", tt.chapter)}, *sent)
+		})
+	}
+}
+
+func TestTelegramService_GroupChatsCannotManageAccount(t *testing.T) {
+	client, sent := fakeTelegramAPI(t)
+	channelRepo := &telegramChannelRepo{subscriptions: []models.ChannelSubscription{{UserID: 7, Channel: notifier.ChannelTelegram, Target: "-100"}}}
+	mangaService := &telegramMangaService{bookmarks: map[uint]uint{}}
+	s := NewTelegramService(client, "manga_bot", nil, channelRepo, mangaService)
+	group := notifier.TelegramChat{ID: -100, Type: "group"}
+
+	s.HandleUpdate(notifier.TelegramUpdate{Message: &notifier.TelegramMessage{Chat: group, Text: "/follow solo"}})
+	s.HandleUpdate(notifier.TelegramUpdate{Message: &notifier.TelegramMessage{Chat: group, Text: "nice chapter"}})
+	s.HandleUpdate(markRead(group, "3:11"))
+
+	assert.Empty(t, mangaService.favorited)
+	assert.Empty(t, mangaService.bookmarks)
+	assert.Equal(t, []string{telegramPrivateOnly, telegramPrivateOnly}, *sent)
+}
+
+func TestTelegramService_Next(t *testing.T) {
+	tests := []struct {
+		name      string
+		bookmarks map[uint]uint
+		reply     string
+	}{
+		{"no bookmark", map[uint]uint{}, "chapter 10"},
+		{"bookmarked", map[uint]uint{3: 10}, "chapter 11"},
+		{"caught up", map[uint]uint{3: 12}, "You are all caught up."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, sent := fakeTelegramAPI(t)
+			channelRepo := &telegramChannelRepo{subscriptions: []models.ChannelSubscription{{UserID: 7, Channel: notifier.ChannelTelegram, Target: "4242"}}}
+			s := NewTelegramService(client, "manga_bot", nil, channelRepo, &telegramMangaService{bookmarks: tt.bookmarks})
+
+			s.HandleUpdate(command(4242, "/next"))
+
+			require.Len(t, *sent, 1)
+			assert.Contains(t, (*sent)[0], tt.reply)
+		})
+	}
+}
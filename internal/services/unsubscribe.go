@@ -0,0 +1,57 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidUnsubscribeToken is returned for unsubscribe tokens that are
+// malformed or carry a wrong signature.
+var ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+
+// UnsubscribeSigner issues and checks the tokens of one-click unsubscribe
+// links. A token names a channel subscription and, optionally, a manga; it
+// is signed so links need no login and cannot be forged for other users.
+type UnsubscribeSigner struct {
+	secret []byte
+}
+
+func NewUnsubscribeSigner(secret string) *UnsubscribeSigner {
+	return &UnsubscribeSigner{secret: []byte(secret)}
+}
+
+// Token returns the token stopping the subscription, or only notifications
+// about the manga over it when mangaID is not zero.
+func (s *UnsubscribeSigner) Token(subscriptionID, mangaID uint) string {
+	payload := fmt.Sprintf("%d.%d", subscriptionID, mangaID)
+	return payload + "." + s.sign(payload)
+}
+
+// Parse verifies token and returns the subscription and manga it names.
+func (s *UnsubscribeSigner) Parse(token string) (subscriptionID, mangaID uint, err error) {
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 || !hmac.Equal([]byte(token[i+1:]), []byte(s.sign(token[:i]))) {
+		return 0, 0, ErrInvalidUnsubscribeToken
+	}
+	subscription, manga, _ := strings.Cut(token[:i], ".")
+	subID, err := strconv.ParseUint(subscription, 10, 32)
+	if err != nil {
+		return 0, 0, ErrInvalidUnsubscribeToken
+	}
+	mID, err := strconv.ParseUint(manga, 10, 32)
+	if err != nil {
+		return 0, 0, ErrInvalidUnsubscribeToken
+	}
+	return uint(subID), uint(mID), nil
+}
+
+func (s *UnsubscribeSigner) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("unsubscribe:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnsubscribeSigner(t *testing.T) {
+	signer := NewUnsubscribeSigner("secret")
+
+	subscriptionID, mangaID, err := signer.Parse(signer.Token(12, 345))
+	require.NoError(t, err)
+	assert.Equal(t, uint(12), subscriptionID)
+	assert.Equal(t, uint(345), mangaID)
+
+	_, mangaID, err = signer.Parse(signer.Token(12, 0))
+	require.NoError(t, err)
+	assert.Zero(t, mangaID)
+
+	token := signer.Token(12, 345)
+	_, _, err = signer.Parse("13" + token[2:])
+	assert.ErrorIs(t, err, ErrInvalidUnsubscribeToken)
+	_, _, err = NewUnsubscribeSigner("other").Parse(token)
+	assert.ErrorIs(t, err, ErrInvalidUnsubscribeToken)
+	_, _, err = signer.Parse("garbage")
+	assert.ErrorIs(t, err, ErrInvalidUnsubscribeToken)
+}
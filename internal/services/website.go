@@ -0,0 +1,273 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	neturl "net/url"
+	"strings"
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNoScraper is returned for websites no registered scraper can handle.
+	ErrNoScraper = errors.New("no scraper registered for website")
+	// ErrInvalidWebsite is returned for out-of-range schedule settings.
+	ErrInvalidWebsite = errors.New("invalid website settings")
+)
+
+const maxCheckInterval = 7 * 24 * 60 // Minutes
+
+// WebsiteInput holds the website fields of an update; nil fields are left as
+// they are.
+type WebsiteInput struct {
+	Name          *string `json:"name"`
+	URL           *string `json:"url"`
+	Enabled       *bool   `json:"enabled"`
+	CheckInterval *int    `json:"check_interval"` // Minutes
+	ActiveFrom    *int    `json:"active_from"`    // Hour of day, UTC
+	ActiveUntil   *int    `json:"active_until"`   // Hour of day, UTC
+}
+
+type WebsiteService interface {
+	ListWebsites() ([]models.Website, error)
+	AddWebsite(url, name string) (*models.Website, error)
+	UpdateWebsite(id uint, input WebsiteInput) (*models.Website, error)
+	DeleteWebsite(id uint) error
+	MoveWebsite(id uint, url string) (*repositories.WebsiteMove, error)
+	AddAlias(websiteID uint, url string) (*models.WebsiteAlias, error)
+	RemoveAlias(id uint) error
+	SyncScrapers() error
+}
+
+type websiteService struct {
+	websiteRepo repositories.WebsiteRepository
+}
+
+func NewWebsiteService(websiteRepo repositories.WebsiteRepository) WebsiteService {
+	return &websiteService{websiteRepo: websiteRepo}
+}
+
+func (s *websiteService) ListWebsites() ([]models.Website, error) {
+	return s.websiteRepo.FindAll()
+}
+
+// AddWebsite registers a website for scraping. The URL must belong to a
+// registered scraper. A previously deleted website with the same URL is
+// restored instead of duplicated.
+func (s *websiteService) AddWebsite(url, name string) (*models.Website, error) {
+	url = normalizeWebsiteURL(url)
+	if _, ok := GetScraperForWebsite(url); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoScraper, url)
+	}
+	if alias, err := s.websiteRepo.FindAliasByURL(url); err == nil {
+		return nil, fmt.Errorf("%w: %s is an alias of website %d", ErrInvalidWebsite, url, alias.WebsiteID)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	existing, err := s.websiteRepo.FindByURLWithDeleted(url)
+	if err == nil {
+		if !existing.DeletedAt.Valid {
+			return nil, fmt.Errorf("%w: %s is already registered", ErrInvalidWebsite, url)
+		}
+		existing.Name = name
+		existing.Enabled = true
+		return existing, s.websiteRepo.Restore(existing)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	website := &models.Website{URL: url, Name: name, Enabled: true, CheckInterval: 60}
+	return website, s.websiteRepo.Create(website)
+}
+
+// UpdateWebsite applies the non-nil fields of input. Changing the URL moves
+// the website as MoveWebsite does.
+func (s *websiteService) UpdateWebsite(id uint, input WebsiteInput) (*models.Website, error) {
+	website, err := s.websiteRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if input.Name != nil {
+		website.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Enabled != nil {
+		website.Enabled = *input.Enabled
+	}
+	if input.CheckInterval != nil {
+		if *input.CheckInterval < 1 || *input.CheckInterval > maxCheckInterval {
+			return nil, fmt.Errorf("%w: check_interval must be between 1 and %d minutes", ErrInvalidWebsite, maxCheckInterval)
+		}
+		website.CheckInterval = *input.CheckInterval
+	}
+	for _, hour := range []struct {
+		value *int
+		dst   *int
+	}{{input.ActiveFrom, &website.ActiveFrom}, {input.ActiveUntil, &website.ActiveUntil}} {
+		if hour.value == nil {
+			continue
+		}
+		if *hour.value < 0 || *hour.value > 23 {
+			return nil, fmt.Errorf("%w: active hours must be between 0 and 23", ErrInvalidWebsite)
+		}
+		*hour.dst = *hour.value
+	}
+	// A new URL is a move: it needs the alias, URL rewrite and scraper re-key
+	// of MoveWebsite, so it runs after the other fields are validated.
+	if input.URL != nil && normalizeWebsiteURL(*input.URL) != website.URL {
+		if _, err := moveWebsite(s.websiteRepo, website, *input.URL); err != nil {
+			return nil, err
+		}
+	}
+	return website, s.websiteRepo.Update(website)
+}
+
+// DeleteWebsite soft-deletes a website; its mangas are kept.
+func (s *websiteService) DeleteWebsite(id uint) error {
+	if _, err := s.websiteRepo.FindByID(id); err != nil {
+		return err
+	}
+	return s.websiteRepo.Delete(id)
+}
+
+// MoveWebsite moves a website to a new base URL, for example after a domain
+// change. The old URL is kept as an alias, stored manga and chapter URLs are
+// rewritten and the website's scraper is re-keyed.
+func (s *websiteService) MoveWebsite(id uint, url string) (*repositories.WebsiteMove, error) {
+	website, err := s.websiteRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return moveWebsite(s.websiteRepo, website, url)
+}
+
+// AddAlias records another base URL of a website.
+func (s *websiteService) AddAlias(websiteID uint, url string) (*models.WebsiteAlias, error) {
+	if _, err := s.websiteRepo.FindByID(websiteID); err != nil {
+		return nil, err
+	}
+	url = normalizeWebsiteURL(url)
+	if err := validateWebsiteURL(url); err != nil {
+		return nil, err
+	}
+	if err := ensureWebsiteURLFree(s.websiteRepo, url, 0); err != nil {
+		return nil, err
+	}
+	alias := &models.WebsiteAlias{WebsiteID: websiteID, URL: url}
+	return alias, s.websiteRepo.CreateAlias(alias)
+}
+
+func (s *websiteService) RemoveAlias(id uint) error {
+	if _, err := s.websiteRepo.FindAliasByID(id); err != nil {
+		return err
+	}
+	return s.websiteRepo.DeleteAlias(id)
+}
+
+// SyncScrapers re-keys scrapers registered under an old URL of a website that
+// has since moved. It runs at startup, after RegisterScrapers.
+func (s *websiteService) SyncScrapers() error {
+	websites, err := s.websiteRepo.FindAll()
+	if err != nil {
+		return err
+	}
+	for _, w := range websites {
+		if _, ok := GetScraperForWebsite(w.URL); ok {
+			continue
+		}
+		for _, alias := range w.Aliases {
+			if MoveScraper(alias.URL, w.URL) {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// moveWebsite validates to and moves website there, re-keying its scraper.
+func moveWebsite(websiteRepo repositories.WebsiteRepository, website *models.Website, to string) (*repositories.WebsiteMove, error) {
+	to = normalizeWebsiteURL(to)
+	if err := validateWebsiteURL(to); err != nil {
+		return nil, err
+	}
+	if to == website.URL {
+		return nil, fmt.Errorf("%w: website is already at %s", ErrInvalidWebsite, to)
+	}
+	if err := ensureWebsiteURLFree(websiteRepo, to, website.ID); err != nil {
+		return nil, err
+	}
+	if _, ok := GetScraperForWebsite(website.URL); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoScraper, website.URL)
+	}
+	move, err := websiteRepo.Move(website, to)
+	if err != nil {
+		return nil, err
+	}
+	MoveScraper(move.From, move.To)
+	return move, nil
+}
+
+// ensureWebsiteURLFree checks that url is neither the URL nor an alias of a
+// website other than ownerID.
+func ensureWebsiteURLFree(websiteRepo repositories.WebsiteRepository, url string, ownerID uint) error {
+	website, err := websiteRepo.FindByURLWithDeleted(url)
+	if err == nil && website.ID != ownerID {
+		return fmt.Errorf("%w: %s is used by website %d", ErrInvalidWebsite, url, website.ID)
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	alias, err := websiteRepo.FindAliasByURL(url)
+	if err == nil && alias.WebsiteID != ownerID {
+		return fmt.Errorf("%w: %s is an alias of website %d", ErrInvalidWebsite, url, alias.WebsiteID)
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// validateWebsiteURL checks that url is an absolute http(s) URL.
+func validateWebsiteURL(rawURL string) error {
+	u, err := neturl.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: %s is not an http(s) URL", ErrInvalidWebsite, rawURL)
+	}
+	return nil
+}
+
+// websiteDue reports whether a website should be checked at now: it must be
+// enabled, inside its active hours and its check interval must have elapsed.
+func websiteDue(w *models.Website, now time.Time) bool {
+	if !w.Enabled {
+		return false
+	}
+	if w.ActiveFrom != w.ActiveUntil {
+		hour := now.UTC().Hour()
+		if w.ActiveFrom < w.ActiveUntil {
+			if hour < w.ActiveFrom || hour >= w.ActiveUntil {
+				return false
+			}
+		} else if hour < w.ActiveFrom && hour >= w.ActiveUntil { // Window wraps past midnight
+			return false
+		}
+	}
+	interval := time.Duration(w.CheckInterval) * time.Minute
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return now.Sub(w.LastChecked) >= interval
+}
+
+// normalizeWebsiteURL trims a website URL and gives it the trailing slash the
+// scraper registry uses.
+func normalizeWebsiteURL(url string) string {
+	url = strings.TrimSpace(url)
+	if !strings.HasSuffix(url, "/") {
+		url += "/"
+	}
+	return url
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sidler1/manga-backend/internal/models"
+	"github.com/sidler1/manga-backend/internal/repositories"
+	"github.com/sidler1/manga-backend/scraper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestWebsiteDue_Interval(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	w := &models.Website{Enabled: true, CheckInterval: 30, LastChecked: now.Add(-20 * time.Minute)}
+	assert.False(t, websiteDue(w, now))
+
+	w.LastChecked = now.Add(-30 * time.Minute)
+	assert.True(t, websiteDue(w, now))
+
+	w.Enabled = false
+	assert.False(t, websiteDue(w, now))
+}
+
+func TestWebsiteDue_ActiveHours(t *testing.T) {
+	w := &models.Website{Enabled: true, CheckInterval: 10, ActiveFrom: 8, ActiveUntil: 20}
+	assert.True(t, websiteDue(w, time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)))
+	assert.False(t, websiteDue(w, time.Date(2025, 3, 1, 20, 0, 0, 0, time.UTC)))
+
+	// A window past midnight: 22:00 to 06:00.
+	w.ActiveFrom, w.ActiveUntil = 22, 6
+	assert.True(t, websiteDue(w, time.Date(2025, 3, 1, 23, 0, 0, 0, time.UTC)))
+	assert.True(t, websiteDue(w, time.Date(2025, 3, 1, 5, 0, 0, 0, time.UTC)))
+	assert.False(t, websiteDue(w, time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)))
+}
+
+func TestValidateWebsiteURL(t *testing.T) {
+	assert.NoError(t, validateWebsiteURL("https://www.mangaread.net/"))
+	assert.ErrorIs(t, validateWebsiteURL("www.mangaread.net/"), ErrInvalidWebsite)
+	assert.ErrorIs(t, validateWebsiteURL("ftp://mangaread.net/"), ErrInvalidWebsite)
+}
+
+func TestMoveScraper(t *testing.T) {
+	from, to := "https://old.example/", "https://new.example/"
+	scrapersMu.Lock()
+	scrapers[from] = scraper.NewMangaReadScraper()
+	scrapersMu.Unlock()
+	defer func() {
+		scrapersMu.Lock()
+		delete(scrapers, to)
+		scrapersMu.Unlock()
+	}()
+
+	assert.True(t, MoveScraper(from, to))
+	_, ok := GetScraperForWebsite(from)
+	assert.False(t, ok)
+	sc, ok := GetScraperForWebsite(to)
+	assert.True(t, ok)
+	assert.Equal(t, to, sc.GetBaseUrl())
+	assert.False(t, MoveScraper(from, to))
+}
+
+// fakeWebsiteRepo keeps websites in memory and records moves.
+type fakeWebsiteRepo struct {
+	repositories.WebsiteRepository
+	websites []models.Website
+	moves    []repositories.WebsiteMove
+	updated  int
+}
+
+func (r *fakeWebsiteRepo) FindByID(id uint) (*models.Website, error) {
+	for i := range r.websites {
+		if r.websites[i].ID == id {
+			website := r.websites[i]
+			return &website, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeWebsiteRepo) FindByURLWithDeleted(url string) (*models.Website, error) {
+	for i := range r.websites {
+		if r.websites[i].URL == url {
+			return &r.websites[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeWebsiteRepo) FindAliasByURL(url string) (*models.WebsiteAlias, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeWebsiteRepo) Move(website *models.Website, to string) (*repositories.WebsiteMove, error) {
+	move := repositories.WebsiteMove{From: website.URL, To: to}
+	r.moves = append(r.moves, move)
+	website.URL = to
+	return &move, nil
+}
+
+func (r *fakeWebsiteRepo) Update(website *models.Website) error {
+	r.updated++
+	return nil
+}
+
+func TestWebsiteService_UpdateWebsiteURL(t *testing.T) {
+	from, to := "https://old.example/", "https://new.example/"
+	scrapersMu.Lock()
+	scrapers[from] = scraper.NewMangaReadScraper()
+	scrapersMu.Unlock()
+	defer func() {
+		scrapersMu.Lock()
+		delete(scrapers, from)
+		delete(scrapers, to)
+		scrapersMu.Unlock()
+	}()
+
+	repo := &fakeWebsiteRepo{websites: []models.Website{
+		{Model: gorm.Model{ID: 1}, URL: from},
+		{Model: gorm.Model{ID: 2}, URL: "https://taken.example/"},
+	}}
+	s := NewWebsiteService(repo)
+
+	taken := "https://taken.example"
+	_, err := s.UpdateWebsite(1, WebsiteInput{URL: &taken})
+	assert.ErrorIs(t, err, ErrInvalidWebsite)
+
+	interval := 0
+	_, err = s.UpdateWebsite(1, WebsiteInput{URL: &to, CheckInterval: &interval})
+	assert.ErrorIs(t, err, ErrInvalidWebsite)
+	assert.Empty(t, repo.moves, "nothing moves when another field is invalid")
+
+	same := from
+	_, err = s.UpdateWebsite(1, WebsiteInput{URL: &same})
+	require.NoError(t, err)
+	assert.Empty(t, repo.moves)
+
+	website, err := s.UpdateWebsite(1, WebsiteInput{URL: &to})
+	require.NoError(t, err)
+	assert.Equal(t, to, website.URL)
+	assert.Equal(t, []repositories.WebsiteMove{{From: from, To: to}}, repo.moves)
+	_, ok := GetScraperForWebsite(to)
+	assert.True(t, ok, "the scraper is re-keyed")
+}
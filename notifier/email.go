@@ -0,0 +1,143 @@
+package notifier
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	texttemplate "text/template"
+	"time"
+)
+
+// ChannelEmail is the channel name of the email notifier.
+const ChannelEmail = "email"
+
+//go:embed templates
+var templates embed.FS
+
+// SMTPConfig holds the settings of the server emails are sent through.
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string // Optional; authentication is skipped without it
+	Password string
+	From     string // Sender, e.g. "Manga Tracker <updates@example.com>"
+}
+
+// EmailNotifier delivers messages as multipart HTML and plain-text emails
+// over SMTP. The destination target is the recipient address.
+type EmailNotifier struct {
+	cfg  SMTPConfig
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// NewEmailNotifier initializes the notifier with the embedded templates.
+func NewEmailNotifier(cfg SMTPConfig) Notifier {
+	return &EmailNotifier{
+		cfg:  cfg,
+		text: texttemplate.Must(texttemplate.ParseFS(templates, "templates/*.txt")),
+		html: htmltemplate.Must(htmltemplate.ParseFS(templates, "templates/*.html")),
+	}
+}
+
+func (n *EmailNotifier) Channel() string {
+	return ChannelEmail
+}
+
+func (n *EmailNotifier) Validate(dest Destination) error {
+	if _, err := mail.ParseAddress(dest.Target); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDestination, err)
+	}
+	return nil
+}
+
+// Send renders msg and hands it to the SMTP server. Rejections with a 5xx
+// reply, such as an unknown recipient, are permanent.
+func (n *EmailNotifier) Send(dest Destination, msg Message) error {
+	from, err := mail.ParseAddress(n.cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender %q: %w", n.cfg.From, err)
+	}
+	to, err := mail.ParseAddress(dest.Target)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	body, err := n.render(from, to, msg)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if n.cfg.Username != "" {
+		auth = smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)
+	}
+	err = smtp.SendMail(net.JoinHostPort(n.cfg.Host, n.cfg.Port), auth, from.Address, []string{to.Address}, body)
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	return err
+}
+
+// render builds the email: headers, then a plain-text and an HTML part.
+func (n *EmailNotifier) render(from, to *mail.Address, msg Message) ([]byte, error) {
+	name := "chapter"
+	subject := msg.Body
+	if msg.Type == MessageTypeDigest {
+		name, subject = "digest", msg.Title
+	}
+
+	var buf bytes.Buffer
+	parts := multipart.NewWriter(&buf)
+	header := func(key, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+	header("From", from.String())
+	header("To", to.String())
+	header("Subject", mime.QEncoding.Encode("utf-8", subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	if msg.UnsubscribeURL != "" {
+		// RFC 8058 one-click unsubscribe
+		header("List-Unsubscribe", "<"+msg.UnsubscribeURL+">")
+		header("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	}
+	header("Content-Type", "multipart/alternative; boundary="+parts.Boundary())
+	buf.WriteString("\r\n")
+
+	for _, part := range []struct {
+		contentType string
+		execute     func(w *quotedprintable.Writer) error
+	}{
+		{"text/plain", func(w *quotedprintable.Writer) error { return n.text.ExecuteTemplate(w, name+".txt", msg) }},
+		{"text/html", func(w *quotedprintable.Writer) error { return n.html.ExecuteTemplate(w, name+".html", msg) }},
+	} {
+		pw, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType + "; charset=utf-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(pw)
+		if err := part.execute(qp); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,123 @@
+package notifier
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTPServer accepts one SMTP session and records the message data. A
+// non-empty rcptReply replaces the reply to RCPT TO.
+func fakeSMTPServer(t *testing.T, rcptReply string) (host, port string, data <-chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	received := make(chan string, 1)
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+		reply("220 fake ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 fake")
+			case strings.HasPrefix(cmd, "RCPT") && rcptReply != "":
+				reply(rcptReply)
+			case strings.HasPrefix(cmd, "DATA"):
+				reply("354 go ahead")
+				var msg strings.Builder
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					msg.WriteString(line)
+				}
+				received <- msg.String()
+				reply("250 queued")
+			case strings.HasPrefix(cmd, "QUIT"):
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	host, port, _ = net.SplitHostPort(ln.Addr().String())
+	return host, port, received
+}
+
+func TestEmailNotifier_Send(t *testing.T) {
+	host, port, data := fakeSMTPServer(t, "")
+	n := NewEmailNotifier(SMTPConfig{Host: host, Port: port, From: "Manga Tracker <updates@example.com>"})
+
+	err := n.Send(Destination{Target: "reader@example.com"}, Message{
+		Type:                "chapter",
+		Title:               "New chapter",
+		Body:                "New chapter available for Solo Leveling: Chapter 12",
+		URL:                 "https://www.mangaread.org/manga/solo-leveling/",
+		UnsubscribeURL:      "https://tracker.example/api/v1/unsubscribe/all-token",
+		MangaUnsubscribeURL: "https://tracker.example/api/v1/unsubscribe/manga-token",
+	})
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(strings.NewReader(<-data))
+	require.NoError(t, err)
+	assert.Equal(t, "<reader@example.com>", msg.Header.Get("To"))
+	assert.Equal(t, "New chapter available for Solo Leveling: Chapter 12", msg.Header.Get("Subject"))
+	assert.Equal(t, "<https://tracker.example/api/v1/unsubscribe/all-token>", msg.Header.Get("List-Unsubscribe"))
+	assert.Equal(t, "List-Unsubscribe=One-Click", msg.Header.Get("List-Unsubscribe-Post"))
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	var types []string
+	for {
+		part, err := parts.NextPart() // Decodes quoted-printable
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		body, _ := io.ReadAll(part)
+		types = append(types, strings.Split(part.Header.Get("Content-Type"), ";")[0])
+		assert.Contains(t, string(body), "https://tracker.example/api/v1/unsubscribe/manga-token")
+		assert.Contains(t, string(body), "https://tracker.example/api/v1/unsubscribe/all-token")
+	}
+	assert.Equal(t, []string{"text/plain", "text/html"}, types)
+}
+
+func TestEmailNotifier_Send_RejectedRecipientIsPermanent(t *testing.T) {
+	host, port, _ := fakeSMTPServer(t, "550 no such user")
+	n := NewEmailNotifier(SMTPConfig{Host: host, Port: port, From: "updates@example.com"})
+
+	err := n.Send(Destination{Target: "gone@example.com"}, Message{Title: "New chapter", Body: "New chapter"})
+
+	assert.ErrorIs(t, err, ErrPermanent)
+}
+
+func TestEmailNotifier_Validate(t *testing.T) {
+	n := NewEmailNotifier(SMTPConfig{})
+	assert.NoError(t, n.Validate(Destination{Target: "reader@example.com"}))
+	assert.ErrorIs(t, n.Validate(Destination{Target: "not an address"}), ErrInvalidDestination)
+}
@@ -0,0 +1,69 @@
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Message is a notification rendered for delivery over a channel.
+type Message struct {
+	Type     string // Notification type, e.g. "chapter"; MessageTypeDigest for batched messages
+	Title    string
+	Body     string
+	URL      string    // Link to read, if any
+	MangaID  uint      // Manga the message is about, if any
+	Chapter  uint      // Chapter number of chapter messages, if known
+	Priority int       // PriorityMin to PriorityMax; zero means PriorityDefault
+	SentAt   time.Time // When the notification was created
+	Items    []Message // Entries of a digest
+
+	UnsubscribeURL      string // Signed one-click link that stops this channel
+	MangaUnsubscribeURL string // Signed one-click link that stops this manga on this channel
+}
+
+const (
+	// MessageTypeDigest is the type of messages batching several notifications.
+	MessageTypeDigest = "digest"
+	// MessageTypeTest is the type of test messages sent on request.
+	MessageTypeTest = "test"
+)
+
+// Message priorities, on the 1 to 5 scale of ntfy.
+const (
+	PriorityMin     = 1
+	PriorityLow     = 2
+	PriorityDefault = 3
+	PriorityHigh    = 4
+	PriorityMax     = 5
+)
+
+// Destination is where a channel delivers to, as set up by a user.
+type Destination struct {
+	Target string // Channel-specific address, e.g. an email address or webhook URL
+	Config string // Channel-specific JSON settings, may be empty
+}
+
+// Notifier defines the interface for notification delivery channels.
+type Notifier interface {
+	Channel() string                          // Name of the channel, e.g. "email"
+	Validate(dest Destination) error          // Check a destination before it is saved
+	Send(dest Destination, msg Message) error // Deliver one message
+}
+
+// EventFilter is implemented by notifiers whose destinations choose the
+// message types they receive. Others receive every message.
+type EventFilter interface {
+	Accepts(dest Destination, msgType string) bool
+}
+
+// ErrPermanent marks delivery errors that retrying cannot fix, such as a
+// destination that no longer exists. Wrap it to stop further attempts.
+var ErrPermanent = errors.New("permanent delivery failure")
+
+// ErrGone marks destinations that no longer exist, such as expired push
+// subscriptions. They are permanent failures and their subscription is removed.
+var ErrGone = fmt.Errorf("%w: destination gone", ErrPermanent)
+
+// ErrInvalidDestination is returned by Validate for unusable destinations.
+var ErrInvalidDestination = errors.New("invalid destination")
@@ -0,0 +1,215 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Channel names of the self-hosted push notifiers.
+const (
+	ChannelNtfy   = "ntfy"
+	ChannelGotify = "gotify"
+)
+
+// SelfHostedConfig is the JSON config of ntfy and Gotify subscriptions.
+type SelfHostedConfig struct {
+	Token string `json:"token"` // ntfy access token (optional) or Gotify application token
+}
+
+// NtfyNotifier publishes messages to an ntfy topic. The destination target
+// is the topic URL, e.g. https://ntfy.sh/my-manga-updates.
+type NtfyNotifier struct {
+	client *http.Client
+}
+
+// NewNtfyNotifier returns the ntfy notifier. Like webhooks, topic servers
+// may not be on private networks other than the allowed ones.
+func NewNtfyNotifier(allowed []*net.IPNet) Notifier {
+	return &NtfyNotifier{client: publicClient(allowed)}
+}
+
+func (n *NtfyNotifier) Channel() string {
+	return ChannelNtfy
+}
+
+func (n *NtfyNotifier) Validate(dest Destination) error {
+	if _, _, err := splitNtfyTopic(dest.Target); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDestination, err)
+	}
+	if _, err := parseSelfHostedConfig(dest.Config); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDestination, err)
+	}
+	return nil
+}
+
+// Send publishes msg as JSON to the topic's server, opening msg.URL when the
+// notification is tapped.
+func (n *NtfyNotifier) Send(dest Destination, msg Message) error {
+	server, topic, err := splitNtfyTopic(dest.Target)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	cfg, err := parseSelfHostedConfig(dest.Config)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	body := map[string]any{
+		"topic":    topic,
+		"title":    msg.Title,
+		"message":  selfHostedBody(msg),
+		"priority": messagePriority(msg),
+		"tags":     []string{"books"},
+	}
+	if msg.URL != "" {
+		body["click"] = msg.URL
+	}
+	header := http.Header{}
+	if cfg.Token != "" {
+		header.Set("Authorization", "Bearer "+cfg.Token)
+	}
+	return postSelfHosted(n.client, "ntfy", server, header, body)
+}
+
+// splitNtfyTopic splits a topic URL into its server and topic name.
+func splitNtfyTopic(target string) (server, topic string, err error) {
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", "", fmt.Errorf("target must be an http or https topic URL")
+	}
+	path := strings.Trim(u.Path, "/")
+	i := strings.LastIndexByte(path, '/')
+	topic = path[i+1:]
+	if topic == "" {
+		return "", "", fmt.Errorf("target must end with the topic name")
+	}
+	u.Path, u.RawQuery, u.Fragment = "/"+path[:i+1], "", ""
+	return u.String(), topic, nil
+}
+
+// GotifyNotifier posts messages to a Gotify server. The destination target is
+// the server URL and the config holds an application token.
+type GotifyNotifier struct {
+	client *http.Client
+}
+
+// NewGotifyNotifier returns the Gotify notifier. Like webhooks, Gotify
+// servers may not be on private networks other than the allowed ones.
+func NewGotifyNotifier(allowed []*net.IPNet) Notifier {
+	return &GotifyNotifier{client: publicClient(allowed)}
+}
+
+func (n *GotifyNotifier) Channel() string {
+	return ChannelGotify
+}
+
+func (n *GotifyNotifier) Validate(dest Destination) error {
+	u, err := url.Parse(dest.Target)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("%w: target must be the http or https URL of the Gotify server", ErrInvalidDestination)
+	}
+	cfg, err := parseSelfHostedConfig(dest.Config)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDestination, err)
+	}
+	if cfg.Token == "" {
+		return fmt.Errorf("%w: an application token is required", ErrInvalidDestination)
+	}
+	return nil
+}
+
+// Send posts msg to the server's message endpoint. The priority is scaled to
+// Gotify's 0 to 10 and msg.URL opens on click in the Android app.
+func (n *GotifyNotifier) Send(dest Destination, msg Message) error {
+	cfg, err := parseSelfHostedConfig(dest.Config)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	body := map[string]any{
+		"title":    msg.Title,
+		"message":  selfHostedBody(msg),
+		"priority": gotifyPriority(messagePriority(msg)),
+	}
+	if msg.URL != "" {
+		body["extras"] = map[string]any{
+			"client::notification": map[string]any{"click": map[string]string{"url": msg.URL}},
+		}
+	}
+	header := http.Header{}
+	header.Set("X-Gotify-Key", cfg.Token)
+	return postSelfHosted(n.client, "gotify", strings.TrimSuffix(dest.Target, "/")+"/message", header, body)
+}
+
+// gotifyPriority maps ntfy's 1 to 5 onto Gotify's 0 to 10, where clients
+// start to alert loudly at 8.
+func gotifyPriority(priority int) int {
+	return [...]int{0, 0, 2, 5, 8, 10}[priority]
+}
+
+// messagePriority returns the priority of msg, clamped to the ntfy range.
+func messagePriority(msg Message) int {
+	if msg.Priority == 0 {
+		return PriorityDefault
+	}
+	return min(max(msg.Priority, PriorityMin), PriorityMax)
+}
+
+// selfHostedBody returns the notification text; digests list their entries.
+func selfHostedBody(msg Message) string {
+	if msg.Type != MessageTypeDigest {
+		return msg.Body
+	}
+	lines := make([]string, len(msg.Items))
+	for i, item := range msg.Items {
+		lines[i] = "• " + item.Body
+	}
+	return strings.Join(lines, "\n")
+}
+
+func parseSelfHostedConfig(config string) (SelfHostedConfig, error) {
+	var cfg SelfHostedConfig
+	if config == "" {
+		return cfg, nil
+	}
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return cfg, fmt.Errorf("config must be JSON with an optional token")
+	}
+	return cfg, nil
+}
+
+// postSelfHosted posts body as JSON. Rejections other than rate limiting are
+// permanent, as they mean a wrong token or URL.
+func postSelfHosted(client *http.Client, service, target string, header http.Header, body any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	req.Header = header
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		if errors.Is(err, errPrivateAddress) {
+			return fmt.Errorf("%w: %v", ErrPermanent, err)
+		}
+		return err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("%s answered %s", service, resp.Status)
+	default:
+		return fmt.Errorf("%w: %s answered %s", ErrPermanent, service, resp.Status)
+	}
+}
@@ -0,0 +1,89 @@
+package notifier
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loopback allows the test servers, which listen on 127.0.0.1.
+var loopback = []*net.IPNet{{IP: net.IPv4(127, 0, 0, 0), Mask: net.CIDRMask(8, 32)}}
+
+// selfHostedServer records the path, headers and JSON body of one request.
+func selfHostedServer(t *testing.T, status int) (*httptest.Server, <-chan *http.Request, <-chan map[string]any) {
+	requests := make(chan *http.Request, 1)
+	bodies := make(chan map[string]any, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		requests <- r
+		bodies <- body
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, requests, bodies
+}
+
+func TestNtfyNotifier_Send(t *testing.T) {
+	server, requests, bodies := selfHostedServer(t, http.StatusOK)
+	n := NewNtfyNotifier(loopback)
+
+	err := n.Send(Destination{Target: server.URL + "/manga-updates", Config: `{"token": "tk_abc"}`}, Message{
+		Title: "New chapter", Body: "Solo Leveling: Chapter 12", URL: "https://example.com/ch12", Priority: PriorityHigh,
+	})
+	require.NoError(t, err)
+
+	req, body := <-requests, <-bodies
+	assert.Equal(t, "/", req.URL.Path)
+	assert.Equal(t, "Bearer tk_abc", req.Header.Get("Authorization"))
+	assert.Equal(t, "manga-updates", body["topic"])
+	assert.Equal(t, float64(PriorityHigh), body["priority"])
+	assert.Equal(t, "https://example.com/ch12", body["click"])
+}
+
+func TestGotifyNotifier_Send(t *testing.T) {
+	server, requests, bodies := selfHostedServer(t, http.StatusOK)
+	n := NewGotifyNotifier(loopback)
+
+	err := n.Send(Destination{Target: server.URL + "/", Config: `{"token": "app-token"}`}, Message{Title: "New chapter", Body: "Chapter 12"})
+	require.NoError(t, err)
+
+	req, body := <-requests, <-bodies
+	assert.Equal(t, "/message", req.URL.Path)
+	assert.Equal(t, "app-token", req.Header.Get("X-Gotify-Key"))
+	assert.Equal(t, float64(5), body["priority"]) // Default priority
+	assert.NotContains(t, body, "extras")
+
+	server, _, _ = selfHostedServer(t, http.StatusUnauthorized)
+	err = n.Send(Destination{Target: server.URL, Config: `{"token": "wrong"}`}, Message{Title: "New chapter"})
+	assert.ErrorIs(t, err, ErrPermanent)
+}
+
+func TestSelfHosted_RefusesPrivateAddresses(t *testing.T) {
+	server, _, _ := selfHostedServer(t, http.StatusOK)
+
+	err := NewNtfyNotifier(nil).Send(Destination{Target: server.URL + "/manga-updates"}, Message{Title: "New chapter"})
+	assert.ErrorIs(t, err, ErrPermanent)
+	err = NewGotifyNotifier(nil).Send(Destination{Target: server.URL, Config: `{"token": "app-token"}`}, Message{Title: "New chapter"})
+	assert.ErrorIs(t, err, ErrPermanent)
+}
+
+func TestSelfHosted_Validate(t *testing.T) {
+	ntfy, gotify := NewNtfyNotifier(nil), NewGotifyNotifier(nil)
+	assert.NoError(t, ntfy.Validate(Destination{Target: "https://ntfy.sh/manga-updates"}))
+	assert.ErrorIs(t, ntfy.Validate(Destination{Target: "https://ntfy.sh/"}), ErrInvalidDestination)
+	assert.NoError(t, gotify.Validate(Destination{Target: "http://192.168.1.5:8080", Config: `{"token": "app-token"}`}))
+	assert.ErrorIs(t, gotify.Validate(Destination{Target: "http://192.168.1.5:8080"}), ErrInvalidDestination)
+}
+
+func TestSplitNtfyTopic(t *testing.T) {
+	server, topic, err := splitNtfyTopic("https://push.example.com/ntfy/manga?x=1")
+	require.NoError(t, err)
+	assert.Equal(t, "https://push.example.com/ntfy/", server)
+	assert.Equal(t, "manga", topic)
+}
@@ -0,0 +1,257 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ChannelTelegram is the channel name of the Telegram notifier.
+const ChannelTelegram = "telegram"
+
+// TelegramAPIURL is the default Bot API server.
+const TelegramAPIURL = "https://api.telegram.org"
+
+// TelegramPollTimeout is how long a getUpdates long poll waits for updates.
+const TelegramPollTimeout = 50 * time.Second
+
+// Telegram Bot API types, limited to the fields the bot uses.
+type (
+	TelegramUpdate struct {
+		UpdateID      int64                  `json:"update_id"`
+		Message       *TelegramMessage       `json:"message,omitempty"`
+		CallbackQuery *TelegramCallbackQuery `json:"callback_query,omitempty"`
+	}
+
+	TelegramMessage struct {
+		MessageID int64        `json:"message_id"`
+		Chat      TelegramChat `json:"chat"`
+		Text      string       `json:"text"`
+	}
+
+	TelegramChat struct {
+		ID   int64  `json:"id"`
+		Type string `json:"type"` // TelegramChatPrivate, "group", "supergroup" or "channel"
+	}
+
+	TelegramCallbackQuery struct {
+		ID      string           `json:"id"`
+		Message *TelegramMessage `json:"message,omitempty"`
+		Data    string           `json:"data"`
+	}
+
+	// TelegramKeyboard is an inline keyboard: rows of buttons under a message.
+	TelegramKeyboard struct {
+		InlineKeyboard [][]TelegramButton `json:"inline_keyboard"`
+	}
+
+	// TelegramButton opens URL or, without one, sends CallbackData to the bot.
+	TelegramButton struct {
+		Text         string `json:"text"`
+		URL          string `json:"url,omitempty"`
+		CallbackData string `json:"callback_data,omitempty"`
+	}
+)
+
+// TelegramAPIError is an error answer of the Bot API.
+type TelegramAPIError struct {
+	Code        int
+	Description string
+}
+
+func (e *TelegramAPIError) Error() string {
+	return fmt.Sprintf("telegram: %d %s", e.Code, e.Description)
+}
+
+// TelegramClient calls the Bot API of one bot.
+type TelegramClient struct {
+	baseURL string // Bot API server, e.g. TelegramAPIURL
+	token   string
+	client  *http.Client
+}
+
+func NewTelegramClient(baseURL, token string) *TelegramClient {
+	return &TelegramClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: TelegramPollTimeout + 10*time.Second},
+	}
+}
+
+// Username returns the bot's username, as used in t.me links.
+func (c *TelegramClient) Username() (string, error) {
+	var me struct {
+		Username string `json:"username"`
+	}
+	err := c.call("getMe", struct{}{}, &me)
+	return me.Username, err
+}
+
+// SendMessage sends an HTML-formatted message, with an inline keyboard when
+// keyboard is not nil.
+func (c *TelegramClient) SendMessage(chatID int64, text string, keyboard *TelegramKeyboard) error {
+	return c.call("sendMessage", struct {
+		ChatID      int64             `json:"chat_id"`
+		Text        string            `json:"text"`
+		ParseMode   string            `json:"parse_mode"`
+		ReplyMarkup *TelegramKeyboard `json:"reply_markup,omitempty"`
+	}{chatID, text, "HTML", keyboard}, nil)
+}
+
+// AnswerCallbackQuery acknowledges a button press, showing text briefly.
+func (c *TelegramClient) AnswerCallbackQuery(id, text string) error {
+	return c.call("answerCallbackQuery", struct {
+		ID   string `json:"callback_query_id"`
+		Text string `json:"text,omitempty"`
+	}{id, text}, nil)
+}
+
+// GetUpdates long-polls for updates after offset.
+func (c *TelegramClient) GetUpdates(offset int64) ([]TelegramUpdate, error) {
+	var updates []TelegramUpdate
+	err := c.call("getUpdates", struct {
+		Offset  int64 `json:"offset"`
+		Timeout int   `json:"timeout"`
+	}{offset, int(TelegramPollTimeout.Seconds())}, &updates)
+	return updates, err
+}
+
+// SetWebhook makes Telegram push updates to url, sending secret in the
+// X-Telegram-Bot-Api-Secret-Token header.
+func (c *TelegramClient) SetWebhook(url, secret string) error {
+	return c.call("setWebhook", struct {
+		URL         string `json:"url"`
+		SecretToken string `json:"secret_token,omitempty"`
+	}{url, secret}, nil)
+}
+
+// DeleteWebhook switches the bot back to getUpdates.
+func (c *TelegramClient) DeleteWebhook() error {
+	return c.call("deleteWebhook", struct{}{}, nil)
+}
+
+// call invokes a Bot API method and decodes its result into result, if any.
+func (c *TelegramClient) call(method string, params, result any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Post(c.baseURL+"/bot"+c.token+"/"+method, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// The URL carries the bot token; keep it out of errors, which end up
+		// in delivery logs.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("telegram %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+	var answer struct {
+		OK          bool            `json:"ok"`
+		Result      json.RawMessage `json:"result"`
+		ErrorCode   int             `json:"error_code"`
+		Description string          `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&answer); err != nil {
+		return fmt.Errorf("telegram %s: %s", method, resp.Status)
+	}
+	if !answer.OK {
+		return &TelegramAPIError{Code: answer.ErrorCode, Description: answer.Description}
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(answer.Result, result)
+}
+
+// TelegramCallbackMarkRead is the callback data prefix of "mark read"
+// buttons, followed by the manga ID and, when known, ":" and the chapter
+// number, e.g. "read:7:12".
+const TelegramCallbackMarkRead = "read:"
+
+// TelegramChatPrivate is the type of one-to-one chats with the bot.
+const TelegramChatPrivate = "private"
+
+// TelegramNotifier delivers messages to linked Telegram chats. The
+// destination target is the chat ID.
+type TelegramNotifier struct {
+	client *TelegramClient
+}
+
+func NewTelegramNotifier(client *TelegramClient) Notifier {
+	return &TelegramNotifier{client: client}
+}
+
+func (n *TelegramNotifier) Channel() string {
+	return ChannelTelegram
+}
+
+func (n *TelegramNotifier) Validate(dest Destination) error {
+	if _, err := strconv.ParseInt(dest.Target, 10, 64); err != nil {
+		return fmt.Errorf("%w: target must be a Telegram chat ID", ErrInvalidDestination)
+	}
+	return nil
+}
+
+// Send posts msg to the chat. New-chapter messages get "read" and "mark
+// read" buttons. Chats that blocked the bot or no longer exist are ErrGone.
+func (n *TelegramNotifier) Send(dest Destination, msg Message) error {
+	chatID, err := strconv.ParseInt(dest.Target, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	text, keyboard := renderTelegram(msg)
+	err = n.client.SendMessage(chatID, text, keyboard)
+	var apiErr *TelegramAPIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	switch {
+	case apiErr.Code == http.StatusForbidden, apiErr.Code == http.StatusBadRequest && strings.Contains(apiErr.Description, "chat not found"):
+		return fmt.Errorf("%w: %v", ErrGone, err)
+	case apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= 500:
+		return err
+	default:
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+}
+
+// renderTelegram formats msg as Telegram HTML with its inline keyboard.
+func renderTelegram(msg Message) (string, *TelegramKeyboard) {
+	var b strings.Builder
+	b.WriteString("<b>" + html.EscapeString(msg.Title) + "</b>\n")
+	if msg.Type == MessageTypeDigest {
+		for _, item := range msg.Items {
+			b.WriteString("\n• " + html.EscapeString(item.Body))
+			if item.URL != "" {
+				b.WriteString(` <a href="` + html.EscapeString(item.URL) + `">read</a>`)
+			}
+		}
+		return b.String(), nil
+	}
+	b.WriteString(html.EscapeString(msg.Body))
+
+	var buttons []TelegramButton
+	if msg.URL != "" {
+		buttons = append(buttons, TelegramButton{Text: "Read", URL: msg.URL})
+	}
+	if msg.Type == "chapter" && msg.MangaID != 0 {
+		data := TelegramCallbackMarkRead + strconv.FormatUint(uint64(msg.MangaID), 10)
+		if msg.Chapter != 0 {
+			data += ":" + strconv.FormatUint(uint64(msg.Chapter), 10)
+		}
+		buttons = append(buttons, TelegramButton{Text: "Mark read", CallbackData: data})
+	}
+	if len(buttons) == 0 {
+		return b.String(), nil
+	}
+	return b.String(), &TelegramKeyboard{InlineKeyboard: [][]TelegramButton{buttons}}
+}
@@ -0,0 +1,102 @@
+package notifier
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBotAPI stands in for the Telegram Bot API. It records the parameters
+// of each call by method and answers with the given result or error.
+type fakeBotAPI struct {
+	server *httptest.Server
+	calls  map[string][]map[string]any
+	result map[string]any // Result by method; {} by default
+	errors map[string]TelegramAPIError
+}
+
+func newFakeBotAPI(t *testing.T) *fakeBotAPI {
+	api := &fakeBotAPI{calls: map[string][]map[string]any{}, result: map[string]any{}, errors: map[string]TelegramAPIError{}}
+	api.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, strings.HasPrefix(r.URL.Path, "/bottoken/"))
+		method := strings.TrimPrefix(r.URL.Path, "/bottoken/")
+		var params map[string]any
+		json.NewDecoder(r.Body).Decode(&params)
+		api.calls[method] = append(api.calls[method], params)
+		if apiErr, ok := api.errors[method]; ok {
+			json.NewEncoder(w).Encode(map[string]any{"ok": false, "error_code": apiErr.Code, "description": apiErr.Description})
+			return
+		}
+		result, ok := api.result[method]
+		if !ok {
+			result = map[string]any{}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+	}))
+	t.Cleanup(api.server.Close)
+	return api
+}
+
+func TestTelegramNotifier_Send(t *testing.T) {
+	api := newFakeBotAPI(t)
+	n := NewTelegramNotifier(NewTelegramClient(api.server.URL, "token"))
+
+	err := n.Send(Destination{Target: "4242"}, Message{
+		Type: "chapter", Title: "New chapter", Body: "Solo Leveling: Chapter 12 <b>", URL: "https://example.com/solo", MangaID: 7, Chapter: 12,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, api.calls["sendMessage"], 1)
+	params := api.calls["sendMessage"][0]
+	assert.Equal(t, float64(4242), params["chat_id"])
+	assert.Equal(t, "<b>New chapter</b>\nSolo Leveling: Chapter 12 &lt;b&gt;", params["text"])
+	buttons := params["reply_markup"].(map[string]any)["inline_keyboard"].([]any)[0].([]any)
+	assert.Equal(t, map[string]any{"text": "Read", "url": "https://example.com/solo"}, buttons[0])
+	assert.Equal(t, map[string]any{"text": "Mark read", "callback_data": "read:7:12"}, buttons[1])
+}
+
+func TestTelegramNotifier_Send_BlockedChatIsGone(t *testing.T) {
+	api := newFakeBotAPI(t)
+	api.errors["sendMessage"] = TelegramAPIError{Code: 403, Description: "Forbidden: bot was blocked by the user"}
+	n := NewTelegramNotifier(NewTelegramClient(api.server.URL, "token"))
+
+	err := n.Send(Destination{Target: "4242"}, Message{Title: "New chapter"})
+
+	assert.ErrorIs(t, err, ErrGone)
+}
+
+func TestTelegramClient_GetUpdates(t *testing.T) {
+	api := newFakeBotAPI(t)
+	api.result["getUpdates"] = []map[string]any{
+		{"update_id": 10, "message": map[string]any{"message_id": 1, "chat": map[string]any{"id": 4242}, "text": "/list"}},
+		{"update_id": 11, "callback_query": map[string]any{"id": "q1", "data": "read:7", "message": map[string]any{"chat": map[string]any{"id": 4242}}}},
+	}
+	client := NewTelegramClient(api.server.URL, "token")
+
+	updates, err := client.GetUpdates(10)
+	require.NoError(t, err)
+
+	require.Len(t, updates, 2)
+	assert.Equal(t, "/list", updates[0].Message.Text)
+	assert.Equal(t, int64(4242), updates[1].CallbackQuery.Message.Chat.ID)
+	assert.Equal(t, float64(10), api.calls["getUpdates"][0]["offset"])
+}
+
+func TestTelegramClient_ErrorsHideToken(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close() // Nothing listens there any more
+
+	n := NewTelegramNotifier(NewTelegramClient("http://"+addr, "123456:secret-bot-token"))
+	err = n.Send(Destination{Target: "42"}, Message{Title: "New chapter"})
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret-bot-token")
+	assert.Contains(t, err.Error(), "telegram sendMessage")
+}
@@ -0,0 +1,232 @@
+package notifier
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// ChannelWebhook is the channel name of the webhook notifier.
+const ChannelWebhook = "webhook"
+
+// Webhook payload formats.
+const (
+	WebhookFormatGeneric = "generic" // WebhookPayload as JSON
+	WebhookFormatDiscord = "discord" // Discord webhook message with an embed
+	WebhookFormatSlack   = "slack"   // Slack incoming webhook message
+)
+
+// Headers of signed webhook requests. The signature is the hex HMAC-SHA256,
+// keyed with the subscription secret, of the timestamp, a dot and the body.
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature" // "sha256=" + hex signature
+	WebhookTimestampHeader = "X-Webhook-Timestamp" // Unix seconds
+)
+
+// WebhookConfig is the JSON config of a webhook subscription.
+type WebhookConfig struct {
+	Format string   `json:"format"` // generic (default), discord or slack
+	Events []string `json:"events"` // Message types to deliver, e.g. "chapter"; all when empty
+	Secret string   `json:"secret"` // Signing key; required for the generic format
+}
+
+// WebhookPayload is the body of generic-format webhook requests.
+type WebhookPayload struct {
+	Event   string    `json:"event"`
+	Title   string    `json:"title"`
+	Message string    `json:"message"`
+	URL     string    `json:"url,omitempty"`
+	MangaID uint      `json:"manga_id,omitempty"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+// WebhookNotifier posts messages as JSON to user-supplied URLs. The
+// destination target is the URL; requests to private networks are refused.
+type WebhookNotifier struct {
+	client *http.Client
+}
+
+// NewWebhookNotifier initializes the notifier with a client that only
+// connects to public addresses.
+func NewWebhookNotifier() Notifier {
+	return &WebhookNotifier{client: publicClient(nil)}
+}
+
+func (n *WebhookNotifier) Channel() string {
+	return ChannelWebhook
+}
+
+func (n *WebhookNotifier) Validate(dest Destination) error {
+	target, err := url.Parse(dest.Target)
+	if err != nil || (target.Scheme != "https" && target.Scheme != "http") || target.Host == "" {
+		return fmt.Errorf("%w: target must be an http or https URL", ErrInvalidDestination)
+	}
+	if _, err := parseWebhookConfig(dest.Config); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDestination, err)
+	}
+	return nil
+}
+
+// Accepts reports whether the destination's event filter lets msgType
+// through. Test messages always pass.
+func (n *WebhookNotifier) Accepts(dest Destination, msgType string) bool {
+	cfg, err := parseWebhookConfig(dest.Config)
+	if err != nil {
+		return false
+	}
+	return len(cfg.Events) == 0 || msgType == MessageTypeTest || slices.Contains(cfg.Events, msgType)
+}
+
+// Send posts msg in the configured format, signed when a secret is set. The
+// URL answering 404 or 410, as deleted Discord and Slack webhooks do, is
+// reported as ErrGone.
+func (n *WebhookNotifier) Send(dest Destination, msg Message) error {
+	cfg, err := parseWebhookConfig(dest.Config)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	body, err := json.Marshal(webhookBody(cfg.Format, msg))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, dest.Target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "manga-backend-webhook")
+	if cfg.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(WebhookTimestampHeader, timestamp)
+		req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhook(cfg.Secret, timestamp, body))
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		if errors.Is(err, errPrivateAddress) {
+			return fmt.Errorf("%w: %v", ErrPermanent, err)
+		}
+		return err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return fmt.Errorf("%w: webhook answered %s", ErrGone, resp.Status)
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	default:
+		return fmt.Errorf("%w: webhook answered %s", ErrPermanent, resp.Status)
+	}
+}
+
+// SignWebhook returns the hex signature of a webhook request body, for
+// receivers to compare against the signature header.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBody builds the request body of msg in format.
+func webhookBody(format string, msg Message) any {
+	switch format {
+	case WebhookFormatDiscord:
+		type embed struct {
+			Title       string `json:"title"`
+			Description string `json:"description"`
+			URL         string `json:"url,omitempty"`
+			Timestamp   string `json:"timestamp,omitempty"`
+		}
+		e := embed{Title: msg.Title, Description: msg.Body, URL: msg.URL}
+		if !msg.SentAt.IsZero() {
+			e.Timestamp = msg.SentAt.UTC().Format(time.RFC3339)
+		}
+		return map[string]any{"username": "Manga Tracker", "embeds": []embed{e}}
+	case WebhookFormatSlack:
+		text := "*" + msg.Title + "*\n" + msg.Body
+		if msg.URL != "" {
+			text += "\n<" + msg.URL + ">"
+		}
+		return map[string]any{"text": text}
+	default:
+		return WebhookPayload{
+			Event:   msg.Type,
+			Title:   msg.Title,
+			Message: msg.Body,
+			URL:     msg.URL,
+			MangaID: msg.MangaID,
+			SentAt:  msg.SentAt,
+		}
+	}
+}
+
+func parseWebhookConfig(config string) (WebhookConfig, error) {
+	var cfg WebhookConfig
+	if config != "" {
+		if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+			return cfg, fmt.Errorf("config must be JSON with format, events and secret")
+		}
+	}
+	switch cfg.Format {
+	case "":
+		cfg.Format = WebhookFormatGeneric
+	case WebhookFormatGeneric, WebhookFormatDiscord, WebhookFormatSlack:
+	default:
+		return cfg, fmt.Errorf("unknown format %q", cfg.Format)
+	}
+	if cfg.Format == WebhookFormatGeneric && cfg.Secret == "" {
+		return cfg, fmt.Errorf("generic webhooks need a secret to sign requests with")
+	}
+	return cfg, nil
+}
+
+var errPrivateAddress = errors.New("URL resolves to a private address")
+
+// publicClient returns a client for URLs users enter. It refuses to connect
+// to the server's own network, except to the allowed networks, and ignores
+// proxy settings, which would hide the address connected to.
+func publicClient(allowed []*net.IPNet) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: refusePrivateAddress(allowed)}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+	return &http.Client{Timeout: 30 * time.Second, Transport: transport}
+}
+
+// refusePrivateAddress returns a dialer control that stops connections to
+// private addresses outside the allowed networks.
+func refusePrivateAddress(allowed []*net.IPNet) func(network, address string, _ syscall.RawConn) error {
+	return func(network, address string, _ syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		ip := net.ParseIP(host)
+		if ip == nil {
+			return errPrivateAddress
+		}
+		for _, network := range allowed {
+			if network.Contains(ip) {
+				return nil
+			}
+		}
+		if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified() || ip.IsMulticast() {
+			return errPrivateAddress
+		}
+		return nil
+	}
+}
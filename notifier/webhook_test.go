@@ -0,0 +1,95 @@
+package notifier
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookReceiver records the requests it receives and answers with status.
+func webhookReceiver(t *testing.T, status int) (*httptest.Server, <-chan *http.Request, <-chan []byte) {
+	requests := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- r
+		bodies <- body
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, requests, bodies
+}
+
+func TestWebhookNotifier_Send_Generic(t *testing.T) {
+	server, requests, bodies := webhookReceiver(t, http.StatusNoContent)
+	n := &WebhookNotifier{client: server.Client()} // Test receivers listen on loopback
+	sentAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	err := n.Send(Destination{Target: server.URL, Config: `{"secret": "s3cret"}`}, Message{
+		Type: "chapter", Title: "New chapter", Body: "Solo Leveling: Chapter 12", MangaID: 7, SentAt: sentAt,
+	})
+	require.NoError(t, err)
+
+	req, body := <-requests, <-bodies
+	var payload WebhookPayload
+	require.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, WebhookPayload{Event: "chapter", Title: "New chapter", Message: "Solo Leveling: Chapter 12", MangaID: 7, SentAt: sentAt}, payload)
+	timestamp := req.Header.Get(WebhookTimestampHeader)
+	assert.Equal(t, "sha256="+SignWebhook("s3cret", timestamp, body), req.Header.Get(WebhookSignatureHeader))
+}
+
+func TestWebhookNotifier_Send_Discord(t *testing.T) {
+	server, _, bodies := webhookReceiver(t, http.StatusOK)
+	n := &WebhookNotifier{client: server.Client()}
+
+	err := n.Send(Destination{Target: server.URL, Config: `{"format": "discord"}`}, Message{Title: "New chapter", Body: "Chapter 12", URL: "https://example.com/ch12"})
+	require.NoError(t, err)
+
+	var payload struct {
+		Embeds []struct{ Title, Description, URL string }
+	}
+	require.NoError(t, json.Unmarshal(<-bodies, &payload))
+	require.Len(t, payload.Embeds, 1)
+	assert.Equal(t, "New chapter", payload.Embeds[0].Title)
+	assert.Equal(t, "https://example.com/ch12", payload.Embeds[0].URL)
+}
+
+func TestWebhookNotifier_Send_Errors(t *testing.T) {
+	for status, want := range map[int]error{http.StatusGone: ErrGone, http.StatusBadRequest: ErrPermanent} {
+		server, _, _ := webhookReceiver(t, status)
+		n := &WebhookNotifier{client: server.Client()}
+		err := n.Send(Destination{Target: server.URL, Config: `{"format": "slack"}`}, Message{Title: "New chapter"})
+		assert.ErrorIs(t, err, want)
+	}
+
+	server, _, _ := webhookReceiver(t, http.StatusServiceUnavailable)
+	n := &WebhookNotifier{client: server.Client()}
+	err := n.Send(Destination{Target: server.URL, Config: `{"format": "slack"}`}, Message{Title: "New chapter"})
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrPermanent)
+
+	// The default client refuses the loopback address of the test receiver.
+	err = NewWebhookNotifier().Send(Destination{Target: server.URL, Config: `{"format": "slack"}`}, Message{Title: "New chapter"})
+	assert.ErrorIs(t, err, ErrPermanent)
+}
+
+func TestWebhookNotifier_ValidateAndAccepts(t *testing.T) {
+	n := NewWebhookNotifier()
+	assert.NoError(t, n.Validate(Destination{Target: "https://discord.com/api/webhooks/1/x", Config: `{"format": "discord"}`}))
+	assert.ErrorIs(t, n.Validate(Destination{Target: "https://example.com/hook"}), ErrInvalidDestination) // Generic needs a secret
+	assert.ErrorIs(t, n.Validate(Destination{Target: "ftp://example.com", Config: `{"format": "slack"}`}), ErrInvalidDestination)
+	assert.ErrorIs(t, n.Validate(Destination{Target: "https://example.com", Config: `{"format": "teams"}`}), ErrInvalidDestination)
+
+	filter := n.(EventFilter)
+	dest := Destination{Config: `{"format": "slack", "events": ["chapter", "removed"]}`}
+	assert.True(t, filter.Accepts(dest, "chapter"))
+	assert.False(t, filter.Accepts(dest, "state"))
+	assert.True(t, filter.Accepts(dest, MessageTypeTest))
+	assert.True(t, filter.Accepts(Destination{Config: `{"format": "slack"}`}, "state"))
+}
@@ -0,0 +1,272 @@
+package notifier
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// ChannelWebPush is the channel name of the Web Push notifier.
+const ChannelWebPush = "webpush"
+
+const (
+	// webPushTTL is how long push services keep undelivered messages.
+	webPushTTL = 24 * time.Hour
+	// webPushRecordSize is the aes128gcm record size; payloads fit one record.
+	webPushRecordSize = 4096
+)
+
+// WebPushKeys are the keys a browser hands out with a push subscription,
+// stored as the subscription's JSON config.
+type WebPushKeys struct {
+	P256dh string `json:"p256dh"` // User agent public key, base64url
+	Auth   string `json:"auth"`   // Authentication secret, base64url
+}
+
+// WebPushNotifier delivers messages to browser push subscriptions (RFC 8030)
+// with encrypted payloads (RFC 8291) and VAPID authentication (RFC 8292).
+// The destination target is the subscription endpoint.
+type WebPushNotifier struct {
+	key     *ecdsa.PrivateKey
+	subject string // Contact of the application server, a mailto: or https: URL
+	client  *http.Client
+}
+
+// NewWebPushNotifier initializes the notifier with the VAPID key pair. The
+// endpoint comes from the browser, so private addresses are refused as for
+// webhooks.
+func NewWebPushNotifier(key *ecdsa.PrivateKey, subject string) Notifier {
+	return &WebPushNotifier{key: key, subject: subject, client: publicClient(nil)}
+}
+
+// GenerateVAPIDKey creates a new VAPID key pair.
+func GenerateVAPIDKey() (*ecdsa.PrivateKey, error) {
+	return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+}
+
+// EncodeVAPIDKey returns the private key as base64url, the format used by
+// common Web Push tooling.
+func EncodeVAPIDKey(key *ecdsa.PrivateKey) string {
+	return base64.RawURLEncoding.EncodeToString(key.D.FillBytes(make([]byte, 32)))
+}
+
+// ParseVAPIDKey parses a private key encoded by EncodeVAPIDKey.
+func ParseVAPIDKey(encoded string) (*ecdsa.PrivateKey, error) {
+	d, err := decodeBase64(encoded)
+	if err != nil || len(d) != 32 {
+		return nil, fmt.Errorf("invalid VAPID private key")
+	}
+	private, err := ecdh.P256().NewPrivateKey(d)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key")
+	}
+	public := private.PublicKey().Bytes() // Uncompressed point: 0x04 || X || Y
+	key := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(d)}
+	key.Curve = elliptic.P256()
+	key.X = new(big.Int).SetBytes(public[1:33])
+	key.Y = new(big.Int).SetBytes(public[33:])
+	return key, nil
+}
+
+// VAPIDPublicKey returns the public key as base64url, the
+// applicationServerKey browsers subscribe with.
+func VAPIDPublicKey(key *ecdsa.PrivateKey) string {
+	public, err := key.PublicKey.ECDH()
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(public.Bytes())
+}
+
+func (n *WebPushNotifier) Channel() string {
+	return ChannelWebPush
+}
+
+func (n *WebPushNotifier) Validate(dest Destination) error {
+	endpoint, err := url.Parse(dest.Target)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+		return fmt.Errorf("%w: endpoint must be an https URL", ErrInvalidDestination)
+	}
+	if _, _, err := parseWebPushKeys(dest.Config); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDestination, err)
+	}
+	return nil
+}
+
+// Send encrypts msg for the subscription and posts it to its push service.
+// Endpoints answering 404 or 410 have expired or were unsubscribed and are
+// reported as ErrGone.
+func (n *WebPushNotifier) Send(dest Destination, msg Message) error {
+	uaPublic, authSecret, err := parseWebPushKeys(dest.Config)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	payload, err := json.Marshal(struct {
+		Type  string `json:"type,omitempty"`
+		Title string `json:"title"`
+		Body  string `json:"body"`
+		URL   string `json:"url,omitempty"`
+	}{msg.Type, msg.Title, msg.Body, msg.URL})
+	if err != nil {
+		return err
+	}
+	body, err := encryptWebPush(payload, uaPublic, authSecret)
+	if err != nil {
+		return err
+	}
+	authorization, err := n.vapidAuthorization(dest.Target)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, dest.Target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", fmt.Sprint(int(webPushTTL.Seconds())))
+	req.Header.Set("Authorization", authorization)
+	resp, err := n.client.Do(req)
+	if err != nil {
+		if errors.Is(err, errPrivateAddress) {
+			return fmt.Errorf("%w: %v", ErrPermanent, err)
+		}
+		return err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return fmt.Errorf("%w: push service answered %s", ErrGone, resp.Status)
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("push service answered %s", resp.Status)
+	default:
+		return fmt.Errorf("%w: push service answered %s", ErrPermanent, resp.Status)
+	}
+}
+
+// vapidAuthorization returns the Authorization header for a push service: a
+// short-lived ES256 JWT for its origin and the public key.
+func (n *WebPushNotifier) vapidAuthorization(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": time.Now().Add(12 * time.Hour).Unix(),
+		"sub": n.subject,
+	}).SignedString(n.key)
+	if err != nil {
+		return "", err
+	}
+	return "vapid t=" + token + ", k=" + VAPIDPublicKey(n.key), nil
+}
+
+// encryptWebPush encrypts payload for a user agent as a single aes128gcm
+// record (RFC 8188) keyed as described in RFC 8291.
+func encryptWebPush(payload []byte, uaPublic *ecdh.PublicKey, authSecret []byte) ([]byte, error) {
+	asKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	sharedSecret, err := asKey.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asKey.PublicKey().Bytes()
+	cek, nonce, err := webPushContentKeys(sharedSecret, authSecret, salt, uaPublic.Bytes(), asPublic)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload)+1+gcm.Overhead() > webPushRecordSize {
+		return nil, fmt.Errorf("%w: payload too large for a push message", ErrPermanent)
+	}
+
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, webPushRecordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+	plaintext := append(append([]byte{}, payload...), 0x02) // Last record delimiter
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+// webPushContentKeys derives the content encryption key and nonce from the
+// ECDH shared secret, the user agent's auth secret and the record salt.
+func webPushContentKeys(sharedSecret, authSecret, salt, uaPublic, asPublic []byte) (cek, nonce []byte, err error) {
+	keyInfo := "WebPush: info\x00" + string(uaPublic) + string(asPublic)
+	ikm, err := hkdf.Key(sha256.New, sharedSecret, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, nil, err
+	}
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cek, err = hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16); err != nil {
+		return nil, nil, err
+	}
+	nonce, err = hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	return cek, nonce, err
+}
+
+// parseWebPushKeys decodes the subscription keys stored as destination config.
+func parseWebPushKeys(config string) (*ecdh.PublicKey, []byte, error) {
+	var keys WebPushKeys
+	if err := json.Unmarshal([]byte(config), &keys); err != nil {
+		return nil, nil, fmt.Errorf("config must hold the subscription keys p256dh and auth")
+	}
+	p256dh, err := decodeBase64(keys.P256dh)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid p256dh key")
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(p256dh)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid p256dh key")
+	}
+	auth, err := decodeBase64(keys.Auth)
+	if err != nil || len(auth) != 16 {
+		return nil, nil, fmt.Errorf("invalid auth secret")
+	}
+	return uaPublic, auth, nil
+}
+
+// decodeBase64 accepts base64url with or without padding, as browsers and
+// tools differ.
+func decodeBase64(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
@@ -0,0 +1,133 @@
+package notifier
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pushEndpoint stands in for a browser's push service and user agent: it
+// answers with status and decrypts what it receives.
+type pushEndpoint struct {
+	server *httptest.Server
+	key    *ecdh.PrivateKey
+	auth   []byte
+
+	authorization string
+	payload       []byte
+}
+
+func newPushEndpoint(t *testing.T, status int) *pushEndpoint {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	e := &pushEndpoint{key: key, auth: make([]byte, 16)}
+	rand.Read(e.auth)
+
+	e.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "aes128gcm", r.Header.Get("Content-Encoding"))
+		assert.NotEmpty(t, r.Header.Get("TTL"))
+		e.authorization = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		e.payload = e.decrypt(t, body)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(e.server.Close)
+	return e
+}
+
+func (e *pushEndpoint) destination() Destination {
+	keys, _ := json.Marshal(WebPushKeys{
+		P256dh: base64.RawURLEncoding.EncodeToString(e.key.PublicKey().Bytes()),
+		Auth:   base64.RawURLEncoding.EncodeToString(e.auth),
+	})
+	return Destination{Target: e.server.URL + "/push/abc", Config: string(keys)}
+}
+
+// decrypt reverses the aes128gcm encoding as a user agent would.
+func (e *pushEndpoint) decrypt(t *testing.T, body []byte) []byte {
+	require.Greater(t, len(body), 21)
+	salt, recordSize, idLen := body[:16], binary.BigEndian.Uint32(body[16:20]), int(body[20])
+	assert.Equal(t, uint32(webPushRecordSize), recordSize)
+	asPublic, err := ecdh.P256().NewPublicKey(body[21 : 21+idLen])
+	require.NoError(t, err)
+	sharedSecret, err := e.key.ECDH(asPublic)
+	require.NoError(t, err)
+	cek, nonce, err := webPushContentKeys(sharedSecret, e.auth, salt, e.key.PublicKey().Bytes(), asPublic.Bytes())
+	require.NoError(t, err)
+	block, _ := aes.NewCipher(cek)
+	gcm, _ := cipher.NewGCM(block)
+	plaintext, err := gcm.Open(nil, nonce, body[21+idLen:], nil)
+	require.NoError(t, err)
+	require.Equal(t, byte(0x02), plaintext[len(plaintext)-1])
+	return plaintext[:len(plaintext)-1]
+}
+
+func TestWebPushNotifier_Send(t *testing.T) {
+	endpoint := newPushEndpoint(t, http.StatusCreated)
+	key, err := GenerateVAPIDKey()
+	require.NoError(t, err)
+	n := &WebPushNotifier{key: key, subject: "mailto:admin@example.com", client: endpoint.server.Client()} // Test endpoints listen on loopback
+
+	err = n.Send(endpoint.destination(), Message{Type: "chapter", Title: "New chapter", Body: "Solo Leveling: Chapter 12"})
+	require.NoError(t, err)
+
+	var payload map[string]string
+	require.NoError(t, json.Unmarshal(endpoint.payload, &payload))
+	assert.Equal(t, "Solo Leveling: Chapter 12", payload["body"])
+	assert.Equal(t, "chapter", payload["type"])
+
+	token, public, ok := strings.Cut(strings.TrimPrefix(endpoint.authorization, "vapid t="), ", k=")
+	require.True(t, ok)
+	assert.Equal(t, VAPIDPublicKey(key), public)
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) { return &key.PublicKey, nil })
+	require.NoError(t, err)
+	assert.Equal(t, endpoint.server.URL, claims["aud"])
+	assert.Equal(t, "mailto:admin@example.com", claims["sub"])
+}
+
+func TestWebPushNotifier_Send_ExpiredSubscriptionIsGone(t *testing.T) {
+	key, _ := GenerateVAPIDKey()
+	n := &WebPushNotifier{key: key, subject: "mailto:admin@example.com", client: http.DefaultClient}
+
+	for _, status := range []int{http.StatusNotFound, http.StatusGone} {
+		err := n.Send(newPushEndpoint(t, status).destination(), Message{Title: "New chapter"})
+		assert.ErrorIs(t, err, ErrGone)
+		assert.ErrorIs(t, err, ErrPermanent)
+	}
+	err := n.Send(newPushEndpoint(t, http.StatusServiceUnavailable).destination(), Message{Title: "New chapter"})
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrPermanent)
+}
+
+func TestWebPushNotifier_RefusesPrivateAddresses(t *testing.T) {
+	endpoint := newPushEndpoint(t, http.StatusCreated)
+	key, _ := GenerateVAPIDKey()
+
+	err := NewWebPushNotifier(key, "mailto:admin@example.com").Send(endpoint.destination(), Message{Title: "New chapter"})
+	assert.ErrorIs(t, err, ErrPermanent)
+	assert.Nil(t, endpoint.payload, "nothing reaches the endpoint")
+}
+
+func TestVAPIDKey_RoundTrip(t *testing.T) {
+	key, err := GenerateVAPIDKey()
+	require.NoError(t, err)
+
+	parsed, err := ParseVAPIDKey(EncodeVAPIDKey(key))
+	require.NoError(t, err)
+	assert.True(t, key.Equal(parsed))
+	assert.Len(t, VAPIDPublicKey(parsed), 87) // 65 bytes, base64url
+}
@@ -0,0 +1,77 @@
+package scraper
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// CheckURL fetches pageURL and reports whether the page is still served.
+// It returns ErrNotFound for pages that are gone or redirected to another
+// path, ErrScrapeFailed when the site cannot be reached, and nil otherwise.
+func CheckURL(pageURL string) error {
+	resp, err := http.Get(pageURL)
+	if err != nil {
+		return ErrScrapeFailed
+	}
+	defer resp.Body.Close()
+	return checkResponse(resp, pageURL)
+}
+
+// checkResponse classifies the response to a request for pageURL.
+func checkResponse(resp *http.Response, pageURL string) error {
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return ErrScrapeFailed
+	}
+	if resp.Request != nil && redirected(pageURL, resp.Request.URL) {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// redirected reports whether final points to another path than requested.
+// Scheme, host and trailing slash changes do not count.
+func redirected(requested string, final *url.URL) bool {
+	u, err := url.Parse(requested)
+	if err != nil || final == nil {
+		return false
+	}
+	return strings.TrimSuffix(u.Path, "/") != strings.TrimSuffix(final.Path, "/")
+}
+
+// movedBase returns the new base URL when a request for baseURL was
+// permanently redirected (301 or 308) to the root of another scheme or host,
+// and "" otherwise. Temporary redirects, as used for maintenance pages and
+// geo or bot checks, do not count as a move.
+func movedBase(baseURL string, resp *http.Response) string {
+	u, err := url.Parse(baseURL)
+	if err != nil || resp.Request == nil || !permanentlyRedirected(resp) {
+		return ""
+	}
+	final := resp.Request.URL
+	if strings.TrimSuffix(final.Path, "/") != strings.TrimSuffix(u.Path, "/") {
+		return ""
+	}
+	if final.Scheme == u.Scheme && final.Host == u.Host {
+		return ""
+	}
+	return final.Scheme + "://" + final.Host + strings.TrimSuffix(u.Path, "/") + "/"
+}
+
+// permanentlyRedirected reports whether resp was reached through at least one
+// redirect and every redirect on the way was permanent.
+func permanentlyRedirected(resp *http.Response) bool {
+	hops := 0
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		switch req.Response.StatusCode {
+		case http.StatusMovedPermanently, http.StatusPermanentRedirect:
+			hops++
+		default:
+			return false
+		}
+	}
+	return hops > 0
+}
@@ -21,6 +21,11 @@ func (s *MangaReadScraper) GetBaseUrl() string {
 	return s.baseURL
 }
 
+// WithBaseURL returns a scraper for the same site served from baseURL.
+func (s *MangaReadScraper) WithBaseURL(baseURL string) Scraper {
+	return &MangaReadScraper{baseURL: baseURL}
+}
+
 // GetLatestUpdates fetches the latest chapter updates from the MangaRead homepage.
 //
 // This function scrapes the homepage of MangaRead.org to extract information about
@@ -30,14 +35,17 @@ func (s *MangaReadScraper) GetBaseUrl() string {
 // Returns:
 //   - []Update: A slice of Update structs, each containing information about a single
 //     manga update, including the manga title, slug, latest chapter number, and update time.
-//   - error: An error of type ErrScrapeFailed if the scraping process fails at any point,
-//     or nil if the operation is successful.
+//   - error: A *MovedError if the homepage permanently redirects to another domain, an error of type
+//     ErrScrapeFailed if the scraping process fails at any point, or nil if the operation is successful.
 func (s *MangaReadScraper) GetLatestUpdates() ([]Update, error) {
 	resp, err := http.Get(s.baseURL)
 	if err != nil {
 		return nil, ErrScrapeFailed
 	}
 	defer resp.Body.Close()
+	if moved := movedBase(s.baseURL, resp); moved != "" {
+		return nil, &MovedError{URL: moved}
+	}
 
 	doc, err := goquery.NewDocumentFromReader(resp.Body)
 	if err != nil {
@@ -53,12 +61,14 @@ func (s *MangaReadScraper) GetLatestUpdates() ([]Update, error) {
 			title := t.Text()
 			slug := strings.TrimSuffix(strings.TrimPrefix(titleLink, s.baseURL+"manga/"), "/")
 			chapter := strings.TrimSpace(subSelection.Find(".chapter").Text())
+			chapterURL, _ := subSelection.Find(".chapter a").First().Attr("href")
 			updateDate := strings.TrimSpace(subSelection.Find(".post-on").Text())
 			if title != "" && slug != "" {
 				updates = append(updates, Update{
 					MangaTitle:    title,
 					MangaSlug:     slug,
 					ChapterNumber: chapter,
+					ChapterURL:    chapterURL,
 					UpdateDate:    updateDate,
 				})
 			}
@@ -78,14 +88,18 @@ func (s *MangaReadScraper) GetLatestUpdates() ([]Update, error) {
 //
 // Returns:
 //   - Manga: A Manga struct containing the scraped details of the manga.
-//   - error: An error if the scraping process fails (ErrScrapeFailed), or nil if successful.
+//   - error: ErrNotFound if the page is gone or redirected, ErrScrapeFailed if the scraping process fails,
+//     or nil if successful.
 func (s *MangaReadScraper) GetMangaDetails(slug string) (Manga, error) {
 	url := s.baseURL + "manga/" + slug + "/"
 	resp, err := http.Get(url)
-	if err != nil || resp.StatusCode != http.StatusOK {
+	if err != nil {
 		return Manga{}, ErrScrapeFailed
 	}
 	defer resp.Body.Close()
+	if err := checkResponse(resp, url); err != nil {
+		return Manga{}, err
+	}
 
 	doc, err := goquery.NewDocumentFromReader(resp.Body)
 	if err != nil || doc.Text() == "" {
@@ -94,7 +108,8 @@ func (s *MangaReadScraper) GetMangaDetails(slug string) (Manga, error) {
 
 	title := strings.TrimSpace(doc.Find(".post-title h1").Text())
 	description := strings.TrimSpace(doc.Find(".summary__content p").Text())
-	author := strings.TrimSpace(doc.Find(".author-content a").Last().Text())
+	authors := creditNames(doc.Find(".author-content a"))
+	artists := creditNames(doc.Find(".artist-content a"))
 	status := strings.TrimSpace(doc.Find(".post-status .post-content_item .summary-content").Last().Text())
 	coverURL, _ := doc.Find(".summary_image a img").Attr("src")
 
@@ -103,16 +118,47 @@ func (s *MangaReadScraper) GetMangaDetails(slug string) (Manga, error) {
 		tags = append(tags, strings.TrimSpace(selection.Text()))
 	})
 
+	var altTitles []string
+	doc.Find(".post-content_item").Each(func(i int, selection *goquery.Selection) {
+		if !strings.Contains(selection.Find(".summary-heading").Text(), "Alternative") {
+			return
+		}
+		for _, alt := range strings.FieldsFunc(selection.Find(".summary-content").Text(), func(r rune) bool {
+			return r == ';' || r == ','
+		}) {
+			if alt = strings.TrimSpace(alt); alt != "" {
+				altTitles = append(altTitles, alt)
+			}
+		}
+	})
+
 	return Manga{
 		Title:       title,
+		AltTitles:   altTitles,
 		Description: description,
-		Author:      author,
+		Author:      strings.Join(authors, AuthorSeparator),
+		Authors:     authors,
+		Artists:     artists,
 		Status:      status,
 		CoverURL:    coverURL,
 		Tags:        tags,
 	}, nil
 }
 
+// creditNames returns the distinct non-empty link texts of a credit list.
+func creditNames(links *goquery.Selection) []string {
+	var names []string
+	seen := make(map[string]bool)
+	links.Each(func(i int, selection *goquery.Selection) {
+		name := strings.TrimSpace(selection.Text())
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	})
+	return names
+}
+
 // GetChapterList fetches the list of chapters for a specific manga from MangaRead.org.
 //
 // Parameters:
@@ -1,6 +1,7 @@
 package scraper
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -367,6 +368,64 @@ func TestMangaReadScraper_GetMangaDetails_NoTags(t *testing.T) {
 	assert.Empty(t, manga.Tags)
 }
 
+func TestMangaReadScraper_GetMangaDetails_AltTitles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`
+			<html>
+				<body>
+					<div class="post-title">
+						<h1>Demon Slayer</h1>
+					</div>
+					<div class="post-content_item">
+						<div class="summary-heading"><h5>Alternative</h5></div>
+						<div class="summary-content">Kimetsu no Yaiba; 鬼滅の刃 , Blade of Demon Destruction</div>
+					</div>
+				</body>
+			</html>
+		`))
+	}))
+	defer server.Close()
+
+	scraper := &MangaReadScraper{baseURL: server.URL + "/"}
+	manga, err := scraper.GetMangaDetails("demon-slayer")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Kimetsu no Yaiba", "鬼滅の刃", "Blade of Demon Destruction"}, manga.AltTitles)
+}
+
+func TestMangaReadScraper_GetMangaDetails_Credits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`
+			<html>
+				<body>
+					<div class="post-title">
+						<h1>Solo Leveling</h1>
+					</div>
+					<div class="author-content">
+						<a>Chugong</a>
+						<a>h-goon</a>
+					</div>
+					<div class="artist-content">
+						<a>DUBU (REDICE STUDIO)</a>
+						<a>Chugong</a>
+					</div>
+				</body>
+			</html>
+		`))
+	}))
+	defer server.Close()
+
+	scraper := &MangaReadScraper{baseURL: server.URL + "/"}
+	manga, err := scraper.GetMangaDetails("solo-leveling")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Chugong", "h-goon"}, manga.Authors)
+	assert.Equal(t, []string{"DUBU (REDICE STUDIO)", "Chugong"}, manga.Artists)
+	assert.Equal(t, "Chugong, h-goon", manga.Author)
+}
+
 func TestMangaReadScraper_GetMangaDetails_RealHTML(t *testing.T) {
 	scraper := &MangaReadScraper{baseURL: "https://www.mangaread.org/"}
 	manga, err := scraper.GetMangaDetails("healing-life-through-camping-in-another-world")
@@ -403,3 +462,79 @@ func TestMangaReadScraper_GetLatestUpdates_MultipleUpdates(t *testing.T) {
 	assert.NotEmpty(t, updates[1].ChapterNumber)
 	assert.NotEmpty(t, updates[1].UpdateDate)
 }
+
+func TestMangaReadScraper_GetMangaDetails_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	scraper := &MangaReadScraper{baseURL: server.URL + "/"}
+	_, err := scraper.GetMangaDetails("licensed-manga")
+
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestCheckURL_Redirected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.Redirect(w, r, "/", http.StatusMovedPermanently)
+			return
+		}
+		w.Write([]byte("<html><body>Home</body></html>"))
+	}))
+	defer server.Close()
+
+	assert.Equal(t, ErrNotFound, CheckURL(server.URL+"/manga/licensed-manga/"))
+	assert.NoError(t, CheckURL(server.URL))
+}
+
+func TestMangaReadScraper_GetLatestUpdates_Moved(t *testing.T) {
+	newSite := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body></body></html>"))
+	}))
+	defer newSite.Close()
+	oldSite := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, newSite.URL+r.URL.Path, http.StatusMovedPermanently)
+	}))
+	defer oldSite.Close()
+
+	scraper := &MangaReadScraper{baseURL: oldSite.URL + "/"}
+	_, err := scraper.GetLatestUpdates()
+
+	var moved *MovedError
+	assert.ErrorAs(t, err, &moved)
+	assert.Equal(t, newSite.URL+"/", moved.URL)
+}
+
+func TestMangaReadScraper_GetLatestUpdates_TemporaryRedirect(t *testing.T) {
+	newSite := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body></body></html>"))
+	}))
+	defer newSite.Close()
+	for _, status := range []int{http.StatusFound, http.StatusTemporaryRedirect} {
+		oldSite := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, newSite.URL+r.URL.Path, status)
+		}))
+
+		scraper := &MangaReadScraper{baseURL: oldSite.URL + "/"}
+		_, err := scraper.GetLatestUpdates()
+		oldSite.Close()
+
+		var moved *MovedError
+		assert.False(t, errors.As(err, &moved), "status %d", status)
+	}
+}
+
+func TestSplitAuthors(t *testing.T) {
+	tests := []struct {
+		author string
+		want   []string
+	}{
+		{"Chugong, h-goon", []string{"Chugong", "h-goon"}},
+		{"  Oda Eiichiro ", []string{"Oda Eiichiro"}},
+		{"A,,B , ", []string{"A", "B"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, SplitAuthors(tt.author), tt.author)
+	}
+}
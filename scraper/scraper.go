@@ -1,21 +1,32 @@
 package scraper
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// AuthorSeparator joins the credited authors into Manga.Author.
+const AuthorSeparator = ", "
 
 // Update represents a recent manga chapter update from the site.
 type Update struct {
 	MangaTitle    string
 	MangaSlug     string // Slug for the manga (e.g., "solo-leveling-manhwa")
 	ChapterNumber string
+	ChapterURL    string // Link to the chapter on the site, if listed
 	UpdateDate    string
 }
 
 // Manga represents detailed metadata for a manga.
 type Manga struct {
 	Title       string
+	AltTitles   []string // Alternative and transliterated titles, if listed by the site
 	Description string
-	Author      string
-	Status      string // e.g., "Ongoing", "Completed"
+	Author      string   // Authors joined with AuthorSeparator
+	Authors     []string // Credited authors in page order
+	Artists     []string // Credited artists in page order
+	Status      string   // e.g., "Ongoing", "Completed"
 	CoverURL    string
 	Tags        []string
 }
@@ -34,7 +45,34 @@ type Scraper interface {
 	GetMangaDetails(slug string) (Manga, error)    // Fetch details for a specific manga
 	GetChapterList(slug string) ([]Chapter, error) // Fetch full chapter list for bookmarking and update detection
 	GetBaseUrl() string                            // Get the base URL for the scraped site
+	WithBaseURL(baseURL string) Scraper            // Copy of the scraper for the site after a domain move
 }
 
 // ErrScrapeFailed is a generic error for scraping issues.
 var ErrScrapeFailed = errors.New("scrape failed due to site access or parsing error")
+
+// ErrNotFound is returned when the site answers 404 or 410 for a page, or
+// redirects it elsewhere, as it does for series taken down.
+var ErrNotFound = errors.New("page not found at source")
+
+// MovedError is returned when the site's base URL permanently redirects to
+// another scheme or host, as it does after a domain move. URL is the new base
+// URL.
+type MovedError struct {
+	URL string
+}
+
+func (e *MovedError) Error() string {
+	return fmt.Sprintf("site moved to %s", e.URL)
+}
+
+// SplitAuthors splits a joined Manga.Author back into author names.
+func SplitAuthors(author string) []string {
+	var authors []string
+	for _, name := range strings.Split(author, strings.TrimSpace(AuthorSeparator)) {
+		if name = strings.TrimSpace(name); name != "" {
+			authors = append(authors, name)
+		}
+	}
+	return authors
+}